@@ -0,0 +1,224 @@
+// Copyright 2026 Miklos Vajna
+//
+// SPDX-License-Identifier: MIT
+
+package commands
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pquerna/otp/totp"
+	"github.com/spf13/cobra"
+)
+
+// AuditFormat is an enum of possible audit output formats.
+type AuditFormat string
+
+const (
+	// AuditFormatText is one line per finding, for humans.
+	AuditFormatText AuditFormat = "text"
+	// AuditFormatJSON is a list of findings, for scripts.
+	AuditFormatJSON AuditFormat = "json"
+)
+
+func (f *AuditFormat) String() string {
+	return string(*f)
+}
+
+// Set sets the value of `f` from `v`.
+func (f *AuditFormat) Set(v string) error {
+	switch v {
+	case "text", "json":
+		*f = AuditFormat(v)
+		return nil
+	default:
+		return errors.New(`must be one of "text", or "json"`)
+	}
+}
+
+// Type returns the type of `f` as a string.
+func (f *AuditFormat) Type() string {
+	return "AuditFormat"
+}
+
+// auditFinding is one problem of one password, found by the audit.
+type auditFinding struct {
+	ID      int
+	Machine string
+	Service string
+	User    string
+	// Problem is one of "reused", "weak", "stale", "bad-totp" or "breached".
+	Problem string
+	Details string
+}
+
+// auditOptions are the thresholds of the audit.
+type auditOptions struct {
+	minStrength int
+	maxAge      int
+	// breached is the path of a local Pwned Passwords copy, if any.
+	breached string
+}
+
+// auditPasswords checks all non-archived passwords for problems.
+func auditPasswords(db *sql.DB, opts auditOptions) ([]auditFinding, error) {
+	rows, err := db.Query("select id, machine, service, user, password, type, modified from passwords where archived = 0 order by id")
+	if err != nil {
+		return nil, fmt.Errorf("db.Query(select) failed: %s", err)
+	}
+
+	defer rows.Close()
+	var passwords []passwordRow
+	// Password -> machines of plain passwords, to find reuse.
+	machines := make(map[string]map[string]bool)
+	for rows.Next() {
+		var row passwordRow
+		err = rows.Scan(&row.ID, &row.Machine, &row.Service, &row.User, &row.Password, &row.PasswordType, &row.Modified)
+		if err != nil {
+			return nil, fmt.Errorf("rows.Scan() failed: %s", err)
+		}
+
+		passwords = append(passwords, row)
+		if row.PasswordType == PasswordTypePlain {
+			if machines[row.Password] == nil {
+				machines[row.Password] = make(map[string]bool)
+			}
+			machines[row.Password][row.Machine] = true
+		}
+	}
+
+	var breached *breachedHashes
+	if len(opts.breached) > 0 {
+		breached, err = openBreachedHashes(opts.breached)
+		if err != nil {
+			return nil, fmt.Errorf("openBreachedHashes() failed: %s", err)
+		}
+		defer breached.close()
+	}
+
+	findings := []auditFinding{}
+	now := Now()
+	for _, row := range passwords {
+		finding := auditFinding{ID: row.ID, Machine: row.Machine, Service: row.Service, User: row.User}
+		if row.PasswordType == PasswordTypeTotp {
+			sharedSecret, totpOpts, err := getTotpOpts(row.Password)
+			if err == nil {
+				_, err = totp.GenerateCodeCustom(sharedSecret, now, totpOpts)
+			}
+			if err != nil {
+				finding.Problem = "bad-totp"
+				finding.Details = err.Error()
+				findings = append(findings, finding)
+			}
+			continue
+		}
+
+		if row.PasswordType != PasswordTypePlain {
+			continue
+		}
+
+		var others []string
+		for machine := range machines[row.Password] {
+			if machine != row.Machine {
+				others = append(others, machine)
+			}
+		}
+		if len(others) > 0 {
+			sort.Strings(others)
+			finding.Problem = "reused"
+			finding.Details = fmt.Sprintf("same password as on %s", strings.Join(others, ", "))
+			findings = append(findings, finding)
+		}
+
+		strength := getPasswordStrength(row.Password, row.Machine, row.User)
+		if strength < opts.minStrength {
+			finding.Problem = "weak"
+			finding.Details = fmt.Sprintf("strength is %d/%d", strength, maxStrength)
+			findings = append(findings, finding)
+		}
+
+		if breached != nil {
+			count, err := breached.lookup(row.Password)
+			if err != nil {
+				return nil, fmt.Errorf("lookup() failed: %s", err)
+			}
+			if count > 0 {
+				finding.Problem = "breached"
+				finding.Details = fmt.Sprintf("seen %d times in data breaches", count)
+				findings = append(findings, finding)
+			}
+		}
+
+		// Passwords from before schema version 3 have no modification date.
+		if len(row.Modified) > 0 {
+			modified, err := time.Parse(time.RFC3339, row.Modified)
+			if err != nil {
+				return nil, fmt.Errorf("time.Parse() failed: %s", err)
+			}
+
+			days := int(now.Sub(modified).Hours() / 24)
+			if days > opts.maxAge {
+				finding.Problem = "stale"
+				finding.Details = fmt.Sprintf("not modified for %d days", days)
+				findings = append(findings, finding)
+			}
+		}
+	}
+
+	return findings, nil
+}
+
+func newAuditCommand(ctx *Context) *cobra.Command {
+	var format AuditFormat = "text"
+	var opts auditOptions
+	var cmd = &cobra.Command{
+		Use:   "audit",
+		Short: "checks passwords for reuse, weakness, age, breaches and broken TOTP shared secrets",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx.NoWriteBack = true
+			if !cmd.Flags().Changed("min-strength") {
+				c, err := loadConfig()
+				if err != nil {
+					return fmt.Errorf("loadConfig() failed: %s", err)
+				}
+				opts.minStrength = c.MinStrength
+			}
+
+			findings, err := auditPasswords(ctx.Database, opts)
+			if err != nil {
+				return fmt.Errorf("auditPasswords() failed: %s", err)
+			}
+
+			if format == AuditFormatJSON {
+				j, err := json.Marshal(findings)
+				if err != nil {
+					return fmt.Errorf("json.Marshal() failed: %s", err)
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "%s\n", j)
+			} else {
+				for _, finding := range findings {
+					fmt.Fprintf(cmd.OutOrStdout(), "id: %8d, machine: %s, service: %s, user: %s, problem: %s, details: %s\n", finding.ID, finding.Machine, finding.Service, finding.User, finding.Problem, finding.Details)
+				}
+			}
+
+			// Fail, so this can be used from cron.
+			if len(findings) > 0 {
+				return fmt.Errorf("found %d problems", len(findings))
+			}
+
+			return nil
+		},
+	}
+	cmd.Flags().VarP(&format, "format", "f", `output format ("text" or "json")`)
+	cmd.Flags().IntVarP(&opts.minStrength, "min-strength", "", 0, `report passwords with a strength below this, from 0 to 4 (default: from the config, or 3)`)
+	cmd.Flags().IntVarP(&opts.maxAge, "max-age", "", 365, `report passwords not modified for more than this many days`)
+	cmd.Flags().StringVarP(&opts.breached, "breached", "", "", `report passwords found in this local Pwned Passwords SHA-1 file (sorted by hash) or range directory (default: "")`)
+
+	return cmd
+}
@@ -0,0 +1,126 @@
+// Copyright 2026 Miklos Vajna
+//
+// SPDX-License-Identifier: MIT
+
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"testing"
+)
+
+func TestAudit(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	_, err := ctx.Database.Exec(`insert into passwords (machine, service, user, password, type, modified) values('mymachine', 'http', 'myuser', '7U1FvIzubR95Itg', 'plain', '2020-05-01T00:00:00+02:00');
+	                             insert into passwords (machine, service, user, password, type, modified) values('othermachine', 'http', 'myuser', '7U1FvIzubR95Itg', 'plain', '2020-05-01T00:00:00+02:00');
+	                             insert into passwords (machine, service, user, password, type, modified) values('weakmachine', 'http', 'myuser', '123456', 'plain', '2020-05-01T00:00:00+02:00');
+	                             insert into passwords (machine, service, user, password, type, modified) values('oldmachine', 'http', 'myuser', 'pJ3mAxgK1Tz9Qd0', 'plain', '2018-05-10T00:00:00+02:00');
+	                             insert into passwords (machine, service, user, password, type) values('totpmachine', 'http', 'myuser', 'not-base32', 'totp');
+	                             insert into passwords (machine, service, user, password, type) values('totpmachine', 'http', 'otheruser', 'JBSWY3DPEHPK3PXP', 'totp');
+	                             insert into passwords (machine, service, user, password, type, archived) values('archivedmachine', 'http', 'myuser', '123456', 'plain', 1);
+	                             insert into passwords (machine, service, user, password, type) values('legacymachine', 'http', 'myuser', 'K8vLq2RtWm5Zs7n', 'plain');
+	                             insert into passwords (machine, service, user, password, type) values('codesmachine', 'http', 'myuser', '[{"Code":"1"}]', 'recovery-codes');`)
+	if err != nil {
+		t.Fatalf("db.Exec() = %q, want nil", err)
+	}
+	os.Args = []string{"", "audit"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 1
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	// Archived passwords, recovery codes, good TOTP shared secrets and passwords without a modification date are
+	// not reported.
+	expectedBuf := "id:        1, machine: mymachine, service: http, user: myuser, problem: reused, details: same password as on othermachine\n" +
+		"id:        2, machine: othermachine, service: http, user: myuser, problem: reused, details: same password as on mymachine\n" +
+		"id:        3, machine: weakmachine, service: http, user: myuser, problem: weak, details: strength is 0/4\n" +
+		"id:        4, machine: oldmachine, service: http, user: myuser, problem: stale, details: not modified for 731 days\n" +
+		"id:        5, machine: totpmachine, service: http, user: myuser, problem: bad-totp, details: Decoding of secret as base32 failed.\n"
+	if outBuf.String() != expectedBuf {
+		t.Fatalf("Main() output is %q, want %q", outBuf.String(), expectedBuf)
+	}
+}
+
+func TestAuditJSON(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	UseConfigForTesting(t, `{"MinStrength": 0}`)
+	_, err := ctx.Database.Exec(`insert into passwords (machine, service, user, password, type, modified) values('weakmachine', 'http', 'myuser', '123456', 'plain', '2020-05-01T00:00:00+02:00');
+	                             insert into passwords (machine, service, user, password, type, modified) values('oldmachine', 'http', 'myuser', 'pJ3mAxgK1Tz9Qd0', 'plain', '2020-01-01T00:00:00+02:00');`)
+	if err != nil {
+		t.Fatalf("db.Exec() = %q, want nil", err)
+	}
+	// The weak password is not reported, since the config allows it.
+	os.Args = []string{"", "audit", "--format", "json", "--max-age", "30"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 1
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	var findings []auditFinding
+	err = json.Unmarshal(outBuf.Bytes(), &findings)
+	if err != nil {
+		t.Fatalf("json.Unmarshal() failed: %s", err)
+	}
+	expected := auditFinding{ID: 2, Machine: "oldmachine", Service: "http", User: "myuser", Problem: "stale", Details: "not modified for 130 days"}
+	if len(findings) != 1 || findings[0] != expected {
+		t.Fatalf("findings = %v, want [%v]", findings, expected)
+	}
+}
+
+func TestAuditNoFindings(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	_, err := ctx.Database.Exec(`insert into passwords (machine, service, user, password, type, modified) values('weakmachine', 'http', 'myuser', '123456', 'plain', '2020-05-01T00:00:00+02:00');`)
+	if err != nil {
+		t.Fatalf("db.Exec() = %q, want nil", err)
+	}
+	os.Args = []string{"", "audit", "-f", "json", "--min-strength", "0"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	expectedBuf := "[]\n"
+	if outBuf.String() != expectedBuf {
+		t.Fatalf("Main() output is %q, want %q", outBuf.String(), expectedBuf)
+	}
+}
+
+// Audit fails because the output format is not supported.
+func TestAuditBadFormat(t *testing.T) {
+	CreateContextForTesting(t)
+	os.Args = []string{"", "audit", "--format", "xml"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 1
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q", actualRet, expectedRet)
+	}
+}
+
+func TestAuditFormatType(t *testing.T) {
+	var format AuditFormat
+
+	actual := format.Type()
+
+	expected := "AuditFormat"
+	if actual != expected {
+		t.Fatalf("Type() = %q, want %q", actual, expected)
+	}
+}
@@ -0,0 +1,301 @@
+// Copyright 2026 Miklos Vajna
+//
+// SPDX-License-Identifier: MIT
+
+package commands
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html/template"
+	"os"
+
+	"github.com/spf13/cobra"
+	"rsc.io/qr"
+)
+
+const (
+	// paperChunkPrefix starts the text of the numbered QR codes of a paper backup.
+	paperChunkPrefix = "cpm-paper"
+	// paperChunkSize is the number of base64 characters in one numbered QR code, small enough to
+	// scan reliably from paper.
+	paperChunkSize = 800
+	// paperBackupTemplate is the printable HTML document of a paper backup, QR codes are inline SVG
+	// images.
+	paperBackupTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>cpm paper backup</title>
+<style>
+body { font-family: sans-serif; font-size: 10pt; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border: 1px solid #000000; padding: 2mm; text-align: left; vertical-align: top; }
+tr, figure { break-inside: avoid; }
+.password { font-family: monospace; word-break: break-all; }
+.totp { width: 30mm; }
+.chunk { display: inline-block; margin: 2mm; text-align: center; }
+.chunk img { width: 80mm; }
+</style>
+</head>
+<body>
+<h1>cpm paper backup</h1>
+<p>Created at {{.Created}}.</p>
+<table>
+<tr><th>Machine</th><th>Service</th><th>User</th><th>Type</th><th>Password</th></tr>
+{{- range .Passwords}}
+<tr><td>{{.Machine}}</td><td>{{.Service}}</td><td>{{.User}}</td><td>{{.Type}}</td><td class="password">{{.Password}}{{if .QRCode}}<br><img class="totp" src="{{.QRCode}}" alt="TOTP shared secret">{{end}}</td></tr>
+{{- end}}
+</table>
+{{- if .Chunks}}
+<h2>{{.ChunksTitle}}</h2>
+<p>Scan the numbered QR codes to a text file, one code per line, in any order, then run
+<code>cpm restore paper FILE --out {{.ChunksFile}}</code>.</p>
+{{- range .Chunks}}
+<figure class="chunk"><img src="{{.QRCode}}" alt="chunk {{.Number}} of {{.Total}}"><figcaption>{{.Number}} / {{.Total}}</figcaption></figure>
+{{- end}}
+{{- end}}
+</body>
+</html>
+`
+)
+
+// PaperChunks is an enum of possible contents of the numbered QR codes of a paper backup.
+type PaperChunks string
+
+const (
+	// PaperChunksNone omits the numbered QR codes.
+	PaperChunksNone PaperChunks = "none"
+	// PaperChunksVault is the encrypted database.
+	PaperChunksVault PaperChunks = "vault"
+	// PaperChunksExport is the JSON export, which is not encrypted.
+	PaperChunksExport PaperChunks = "export"
+)
+
+func (c *PaperChunks) String() string {
+	return string(*c)
+}
+
+// Set sets the value of `c` from `v`.
+func (c *PaperChunks) Set(v string) error {
+	switch v {
+	case "none", "vault", "export":
+		*c = PaperChunks(v)
+		return nil
+	default:
+		return errors.New(`must be one of "none", "vault", or "export"`)
+	}
+}
+
+// Type returns the type of `c` as a string.
+func (c *PaperChunks) Type() string {
+	return "PaperChunks"
+}
+
+// paperBackupPassword is a password in the table of a paper backup.
+type paperBackupPassword struct {
+	Machine  string
+	Service  string
+	User     string
+	Type     PasswordType
+	Password string
+	QRCode   template.URL
+}
+
+// paperBackupChunk is a numbered QR code of a paper backup.
+type paperBackupChunk struct {
+	Number int
+	Total  int
+	QRCode template.URL
+}
+
+// paperBackup is the content of the printable HTML document.
+type paperBackup struct {
+	Created     string
+	Passwords   []paperBackupPassword
+	ChunksTitle string
+	ChunksFile  string
+	Chunks      []paperBackupChunk
+}
+
+// getQrcodeDataURL renders `text` as a QR code, which can be inlined in an HTML document.
+func getQrcodeDataURL(text string) (template.URL, error) {
+	code, err := qr.Encode(text, qr.M)
+	if err != nil {
+		return "", fmt.Errorf("qr.Encode() failed: %s", err)
+	}
+
+	return template.URL("data:image/svg+xml;base64," + base64.StdEncoding.EncodeToString(encodeQrcodeSvg(code))), nil
+}
+
+// getPaperChecksum returns the checksum of the payload of a paper backup, which is repeated in each
+// chunk.
+func getPaperChecksum(payload []byte) string {
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:4])
+}
+
+// encodePaperChunks splits `payload` into the texts of numbered QR codes:
+// "cpm-paper:NUMBER/TOTAL:CHECKSUM:BASE64".
+func encodePaperChunks(payload []byte) []string {
+	encoded := base64.StdEncoding.EncodeToString(payload)
+	checksum := getPaperChecksum(payload)
+	total := (len(encoded) + paperChunkSize - 1) / paperChunkSize
+	var chunks []string
+	for i := 0; i < total; i++ {
+		end := min((i+1)*paperChunkSize, len(encoded))
+		chunks = append(chunks, fmt.Sprintf("%s:%d/%d:%s:%s", paperChunkPrefix, i+1, total, checksum, encoded[i*paperChunkSize:end]))
+	}
+
+	return chunks
+}
+
+// getPaperPasswords returns the not archived passwords for the table of a paper backup, TOTP shared
+// secrets also as QR codes.
+func getPaperPasswords(ctx *Context) ([]paperBackupPassword, error) {
+	rows, err := selectPasswords(ctx.Database, searchOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("selectPasswords() failed: %s", err)
+	}
+
+	var passwords []paperBackupPassword
+	for _, row := range rows {
+		password := paperBackupPassword{
+			Machine:  row.Machine,
+			Service:  row.Service,
+			User:     row.User,
+			Type:     row.PasswordType,
+			Password: row.Password,
+		}
+		switch row.PasswordType {
+		case PasswordTypeTotp:
+			password.Password = getTotpURL(row.Machine, row.User, row.Password)
+			password.QRCode, err = getQrcodeDataURL(password.Password)
+			if err != nil {
+				return nil, fmt.Errorf("getQrcodeDataURL() failed: %s", err)
+			}
+		case PasswordTypeRecoveryCodes:
+			password.Password = formatUnusedRecoveryCodes(row.Password)
+		}
+		passwords = append(passwords, password)
+	}
+
+	return passwords, nil
+}
+
+// getPaperPayload returns the content of the numbered QR codes of a paper backup.
+func getPaperPayload(ctx *Context, chunks PaperChunks) ([]byte, error) {
+	if chunks == PaperChunksExport {
+		payload, err := exportPasswords(ctx.Database, searchOptions{verbose: true})
+		if err != nil {
+			return nil, fmt.Errorf("exportPasswords() failed: %s", err)
+		}
+		return payload, nil
+	}
+
+	databasePath, err := getDatabasePath()
+	if err != nil {
+		return nil, fmt.Errorf("getDatabasePath() failed: %s", err)
+	}
+
+	payload, err := os.ReadFile(databasePath)
+	if err != nil {
+		return nil, fmt.Errorf("os.ReadFile() failed: %s", err)
+	}
+
+	return payload, nil
+}
+
+// createPaperBackup renders the printable HTML document of a paper backup.
+func createPaperBackup(ctx *Context, chunks PaperChunks) ([]byte, int, error) {
+	passwords, err := getPaperPasswords(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("getPaperPasswords() failed: %s", err)
+	}
+
+	backup := paperBackup{
+		Created:   Now().Format("2006-01-02 15:04"),
+		Passwords: passwords,
+	}
+	if chunks != PaperChunksNone {
+		payload, err := getPaperPayload(ctx, chunks)
+		if err != nil {
+			return nil, 0, fmt.Errorf("getPaperPayload() failed: %s", err)
+		}
+
+		backup.ChunksTitle = "Encrypted database"
+		backup.ChunksFile = "passwords.db"
+		if chunks == PaperChunksExport {
+			backup.ChunksTitle = "JSON export"
+			backup.ChunksFile = "export.json"
+		}
+		texts := encodePaperChunks(payload)
+		for i, text := range texts {
+			qrcode, err := getQrcodeDataURL(text)
+			if err != nil {
+				return nil, 0, fmt.Errorf("getQrcodeDataURL() failed: %s", err)
+			}
+
+			backup.Chunks = append(backup.Chunks, paperBackupChunk{Number: i + 1, Total: len(texts), QRCode: qrcode})
+		}
+	}
+
+	tmpl, err := template.New("paper").Parse(paperBackupTemplate)
+	if err != nil {
+		return nil, 0, fmt.Errorf("template.Parse() failed: %s", err)
+	}
+
+	var buf bytes.Buffer
+	err = tmpl.Execute(&buf, backup)
+	if err != nil {
+		return nil, 0, fmt.Errorf("Execute() failed: %s", err)
+	}
+
+	return buf.Bytes(), len(passwords), nil
+}
+
+func newBackupPaperCommand(ctx *Context) *cobra.Command {
+	var outPath string
+	var chunks PaperChunks = PaperChunksNone
+	var cmd = &cobra.Command{
+		Use:   "paper",
+		Short: "writes a printable HTML document with all passwords and QR codes",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx.NoWriteBack = true
+			if len(outPath) == 0 {
+				return errors.New("--out is required")
+			}
+
+			content, count, err := createPaperBackup(ctx, chunks)
+			if err != nil {
+				return fmt.Errorf("createPaperBackup() failed: %s", err)
+			}
+
+			err = os.WriteFile(outPath, content, 0600)
+			if err != nil {
+				return fmt.Errorf("os.WriteFile() failed: %s", err)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Wrote %v passwords to '%s'\n", count, outPath)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&outPath, "out", "o", "", `path of the HTML document (default: "")`)
+	cmd.Flags().VarP(&chunks, "chunks", "", `also add the encrypted database or the JSON export as numbered QR codes ("none", "vault" or "export"; default: "none")`)
+
+	return cmd
+}
+
+func newBackupCommand(ctx *Context) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "backup",
+		Short: "creates backups of the password database",
+	}
+	cmd.AddCommand(newBackupPaperCommand(ctx))
+
+	return cmd
+}
@@ -0,0 +1,149 @@
+// Copyright 2026 Miklos Vajna
+//
+// SPDX-License-Identifier: MIT
+
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBackupPaper(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	_, err := ctx.Database.Exec(`insert into passwords (machine, service, user, password, type) values('a&b.com', 'http', 'alice', 'alicepassword', 'plain');
+	                             insert into passwords (machine, service, user, password, type) values('a&b.com', 'http', 'alice', 'JBSWY3DPEHPK3PXP', 'totp');
+	                             insert into passwords (machine, service, user, password, type) values('a&b.com', 'http', 'alice', '[{"Code":"a1","Used":true},{"Code":"b2"}]', 'recovery-codes');
+	                             insert into passwords (machine, service, user, password, type, archived) values('gmail.com', 'email', 'bob', 'bobpassword', 'plain', 1);`)
+	if err != nil {
+		t.Fatalf("db.Exec() = %q, want nil", err)
+	}
+	path := filepath.Join(t.TempDir(), "backup.html")
+	os.Args = []string{"", "backup", "paper", "--out", path}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q", actualRet, expectedRet)
+	}
+	expectedBuf := fmt.Sprintf("Wrote 3 passwords to '%s'\n", path)
+	if outBuf.String() != expectedBuf {
+		t.Fatalf("Main() output is %q, want %q", outBuf.String(), expectedBuf)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("os.ReadFile() err = %q, want nil", err)
+	}
+	html := string(content)
+	for _, expected := range []string{
+		"<p>Created at 2020-05-10 00:00.</p>",
+		"<td>a&amp;b.com</td>",
+		"<td class=\"password\">alicepassword</td>",
+		"otpauth://totp/a&amp;b.com:alice?issuer=a%26b.com&amp;secret=JBSWY3DPEHPK3PXP<br><img class=\"totp\" src=\"data:image/svg&#43;xml;base64,",
+		"<td class=\"password\">b2</td>",
+	} {
+		if !strings.Contains(html, expected) {
+			t.Fatalf("html is %q, want it to contain %q", html, expected)
+		}
+	}
+	for _, unexpected := range []string{"bobpassword", "<h2>"} {
+		if strings.Contains(html, unexpected) {
+			t.Fatalf("html is %q, want it to not contain %q", html, unexpected)
+		}
+	}
+}
+
+// The encrypted database or the JSON export is added as numbered QR codes.
+func TestBackupPaperChunks(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	_, err := ctx.Database.Exec(`insert into passwords (machine, service, user, password, type) values('mymachine', 'http', 'myuser', 'mypassword', 'plain');`)
+	if err != nil {
+		t.Fatalf("db.Exec() = %q, want nil", err)
+	}
+	stateDir := t.TempDir()
+	t.Setenv(xdgStateHome, stateDir)
+	err = os.MkdirAll(filepath.Join(stateDir, "cpm"), 0700)
+	if err != nil {
+		t.Fatalf("os.MkdirAll() failed: %s", err)
+	}
+	vault := []byte(strings.Repeat("-----BEGIN PGP MESSAGE-----\n", 50))
+	err = os.WriteFile(filepath.Join(stateDir, "cpm", "passwords.db"), vault, 0600)
+	if err != nil {
+		t.Fatalf("os.WriteFile() failed: %s", err)
+	}
+	export, err := exportPasswords(ctx.Database, searchOptions{verbose: true})
+	if err != nil {
+		t.Fatalf("exportPasswords() err = %q, want nil", err)
+	}
+	tests := []struct {
+		chunks   string
+		title    string
+		expected int
+	}{
+		{"vault", "<h2>Encrypted database</h2>", len(encodePaperChunks(vault))},
+		{"export", "<h2>JSON export</h2>", len(encodePaperChunks(export))},
+	}
+	for _, test := range tests {
+		path := filepath.Join(t.TempDir(), "backup.html")
+		os.Args = []string{"", "backup", "paper", "--out", path, "--chunks", test.chunks}
+		inBuf := new(bytes.Buffer)
+		outBuf := new(bytes.Buffer)
+
+		actualRet := Main(inBuf, outBuf)
+
+		expectedRet := 0
+		if actualRet != expectedRet {
+			t.Fatalf("Main() = %q, want %q", actualRet, expectedRet)
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("os.ReadFile() err = %q, want nil", err)
+		}
+		html := string(content)
+		if !strings.Contains(html, test.title) {
+			t.Fatalf("html is %q, want it to contain %q", html, test.title)
+		}
+		actual := strings.Count(html, `<figure class="chunk">`)
+		if actual != test.expected {
+			t.Fatalf("chunks = %v, want %v", actual, test.expected)
+		}
+		last := fmt.Sprintf("<figcaption>%d / %d</figcaption>", test.expected, test.expected)
+		if !strings.Contains(html, last) {
+			t.Fatalf("html is %q, want it to contain %q", html, last)
+		}
+	}
+}
+
+// Backup fails because --out is missing or the database is missing.
+func TestBackupPaperInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "backup.html")
+	tests := [][]string{
+		{"backup", "paper"},
+		{"backup", "paper", "--chunks", "vault", "--out", path},
+	}
+	for _, args := range tests {
+		ctx := CreateContextForTesting(t)
+		t.Setenv(xdgStateHome, t.TempDir())
+		_, err := ctx.Database.Exec(`insert into passwords (machine, service, user, password, type) values('mymachine', 'http', 'myuser', 'mypassword', 'plain');`)
+		if err != nil {
+			t.Fatalf("db.Exec() = %q, want nil", err)
+		}
+		os.Args = append([]string{""}, args...)
+		inBuf := new(bytes.Buffer)
+		outBuf := new(bytes.Buffer)
+
+		actualRet := Main(inBuf, outBuf)
+
+		expectedRet := 1
+		if actualRet != expectedRet {
+			t.Fatalf("Main() = %q, want %q, args are %q", actualRet, expectedRet, args)
+		}
+	}
+}
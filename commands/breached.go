@@ -0,0 +1,139 @@
+// Copyright 2026 Miklos Vajna
+//
+// SPDX-License-Identifier: MIT
+
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// readHashLine reads the first "HASH:COUNT" line which starts at or after `off`. It returns the
+// line without the line ending, together with the offset of the next line. The line is empty at the
+// end of the file.
+func readHashLine(r io.ReaderAt, size, off int64) (string, int64, error) {
+	start := off
+	reader := bufio.NewReader(io.NewSectionReader(r, off, size-off))
+	if off > 0 {
+		// Maybe in the middle of a line: skip to the start of the next one, unless a line
+		// starts exactly at `off`.
+		reader = bufio.NewReader(io.NewSectionReader(r, off-1, size-off+1))
+		skipped, err := reader.ReadString('\n')
+		if err == io.EOF {
+			return "", size, nil
+		}
+		if err != nil {
+			return "", 0, fmt.Errorf("ReadString() failed: %s", err)
+		}
+		start = off - 1 + int64(len(skipped))
+	}
+
+	line, err := reader.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", 0, fmt.Errorf("ReadString() failed: %s", err)
+	}
+
+	return strings.TrimRight(line, "\r\n"), start + int64(len(line)), nil
+}
+
+// searchHashFile finds `key` in a file of "HASH:COUNT" lines, sorted by hash, using binary search.
+// It returns the count, or 0 if the hash is not in the file.
+func searchHashFile(r io.ReaderAt, size int64, key string) (int, error) {
+	// The key is in a line which starts in [lo, hi).
+	lo, hi := int64(0), size
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		line, next, err := readHashLine(r, size, mid)
+		if err != nil {
+			return 0, fmt.Errorf("readHashLine() failed: %s", err)
+		}
+
+		hash, count, _ := strings.Cut(line, ":")
+		switch {
+		case len(line) == 0 || strings.ToUpper(hash) > key:
+			hi = mid
+		case strings.ToUpper(hash) < key:
+			lo = next
+		default:
+			ret, err := strconv.Atoi(count)
+			if err != nil {
+				return 0, fmt.Errorf("strconv.Atoi() failed: %s", err)
+			}
+			return ret, nil
+		}
+	}
+
+	return 0, nil
+}
+
+// breachedHashes is a local copy of the Pwned Passwords SHA-1 hashes: either a single file, sorted by
+// hash, or a directory of range files, like 21BD1.txt for hashes starting with 21BD1.
+type breachedHashes struct {
+	path string
+	dir  bool
+	file *os.File
+	size int64
+}
+
+func openBreachedHashes(path string) (*breachedHashes, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("os.Stat() failed: %s", err)
+	}
+
+	b := &breachedHashes{path: path, dir: info.IsDir()}
+	if !b.dir {
+		b.file, err = os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("os.Open() failed: %s", err)
+		}
+		b.size = info.Size()
+	}
+
+	return b, nil
+}
+
+func (b *breachedHashes) close() {
+	if b.file != nil {
+		b.file.Close()
+	}
+}
+
+// lookup returns how many times `password` was seen in breaches, 0 means not found.
+func (b *breachedHashes) lookup(password string) (int, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	if !b.dir {
+		count, err := searchHashFile(b.file, b.size, hash)
+		if err != nil {
+			return 0, fmt.Errorf("searchHashFile() failed: %s", err)
+		}
+		return count, nil
+	}
+
+	// A range file only has the suffixes of the hashes.
+	prefix, suffix := hash[:5], hash[5:]
+	path := filepath.Join(b.path, prefix+".txt")
+	if !pathExists(path) {
+		path = filepath.Join(b.path, prefix)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("os.ReadFile() failed: %s", err)
+	}
+
+	count, err := searchHashFile(bytes.NewReader(content), int64(len(content)), suffix)
+	if err != nil {
+		return 0, fmt.Errorf("searchHashFile() failed: %s", err)
+	}
+	return count, nil
+}
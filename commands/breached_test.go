@@ -0,0 +1,198 @@
+// Copyright 2026 Miklos Vajna
+//
+// SPDX-License-Identifier: MIT
+
+package commands
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func getSha1ForTesting(s string) string {
+	sum := sha1.Sum([]byte(s))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+func TestSearchHashFile(t *testing.T) {
+	counts := make(map[string]int)
+	var hashes []string
+	for i := 0; i < 500; i++ {
+		hash := getSha1ForTesting(fmt.Sprintf("password%d", i))
+		counts[hash] = i + 1
+		hashes = append(hashes, hash)
+	}
+	sort.Strings(hashes)
+	var content string
+	for _, hash := range hashes {
+		content += fmt.Sprintf("%s:%d\r\n", hash, counts[hash])
+	}
+	reader := strings.NewReader(content)
+	size := int64(len(content))
+
+	for _, hash := range hashes {
+		actual, err := searchHashFile(reader, size, hash)
+		if err != nil {
+			t.Fatalf("searchHashFile() err = %q, want nil", err)
+		}
+		if actual != counts[hash] {
+			t.Fatalf("searchHashFile(%s) = %v, want %v", hash, actual, counts[hash])
+		}
+	}
+	for _, hash := range []string{strings.Repeat("0", 40), strings.Repeat("F", 40), getSha1ForTesting("not-breached")} {
+		actual, err := searchHashFile(reader, size, hash)
+		if err != nil {
+			t.Fatalf("searchHashFile() err = %q, want nil", err)
+		}
+		if actual != 0 {
+			t.Fatalf("searchHashFile(%s) = %v, want 0", hash, actual)
+		}
+	}
+}
+
+func TestSearchHashFileNoTrailingNewline(t *testing.T) {
+	content := strings.Repeat("1", 40) + ":1\n" + strings.Repeat("2", 40) + ":2"
+	reader := strings.NewReader(content)
+	size := int64(len(content))
+
+	for key, expected := range map[string]int{strings.Repeat("2", 40): 2, strings.Repeat("3", 40): 0} {
+		actual, err := searchHashFile(reader, size, key)
+		if err != nil {
+			t.Fatalf("searchHashFile() err = %q, want nil", err)
+		}
+		if actual != expected {
+			t.Fatalf("searchHashFile(%s) = %v, want %v", key, actual, expected)
+		}
+	}
+}
+
+func TestReadHashLine(t *testing.T) {
+	content := "AA:1\r\nBB:2"
+	reader := strings.NewReader(content)
+	size := int64(len(content))
+
+	for off, expected := range map[int64]string{0: "AA:1", 1: "BB:2", 6: "BB:2", 7: ""} {
+		actual, _, err := readHashLine(reader, size, off)
+		if err != nil {
+			t.Fatalf("readHashLine() err = %q, want nil", err)
+		}
+		if actual != expected {
+			t.Fatalf("readHashLine(%d) = %q, want %q", off, actual, expected)
+		}
+	}
+}
+
+func TestAuditBreached(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	_, err := ctx.Database.Exec(`insert into passwords (machine, service, user, password, type) values('mymachine', 'http', 'myuser', '7U1FvIzubR95Itg', 'plain');
+	                             insert into passwords (machine, service, user, password, type) values('othermachine', 'http', 'myuser', 'pJ3mAxgK1Tz9Qd0', 'plain');`)
+	if err != nil {
+		t.Fatalf("db.Exec() = %q, want nil", err)
+	}
+	hashes := []string{
+		getSha1ForTesting("7U1FvIzubR95Itg") + ":42",
+		getSha1ForTesting("123456") + ":37359195",
+		getSha1ForTesting("password") + ":9545824",
+	}
+	sort.Strings(hashes)
+	path := filepath.Join(t.TempDir(), "pwned-passwords-sha1-ordered-by-hash.txt")
+	err = os.WriteFile(path, []byte(strings.Join(hashes, "\n")+"\n"), 0600)
+	if err != nil {
+		t.Fatalf("os.WriteFile() failed: %s", err)
+	}
+	os.Args = []string{"", "audit", "--breached", path}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 1
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	expectedBuf := "id:        1, machine: mymachine, service: http, user: myuser, problem: breached, details: seen 42 times in data breaches\n"
+	if outBuf.String() != expectedBuf {
+		t.Fatalf("Main() output is %q, want %q", outBuf.String(), expectedBuf)
+	}
+}
+
+func TestAuditBreachedRangeDir(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	_, err := ctx.Database.Exec(`insert into passwords (machine, service, user, password, type) values('mymachine', 'http', 'myuser', '7U1FvIzubR95Itg', 'plain');
+	                             insert into passwords (machine, service, user, password, type) values('othermachine', 'http', 'myuser', 'pJ3mAxgK1Tz9Qd0', 'plain');`)
+	if err != nil {
+		t.Fatalf("db.Exec() = %q, want nil", err)
+	}
+	dir := t.TempDir()
+	// Range files only contain the hash suffix, both the .txt and the extensionless naming is
+	// accepted.
+	breached := getSha1ForTesting("7U1FvIzubR95Itg")
+	content := fmt.Sprintf("%s:3\r\n%s:42\r\n%s:5\r\n", strings.Repeat("0", 35), breached[5:], strings.Repeat("F", 35))
+	err = os.WriteFile(filepath.Join(dir, breached[:5]+".txt"), []byte(content), 0600)
+	if err != nil {
+		t.Fatalf("os.WriteFile() failed: %s", err)
+	}
+	notBreached := getSha1ForTesting("pJ3mAxgK1Tz9Qd0")
+	err = os.WriteFile(filepath.Join(dir, notBreached[:5]), []byte(strings.Repeat("0", 35)+":1\r\n"), 0600)
+	if err != nil {
+		t.Fatalf("os.WriteFile() failed: %s", err)
+	}
+	os.Args = []string{"", "audit", "--breached", dir}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 1
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	expectedBuf := "id:        1, machine: mymachine, service: http, user: myuser, problem: breached, details: seen 42 times in data breaches\n"
+	if outBuf.String() != expectedBuf {
+		t.Fatalf("Main() output is %q, want %q", outBuf.String(), expectedBuf)
+	}
+}
+
+// Audit fails because the range file of a password is missing.
+func TestAuditBreachedRangeDirIncomplete(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	_, err := ctx.Database.Exec(`insert into passwords (machine, service, user, password, type) values('mymachine', 'http', 'myuser', '7U1FvIzubR95Itg', 'plain');`)
+	if err != nil {
+		t.Fatalf("db.Exec() = %q, want nil", err)
+	}
+	os.Args = []string{"", "audit", "--breached", t.TempDir()}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 1
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q", actualRet, expectedRet)
+	}
+	if outBuf.String() != "" {
+		t.Fatalf("Main() output is %q, want no findings", outBuf.String())
+	}
+}
+
+// Audit fails because the hash file doesn't exist.
+func TestAuditBreachedNoSuchFile(t *testing.T) {
+	CreateContextForTesting(t)
+	os.Args = []string{"", "audit", "--breached", filepath.Join(t.TempDir(), "no-such-file.txt")}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 1
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q", actualRet, expectedRet)
+	}
+}
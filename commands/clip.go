@@ -0,0 +1,132 @@
+// Copyright 2026 Miklos Vajna
+//
+// SPDX-License-Identifier: MIT
+
+package commands
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// getClipboardTool decides how to access the clipboard: "wl-copy" on Wayland, "xclip" on X11, or
+// "" for the OSC 52 terminal escape sequence, which also works over ssh.
+func getClipboardTool() string {
+	if len(os.Getenv("WAYLAND_DISPLAY")) > 0 {
+		if _, err := LookPath("wl-copy"); err == nil {
+			return "wl-copy"
+		}
+	}
+
+	if len(os.Getenv("DISPLAY")) > 0 {
+		if _, err := LookPath("xclip"); err == nil {
+			return "xclip"
+		}
+	}
+
+	return ""
+}
+
+// copyToClipboard puts `text` on the clipboard, the terminal escape sequence is written to `w`.
+func copyToClipboard(w io.Writer, tool, text string) error {
+	switch tool {
+	case "wl-copy":
+		command := Command("wl-copy")
+		command.Stdin = strings.NewReader(text)
+		err := command.Run()
+		if err != nil {
+			return fmt.Errorf("Command() failed to run 'wl-copy': %s", err)
+		}
+	case "xclip":
+		command := Command("xclip", "-selection", "clipboard")
+		command.Stdin = strings.NewReader(text)
+		err := command.Run()
+		if err != nil {
+			return fmt.Errorf("Command() failed to run 'xclip -selection clipboard': %s", err)
+		}
+	default:
+		fmt.Fprintf(w, "\x1b]52;c;%s\x07", base64.StdEncoding.EncodeToString([]byte(text)))
+	}
+
+	return nil
+}
+
+// clearClipboard clears the clipboard, unless its content is no longer `text`. The clipboard can't
+// be read with OSC 52, so that is cleared unconditionally.
+func clearClipboard(w io.Writer, tool, text string) (bool, error) {
+	var command []string
+	switch tool {
+	case "wl-copy":
+		command = []string{"wl-paste", "--no-newline"}
+	case "xclip":
+		command = []string{"xclip", "-selection", "clipboard", "-o"}
+	default:
+		// Not valid base64, which clears the selection.
+		fmt.Fprintf(w, "\x1b]52;c;!\x07")
+		return true, nil
+	}
+
+	output, err := Command(command[0], command[1:]...).Output()
+	if err != nil {
+		return false, fmt.Errorf("Command() failed to run '%s': %s", strings.Join(command, " "), err)
+	}
+
+	if string(output) != text {
+		// Something else was copied since then, leave that alone.
+		return false, nil
+	}
+
+	if tool == "wl-copy" {
+		err = Command("wl-copy", "--clear").Run()
+		if err != nil {
+			return false, fmt.Errorf("Command() failed to run 'wl-copy --clear': %s", err)
+		}
+		return true, nil
+	}
+
+	err = copyToClipboard(w, tool, "")
+	if err != nil {
+		return false, fmt.Errorf("copyToClipboard() failed: %s", err)
+	}
+	return true, nil
+}
+
+// clipPassword copies `password` to the clipboard and clears it after `timeout` seconds.
+func clipPassword(cmd *cobra.Command, ctx *Context, password string, timeout int) error {
+	tool := getClipboardTool()
+	err := copyToClipboard(cmd.OutOrStdout(), tool, password)
+	if err != nil {
+		return fmt.Errorf("copyToClipboard() failed: %s", err)
+	}
+
+	if timeout <= 0 {
+		fmt.Fprintf(cmd.OutOrStdout(), "Copied the password to the clipboard\n")
+		return nil
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Copied the password to the clipboard, clearing it in %d seconds\n", timeout)
+	// Don't keep the decrypted database around while waiting, but write back a migrated one first.
+	err = CloseDatabase(ctx)
+	if err != nil {
+		return fmt.Errorf("CloseDatabase() failed: %s", err)
+	}
+	cleanDatabase(ctx)
+	Sleep(time.Duration(timeout) * time.Second)
+	cleared, err := clearClipboard(cmd.OutOrStdout(), tool, password)
+	if err != nil {
+		return fmt.Errorf("clearClipboard() failed: %s", err)
+	}
+
+	if cleared {
+		fmt.Fprintf(cmd.OutOrStdout(), "Cleared the clipboard\n")
+	} else {
+		fmt.Fprintf(cmd.OutOrStdout(), "Not clearing the clipboard, its content changed in the meantime\n")
+	}
+	return nil
+}
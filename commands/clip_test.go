@@ -0,0 +1,310 @@
+// Copyright 2026 Miklos Vajna
+//
+// SPDX-License-Identifier: MIT
+
+package commands
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// UseClipboardForTesting simulates a clipboard using a file: wl-copy, wl-paste and xclip are
+// available, waiting calls `onSleep` instead, other commands are passed to the previous Command.
+// Returns the path of the clipboard file.
+func UseClipboardForTesting(t *testing.T, onSleep func(path string, d time.Duration)) string {
+	path := filepath.Join(t.TempDir(), "clipboard")
+	oldCommand := Command
+	Command = func(name string, arg ...string) *exec.Cmd {
+		command := strings.Join(append([]string{name}, arg...), " ")
+		switch command {
+		case "wl-copy", "xclip -selection clipboard":
+			return exec.Command("sh", "-c", `cat > "$0"`, path)
+		case "wl-copy --clear":
+			return exec.Command("sh", "-c", `: > "$0"`, path)
+		case "wl-paste --no-newline", "xclip -selection clipboard -o":
+			return exec.Command("cat", path)
+		}
+		return oldCommand(name, arg...)
+	}
+	t.Cleanup(func() { Command = oldCommand })
+	oldLookPath := LookPath
+	LookPath = func(file string) (string, error) {
+		return "/usr/bin/" + file, nil
+	}
+	t.Cleanup(func() { LookPath = oldLookPath })
+	oldSleep := Sleep
+	Sleep = func(d time.Duration) {
+		onSleep(path, d)
+	}
+	t.Cleanup(func() { Sleep = oldSleep })
+	return path
+}
+
+// readClipboardForTesting returns the content of the simulated clipboard.
+func readClipboardForTesting(t *testing.T, path string) string {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("os.ReadFile() failed: %s", err)
+	}
+	return string(content)
+}
+
+func TestClipWayland(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	t.Setenv("WAYLAND_DISPLAY", "wayland-0")
+	var clipped string
+	var slept time.Duration
+	path := UseClipboardForTesting(t, func(path string, d time.Duration) {
+		clipped = readClipboardForTesting(t, path)
+		slept = d
+	})
+	_, err := ctx.Database.Exec(`insert into passwords (machine, service, user, password, type) values('mymachine', 'http', 'myuser', 'mypassword', 'plain');
+	                             insert into passwords (machine, service, user, password, type) values('othermachine', 'http', 'myuser', 'otherpassword', 'plain');`)
+	if err != nil {
+		t.Fatalf("db.Exec() = %q, want nil", err)
+	}
+	os.Args = []string{"", "search", "--clip", "-m", "mymachine"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	// The password is not printed.
+	expectedBuf := "Copied the password to the clipboard, clearing it in 45 seconds\nCleared the clipboard\n"
+	if outBuf.String() != expectedBuf {
+		t.Fatalf("Main() output is %q, want %q", outBuf.String(), expectedBuf)
+	}
+	if clipped != "mypassword" {
+		t.Fatalf("clipped = %q, want %q", clipped, "mypassword")
+	}
+	if slept != 45*time.Second {
+		t.Fatalf("slept = %v, want 45s", slept)
+	}
+	if actual := readClipboardForTesting(t, path); actual != "" {
+		t.Fatalf("clipboard = %q, want empty", actual)
+	}
+}
+
+func TestClipXclip(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	t.Setenv("WAYLAND_DISPLAY", "")
+	t.Setenv("DISPLAY", ":0")
+	var clipped string
+	path := UseClipboardForTesting(t, func(path string, d time.Duration) {
+		clipped = readClipboardForTesting(t, path)
+	})
+	_, err := ctx.Database.Exec(`insert into passwords (machine, service, user, password, type) values('mymachine', 'http', 'myuser', 'JBSWY3DPEHPK3PXP', 'totp');`)
+	if err != nil {
+		t.Fatalf("db.Exec() = %q, want nil", err)
+	}
+	os.Args = []string{"", "search", "--totp", "-c", "--clip-timeout", "10", "mymachine"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	// The current TOTP code is copied, not the shared secret.
+	expectedClipped := "626953"
+	if clipped != expectedClipped {
+		t.Fatalf("clipped = %q, want %q", clipped, expectedClipped)
+	}
+	if actual := readClipboardForTesting(t, path); actual != "" {
+		t.Fatalf("clipboard = %q, want empty", actual)
+	}
+}
+
+func TestClipChanged(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	t.Setenv("WAYLAND_DISPLAY", "wayland-0")
+	path := UseClipboardForTesting(t, func(path string, d time.Duration) {
+		// Copy something else while waiting.
+		err := os.WriteFile(path, []byte("something else"), 0600)
+		if err != nil {
+			t.Fatalf("os.WriteFile() failed: %s", err)
+		}
+	})
+	_, err := ctx.Database.Exec(`insert into passwords (machine, service, user, password, type) values('mymachine', 'http', 'myuser', 'mypassword', 'plain');`)
+	if err != nil {
+		t.Fatalf("db.Exec() = %q, want nil", err)
+	}
+	os.Args = []string{"", "search", "--clip", "mymachine"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	expectedBuf := "Copied the password to the clipboard, clearing it in 45 seconds\nNot clearing the clipboard, its content changed in the meantime\n"
+	if outBuf.String() != expectedBuf {
+		t.Fatalf("Main() output is %q, want %q", outBuf.String(), expectedBuf)
+	}
+	if actual := readClipboardForTesting(t, path); actual != "something else" {
+		t.Fatalf("clipboard = %q, want %q", actual, "something else")
+	}
+}
+
+func TestClipOsc52(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	t.Setenv("WAYLAND_DISPLAY", "wayland-0")
+	t.Setenv("DISPLAY", ":0")
+	UseClipboardForTesting(t, func(path string, d time.Duration) {})
+	// Neither wl-copy, nor xclip is installed.
+	LookPath = func(file string) (string, error) {
+		return "", errors.New("not found")
+	}
+	_, err := ctx.Database.Exec(`insert into passwords (machine, service, user, password, type) values('mymachine', 'http', 'myuser', 'mypassword', 'plain');`)
+	if err != nil {
+		t.Fatalf("db.Exec() = %q, want nil", err)
+	}
+	os.Args = []string{"", "search", "--clip", "mymachine"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	expectedBuf := "\x1b]52;c;bXlwYXNzd29yZA==\x07Copied the password to the clipboard, clearing it in 45 seconds\n\x1b]52;c;!\x07Cleared the clipboard\n"
+	if outBuf.String() != expectedBuf {
+		t.Fatalf("Main() output is %q, want %q", outBuf.String(), expectedBuf)
+	}
+}
+
+func TestClipNoTimeout(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	t.Setenv("WAYLAND_DISPLAY", "")
+	t.Setenv("DISPLAY", "")
+	UseClipboardForTesting(t, func(path string, d time.Duration) {
+		t.Fatalf("Sleep() is not expected to be called")
+	})
+	_, err := ctx.Database.Exec(`insert into passwords (machine, service, user, password, type) values('mymachine', 'http', 'myuser', 'mypassword', 'plain');`)
+	if err != nil {
+		t.Fatalf("db.Exec() = %q, want nil", err)
+	}
+	os.Args = []string{"", "search", "--clip", "--clip-timeout", "0", "mymachine"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	expectedBuf := "\x1b]52;c;bXlwYXNzd29yZA==\x07Copied the password to the clipboard\n"
+	if outBuf.String() != expectedBuf {
+		t.Fatalf("Main() output is %q, want %q", outBuf.String(), expectedBuf)
+	}
+}
+
+// Search fails because --clip needs exactly one match.
+func TestClipMultiple(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	UseClipboardForTesting(t, func(path string, d time.Duration) {})
+	_, err := ctx.Database.Exec(`insert into passwords (machine, service, user, password, type) values('mymachine', 'http', 'myuser', 'mypassword', 'plain');
+	                             insert into passwords (machine, service, user, password, type) values('mymachine', 'http', 'otheruser', 'otherpassword', 'plain');`)
+	if err != nil {
+		t.Fatalf("db.Exec() = %q, want nil", err)
+	}
+	os.Args = []string{"", "search", "--clip", "mymachine"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 1
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q", actualRet, expectedRet)
+	}
+	// No password is printed.
+	if strings.Contains(outBuf.String(), "password:") {
+		t.Fatalf("Main() output is %q, want no passwords", outBuf.String())
+	}
+}
+
+// The database is migrated, then written back before waiting to clear the clipboard.
+func TestClipMigrated(t *testing.T) {
+	UseCommandForTesting(t)
+	oldRemove := Remove
+	Remove = RemoveForTesting
+	t.Cleanup(func() { Remove = oldRemove })
+	oldStat := Stat
+	Stat = StatForTesting
+	t.Cleanup(func() { Stat = oldStat })
+	t.Setenv("WAYLAND_DISPLAY", "")
+	t.Setenv("DISPLAY", "")
+	var vaultExists bool
+	UseClipboardForTesting(t, func(path string, d time.Duration) {
+		_, err := os.Stat("fixtures/passwords.db")
+		vaultExists = err == nil
+	})
+	os.Remove("fixtures/passwords.db")
+	t.Cleanup(func() { os.Remove("fixtures/passwords.db") })
+	db, err := sql.Open("sqlite3", "fixtures/passwords.db")
+	if err != nil {
+		t.Fatalf("sql.Open() failed: %s", err)
+	}
+	_, err = db.Exec(`create table passwords (
+				id integer primary key autoincrement,
+				machine text not null,
+				service text not null,
+				user text not null,
+				password text not null,
+				type text not null,
+				unique(machine, service, user, type)
+		);
+		insert into passwords (machine, service, user, password, type) values('mymachine', 'http', 'myuser', 'mypassword', 'plain');
+		pragma user_version = 1;`)
+	db.Close()
+	if err != nil {
+		t.Fatalf("db.Exec() = %q, want nil", err)
+	}
+	os.Args = []string{"", "search", "--clip", "mymachine"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	if !vaultExists {
+		t.Fatalf("the database was missing while waiting to clear the clipboard")
+	}
+	db, err = sql.Open("sqlite3", "fixtures/passwords.db")
+	if err != nil {
+		t.Fatalf("sql.Open() failed: %s", err)
+	}
+	defer db.Close()
+	var version int
+	var password string
+	err = db.QueryRow("select password, (select user_version from pragma_user_version) from passwords").Scan(&password, &version)
+	if err != nil {
+		t.Fatalf("db.QueryRow() = %q, want nil", err)
+	}
+	if password != "mypassword" || version != 6 {
+		t.Fatalf("password, version = %q, %v, want %q, 6", password, version, "mypassword")
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2026 Miklos Vajna
+//
+// SPDX-License-Identifier: MIT
+
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+)
+
+const (
+	xdgConfigHome = "XDG_CONFIG_HOME"
+)
+
+// config is the optional, unencrypted user configuration.
+type config struct {
+	// Policies maps a policy name to password generation rules.
+	Policies map[string]passwordPolicy
+	// MachinePolicies maps a machine to a policy name, used when generating passwords for that
+	// machine.
+	MachinePolicies map[string]string
+	// MinStrength is the minimal strength of user-supplied passwords, from 0 to 4, without a
+	// warning.
+	MinStrength int
+}
+
+func getConfigPath() (string, error) {
+	usr, err := user.Current()
+	if err != nil {
+		return "", fmt.Errorf("user.Current() failed: %s", err)
+	}
+	configDir := filepath.Join(usr.HomeDir, ".config", "cpm")
+	if a := os.Getenv(xdgConfigHome); a != "" {
+		configDir = filepath.Join(a, "cpm")
+	}
+
+	return configDir + "/config.json", nil
+}
+
+// loadConfig reads the user configuration, a missing configuration file is the same as an empty
+// one.
+func loadConfig() (config, error) {
+	c := config{MinStrength: defaultMinStrength}
+	configPath, err := getConfigPath()
+	if err != nil {
+		return c, fmt.Errorf("getConfigPath() failed: %s", err)
+	}
+
+	if !pathExists(configPath) {
+		return c, nil
+	}
+
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		return c, fmt.Errorf("os.ReadFile() failed: %s", err)
+	}
+
+	err = json.Unmarshal(content, &c)
+	if err != nil {
+		return c, fmt.Errorf("json.Unmarshal() failed: %s", err)
+	}
+
+	return c, nil
+}
@@ -0,0 +1,77 @@
+// Copyright 2026 Miklos Vajna
+//
+// SPDX-License-Identifier: MIT
+
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// UseConfigForTesting points XDG_CONFIG_HOME to a temporary directory and writes `content` as the
+// config there.
+func UseConfigForTesting(t *testing.T, content string) {
+	configHome := t.TempDir()
+	t.Setenv(xdgConfigHome, configHome)
+	err := os.MkdirAll(filepath.Join(configHome, "cpm"), 0700)
+	if err != nil {
+		t.Fatalf("os.MkdirAll() failed: %s", err)
+	}
+	err = os.WriteFile(filepath.Join(configHome, "cpm", "config.json"), []byte(content), 0600)
+	if err != nil {
+		t.Fatalf("os.WriteFile() failed: %s", err)
+	}
+}
+
+// TestGetConfigPath checks if getConfigPath() handles an empty and a custom XDG_CONFIG_HOME value.
+func TestGetConfigPath(t *testing.T) {
+	t.Setenv(xdgConfigHome, "")
+
+	actual, err := getConfigPath()
+	if err != nil {
+		t.Fatalf("getConfigPath() err = %q, want nil", err)
+	}
+
+	expectedSuffix := "/.config/cpm/config.json"
+	if !strings.HasSuffix(actual, expectedSuffix) {
+		t.Fatalf("getConfigPath() = %q, want suffix %q", actual, expectedSuffix)
+	}
+
+	t.Setenv(xdgConfigHome, "/tmp")
+
+	actual, err = getConfigPath()
+	if err != nil {
+		t.Fatalf("getConfigPath() err = %q, want nil", err)
+	}
+
+	expected := "/tmp/cpm/config.json"
+	if actual != expected {
+		t.Fatalf("getConfigPath() = %q, want %q", actual, expected)
+	}
+}
+
+func TestLoadConfigMissing(t *testing.T) {
+	t.Setenv(xdgConfigHome, t.TempDir())
+
+	actual, err := loadConfig()
+
+	if err != nil {
+		t.Fatalf("loadConfig() err = %q, want nil", err)
+	}
+	if len(actual.Policies) != 0 {
+		t.Fatalf("len(Policies) = %v, want 0", len(actual.Policies))
+	}
+}
+
+func TestLoadConfigBad(t *testing.T) {
+	UseConfigForTesting(t, `{"Policies": {"mypolicy": {"Length": "long"}}}`)
+
+	_, err := loadConfig()
+
+	if err == nil {
+		t.Fatalf("loadConfig() err = nil, want !nil")
+	}
+}
@@ -5,17 +5,26 @@
 package commands
 
 import (
+	"crypto/rand"
+	_ "embed" // for the embedded word list
 	"os"
 	"os/exec"
 	"time"
 
+	"github.com/gdamore/tcell/v2"
 	"github.com/mdp/qrterminal/v3"
+	"github.com/pquerna/otp/totp"
 	"github.com/sethvargo/go-password/password"
+	"golang.org/x/term"
 )
 
 // Command returns the Cmd struct to execute the named program
 var Command = exec.Command
 
+// LookPath searches for an executable named file in the directories named by the PATH environment
+// variable.
+var LookPath = exec.LookPath
+
 // Remove removes the named file or (empty) directory.
 var Remove = os.Remove
 
@@ -28,11 +37,34 @@ var GeneratePassword = password.Generate
 // GenerateQrCode creates a QR Code and writes it out to io.Writer.
 var GenerateQrCode = qrterminal.Generate
 
+// GenerateTotpKey is the package shortcut for totp.Generate.
+var GenerateTotpKey = totp.Generate
+
 // OpenDatabase opens the database before running a subcommand.
 var OpenDatabase = openDatabase
 
 // CloseDatabase opens the database before running a subcommand.
 var CloseDatabase = closeDatabase
 
+// RandInt returns a uniform random value in [0, max), from a cryptographically secure source.
+var RandInt = rand.Int
+
+// EffLargeWordlist is the EFF large word list for diceware passphrases.
+//
+//go:embed eff_large_wordlist.txt
+var EffLargeWordlist string
+
 // Now returns the current local time.
 var Now = time.Now
+
+// Sleep pauses the current goroutine for at least the duration d.
+var Sleep = time.Sleep
+
+// NewScreen returns a Screen for the terminal UI.
+var NewScreen = tcell.NewScreen
+
+// IsTerminal returns whether the given file descriptor is a terminal.
+var IsTerminal = term.IsTerminal
+
+// ReadPassword reads a line of input from a terminal without local echo.
+var ReadPassword = term.ReadPassword
@@ -10,23 +10,21 @@ import (
 	"strings"
 	"time"
 
+	"github.com/mdp/qrterminal/v3"
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
 	"github.com/spf13/cobra"
 )
 
 func generatePassword(secure bool) (string, error) {
-	numSymbols := 0
-	if secure {
-		numSymbols = 3
-	}
-	// Length of 15 and no symbols matches current Firefox and `pwgen --secure 15 1`.
-	output, err := GeneratePassword( /*length*/ 15 /*numDigits=*/, 3, numSymbols /*noUpper=*/, false /*allowRepeat=*/, false)
+	output, err := generatePolicyPassword(getDefaultPolicy(secure))
 	if err != nil {
-		return "", fmt.Errorf("password.Generate() failed: %s", err)
+		return "", fmt.Errorf("generatePolicyPassword() failed: %s", err)
 	}
-	return strings.TrimSpace(string(output)), nil
+	return output, nil
 }
 
-func createPassword(context *Context, machine, service, user, password string, passwordType PasswordType, secure bool) (string, error) {
+func createPassword(context *Context, machine, service, user, password string, passwordType PasswordType, secure bool, expires string, rotateEvery int, notes string) (string, error) {
 	if len(password) == 0 {
 		var err error
 		password, err = generatePassword(secure)
@@ -41,13 +39,13 @@ func createPassword(context *Context, machine, service, user, password string, p
 	}
 
 	defer transaction.Rollback()
-	query, err := transaction.Prepare("insert into passwords (machine, service, user, password, type, created, modified) values(?, ?, ?, ?, ?, ?, ?)")
+	query, err := transaction.Prepare("insert into passwords (machine, service, user, password, type, created, modified, expires, rotate_every, notes) values(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
 	if err != nil {
 		return "", fmt.Errorf("db.Prepare() failed: %s", err)
 	}
 
 	now := Now().Format(time.RFC3339)
-	result, err := query.Exec(machine, service, user, password, passwordType, now, now)
+	result, err := query.Exec(machine, service, user, password, passwordType, now, now, expires, rotateEvery, notes)
 	if err != nil {
 		return "", fmt.Errorf("query.Exec() failed: %s", err)
 	}
@@ -79,10 +77,43 @@ func newCreateCommand(ctx *Context) *cobra.Command {
 	var passwordType PasswordType = "plain"
 	var dryRun bool
 	var secure bool
+	var qrImage string
+	var generateSecret bool
+	var issuer string
+	var totpDigits int
+	var period uint
+	var policy policyFlags
+	var strength strengthFlags
+	var expires string
+	var rotateEvery int
+	var notes string
 	var cmd = &cobra.Command{
 		Use:   "create",
 		Short: "creates a new password",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if generateSecret && passwordType != PasswordTypeTotp {
+				return fmt.Errorf("--generate-secret requires --type totp")
+			}
+
+			if len(qrImage) > 0 {
+				if passwordType != PasswordTypeTotp {
+					return fmt.Errorf("--qr-image requires --type totp")
+				}
+
+				key, err := readQrImage(qrImage)
+				if err != nil {
+					return fmt.Errorf("readQrImage() failed: %s", err)
+				}
+
+				if len(machine) == 0 {
+					machine = key.Issuer()
+				}
+				if len(user) == 0 {
+					user = key.AccountName()
+				}
+				password = key.String()
+			}
+
 			reader := bufio.NewReader(cmd.InOrStdin())
 			if len(machine) == 0 {
 				fmt.Fprintf(cmd.OutOrStdout(), "Machine: ")
@@ -101,17 +132,94 @@ func newCreateCommand(ctx *Context) *cobra.Command {
 				user = strings.TrimSuffix(line, "\n")
 			}
 
+			if passwordType == PasswordTypeRecoveryCodes {
+				var err error
+				password, err = encodeRecoveryCodes(password)
+				if err != nil {
+					return fmt.Errorf("encodeRecoveryCodes() failed: %s", err)
+				}
+			}
+
+			var key *otp.Key
+			if generateSecret {
+				if totpDigits < 6 || totpDigits > 8 {
+					return fmt.Errorf("totp-digits must be between 6 and 8, got %d", totpDigits)
+				}
+
+				if len(issuer) == 0 {
+					issuer = machine
+				}
+				opts := totp.GenerateOpts{
+					Issuer:      issuer,
+					AccountName: user,
+					Digits:      otp.Digits(totpDigits),
+					Period:      period,
+				}
+				var err error
+				key, err = GenerateTotpKey(opts)
+				if err != nil {
+					return fmt.Errorf("GenerateTotpKey() failed: %s", err)
+				}
+				password = key.String()
+			}
+
+			if cmd.Flags().Changed("password") && passwordType == PasswordTypePlain {
+				err := checkPasswordStrength(cmd, strength, password, machine, user)
+				if err != nil {
+					return fmt.Errorf("checkPasswordStrength() failed: %s", err)
+				}
+			}
+
+			generated := false
+			var entropy float64
+			if len(password) == 0 {
+				var err error
+				password, entropy, err = generateFlagsPassword(cmd, policy, machine, secure)
+				if err != nil {
+					return fmt.Errorf("generateFlagsPassword() failed: %s", err)
+				}
+				generated = true
+			}
+
+			if rotateEvery < 0 {
+				return fmt.Errorf("rotate-every can't be negative")
+			}
+
+			expiryDate, err := getExpires(expires, rotateEvery)
+			if err != nil {
+				return fmt.Errorf("getExpires() failed: %s", err)
+			}
+
 			ctx.DryRun = dryRun
 			writer := cmd.OutOrStdout()
 			ctx.OutOrStdout = &writer
 			defer func() { ctx.OutOrStdout = nil }()
-			generatedPassword, err := createPassword(ctx, machine, service, user, password, passwordType, secure)
+			_, err = createPassword(ctx, machine, service, user, password, passwordType, secure, expiryDate, rotateEvery, notes)
 			if err != nil {
 				return fmt.Errorf("createPassword() failed: %s", err)
 			}
 
-			if generatedPassword != password {
-				fmt.Fprintf(cmd.OutOrStdout(), "Generated password: %s\n", generatedPassword)
+			if generated {
+				fmt.Fprintf(cmd.OutOrStdout(), "Generated password: %s\n", password)
+				if entropy > 0 {
+					fmt.Fprintf(cmd.OutOrStdout(), "Entropy: %.1f bits\n", entropy)
+				}
+			}
+
+			if key != nil {
+				// Allow verifying the enrollment right away.
+				fmt.Fprintf(cmd.OutOrStdout(), "Generated TOTP shared secret: %s\n", key.String())
+				GenerateQrCode(key.String(), qrterminal.L, cmd.OutOrStdout())
+				opts := totp.ValidateOpts{
+					Period:    uint(key.Period()),
+					Digits:    key.Digits(),
+					Algorithm: key.Algorithm(),
+				}
+				code, err := totp.GenerateCodeCustom(key.Secret(), Now(), opts)
+				if err != nil {
+					return fmt.Errorf("totp.GenerateCodeCustom() failed: %s", err)
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "Current TOTP code: %s\n", code)
 			}
 			return nil
 		},
@@ -120,9 +228,20 @@ func newCreateCommand(ctx *Context) *cobra.Command {
 	cmd.Flags().StringVarP(&service, "service", "s", "http", "service")
 	cmd.Flags().StringVarP(&user, "user", "u", "", "user (default: ask)")
 	cmd.Flags().StringVarP(&password, "password", "p", "", "password (default: generate)")
-	cmd.Flags().VarP(&passwordType, "type", "t", `password type ("plain" or "totp")`)
+	cmd.Flags().VarP(&passwordType, "type", "t", `password type ("plain", "totp" or "recovery-codes")`)
 	cmd.Flags().BoolVarP(&dryRun, "dry-run", "n", false, `do everything except actually perform the database action (default: false)`)
 	cmd.Flags().BoolVarP(&secure, "secure", "y", false, `increase number of symbols from 0 to 3 (default: false)`)
+	cmd.Flags().StringVarP(&qrImage, "qr-image", "", "", `PNG or JPEG image of a TOTP QR code, decoded locally (default: "")`)
+	cmd.Flags().BoolVarP(&generateSecret, "generate-secret", "", false, `generate a new TOTP shared secret, for services you operate (default: false)`)
+	cmd.Flags().StringVarP(&issuer, "issuer", "", "", `issuer of the generated TOTP shared secret (default: machine)`)
+	cmd.Flags().UintVarP(&period, "period", "", 30, `number of seconds a code of the generated TOTP shared secret is valid for`)
+	cmd.Flags().IntVarP(&totpDigits, "totp-digits", "", 6, `number of digits in the codes of the generated TOTP shared secret (6, 7 or 8)`)
+	addPolicyFlags(cmd, &policy)
+	addStrengthFlags(cmd, &strength)
+	cmd.Flags().StringVarP(&expires, "expires", "", "", `expiry date of the password, as YYYY-MM-DD (default: never, or from --rotate-every)`)
+	cmd.Flags().IntVarP(&rotateEvery, "rotate-every", "", 0, `rotation interval of the password in days, the password expires this many days after each change (default: 0, no rotation)`)
+	cmd.Flags().StringVarP(&notes, "notes", "", "", `free-form notes about the password (default: "")`)
+	cmd.MarkFlagsMutuallyExclusive("password", "qr-image", "generate-secret", "passphrase")
 
 	return cmd
 }
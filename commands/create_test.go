@@ -10,6 +10,9 @@ import (
 	"os"
 	"strings"
 	"testing"
+
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
 )
 
 func TestInsert(t *testing.T) {
@@ -116,6 +119,11 @@ func GeneratePasswordForTesting(length, numDigits, numSymbols int, noUpper, allo
 	return "output-from-pwgen", nil
 }
 
+func GenerateTotpKeyForTesting(opts totp.GenerateOpts) (*otp.Key, error) {
+	opts.Secret = []byte("12345678901234567890")
+	return totp.Generate(opts)
+}
+
 func TestGeneratePasswordSecure(t *testing.T) {
 	CreateContextForTesting(t)
 	actualPassword, err := generatePassword( /*secure=*/ true)
@@ -291,3 +299,137 @@ func TestDryRunInsert(t *testing.T) {
 		t.Fatalf("actualLength = %q, want %q", actualLength, expectedLength)
 	}
 }
+
+func TestInsertGenerateSecret(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	OldGenerateQrCode := GenerateQrCode
+	GenerateQrCode = GenerateQrCodeForTesting
+	defer func() { GenerateQrCode = OldGenerateQrCode }()
+	os.Args = []string{"", "create", "-m", "mymachine", "-u", "myuser", "-t", "totp", "--generate-secret", "--issuer", "Myservice", "--totp-digits", "8", "--period", "60"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	expectedURL := "otpauth://totp/Myservice:myuser?algorithm=SHA1&digits=8&issuer=Myservice&period=60&secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	expectedBuf := "Created 1 password\n"
+	expectedBuf += "Generated TOTP shared secret: " + expectedURL + "\n"
+	expectedBuf += "qrcode-output"
+	// TOTP code depends on the 2020 time produced by NowForTesting()
+	expectedBuf += "Current TOTP code: 63201738\n"
+	if outBuf.String() != expectedBuf {
+		t.Fatalf("Main() output is %q, want %q", outBuf.String(), expectedBuf)
+	}
+	opts := searchOptions{}
+	opts.noid = true
+	results, err := readPasswords(ctx.Database, opts)
+	if err != nil {
+		t.Fatalf("readPasswords() err = %q, want nil", err)
+	}
+	expected := "machine: mymachine, service: http, user: myuser, password type: TOTP shared secret, password: " + expectedURL
+	if !ContainsString(results, expected) {
+		t.Fatalf("results = %q, want to contain %q", results, expected)
+	}
+}
+
+func TestInsertGenerateSecretDefaultIssuer(t *testing.T) {
+	CreateContextForTesting(t)
+	OldGenerateQrCode := GenerateQrCode
+	GenerateQrCode = GenerateQrCodeForTesting
+	defer func() { GenerateQrCode = OldGenerateQrCode }()
+	os.Args = []string{"", "create", "-m", "mymachine", "-u", "myuser", "-t", "totp", "--generate-secret"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	expectedPrefix := "Created 1 password\nGenerated TOTP shared secret: otpauth://totp/mymachine:myuser?algorithm=SHA1&digits=6&issuer=mymachine&period=30&secret="
+	if !strings.HasPrefix(outBuf.String(), expectedPrefix) {
+		t.Fatalf("Main() output is %q, want prefix %q", outBuf.String(), expectedPrefix)
+	}
+}
+
+// Insert fails because --generate-secret is only valid for TOTP shared secrets.
+func TestInsertGenerateSecretPlain(t *testing.T) {
+	CreateContextForTesting(t)
+	os.Args = []string{"", "create", "-m", "mymachine", "-u", "myuser", "--generate-secret"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 1
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q", actualRet, expectedRet)
+	}
+}
+
+// Insert fails because TOTP codes have 6 to 8 digits.
+func TestInsertGenerateSecretBadDigits(t *testing.T) {
+	for _, digits := range []string{"5", "9"} {
+		ctx := CreateContextForTesting(t)
+		os.Args = []string{"", "create", "-m", "mymachine", "-u", "myuser", "-t", "totp", "--generate-secret", "--totp-digits", digits}
+		inBuf := new(bytes.Buffer)
+		outBuf := new(bytes.Buffer)
+
+		actualRet := Main(inBuf, outBuf)
+
+		expectedRet := 1
+		if actualRet != expectedRet {
+			t.Fatalf("Main() = %q, want %q, digits are %s", actualRet, expectedRet, digits)
+		}
+		results, err := readPasswords(ctx.Database, searchOptions{})
+		if err != nil {
+			t.Fatalf("readPasswords() err = %q, want nil", err)
+		}
+		if len(results) != 0 {
+			t.Fatalf("results = %q, want none", results)
+		}
+	}
+}
+
+func TestInsertNotes(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	os.Args = []string{"", "create", "-m", "mymachine", "-u", "myuser", "-p", "mypassword", "--notes", "mynotes"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	var notes string
+	err := ctx.Database.QueryRow("select notes from passwords where id=1").Scan(&notes)
+	if err != nil {
+		t.Fatalf("row.Scan() failed: %s", err)
+	}
+	expectedNotes := "mynotes"
+	if notes != expectedNotes {
+		t.Fatalf("notes = %q, want %q", notes, expectedNotes)
+	}
+}
+
+// createPassword() generates a password if none is specified.
+func TestCreatePasswordGenerate(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+
+	actualPassword, err := createPassword(&ctx, "mymachine", "myservice", "myuser", "", PasswordTypePlain /*secure=*/, true, "", 0, "")
+
+	if err != nil {
+		t.Fatalf("createPassword() err = %q, want nil", err)
+	}
+	expectedPassword := "0utput-from-pwgen"
+	if actualPassword != expectedPassword {
+		t.Fatalf("actualPassword = %q, want %q", actualPassword, expectedPassword)
+	}
+}
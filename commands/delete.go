@@ -6,12 +6,44 @@ package commands
 
 import (
 	"bufio"
+	"database/sql"
 	"fmt"
 	"strings"
 
 	"github.com/spf13/cobra"
 )
 
+// deletePassword deletes the password with `id` in `transaction`, together with its history.
+func deletePassword(transaction *sql.Tx, id string) (int64, error) {
+	query, err := transaction.Prepare("delete from passwords where id=?")
+	if err != nil {
+		return 0, fmt.Errorf("db.Prepare() failed: %s", err)
+	}
+
+	result, err := query.Exec(id)
+	if err != nil {
+		return 0, fmt.Errorf("db.Exec() failed: %s", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("result.RowsAffected() failed: %s", err)
+	}
+
+	// Don't keep the old values of a deleted password around.
+	query, err = transaction.Prepare("delete from history where password_id=?")
+	if err != nil {
+		return 0, fmt.Errorf("db.Prepare() failed: %s", err)
+	}
+
+	_, err = query.Exec(id)
+	if err != nil {
+		return 0, fmt.Errorf("db.Exec() failed: %s", err)
+	}
+
+	return affected, nil
+}
+
 func newDeleteCommand(ctx *Context) *cobra.Command {
 	var dryRun bool
 	var id string
@@ -26,7 +58,6 @@ func newDeleteCommand(ctx *Context) *cobra.Command {
 
 			defer transaction.Rollback()
 
-			var affected int64
 			if len(id) == 0 {
 				fmt.Fprintf(cmd.OutOrStdout(), "Id: ")
 				reader := bufio.NewReader(cmd.InOrStdin())
@@ -36,20 +67,11 @@ func newDeleteCommand(ctx *Context) *cobra.Command {
 				}
 				id = strings.TrimSuffix(line, "\n")
 			}
-			query, err := transaction.Prepare("delete from passwords where id=?")
-			if err != nil {
-				return fmt.Errorf("db.Prepare() failed: %s", err)
-			}
-
-			result, err := query.Exec(id)
+			affected, err := deletePassword(transaction, id)
 			if err != nil {
-				return fmt.Errorf("db.Exec() failed: %s", err)
+				return fmt.Errorf("deletePassword() failed: %s", err)
 			}
 
-			affected, err = result.RowsAffected()
-			if err != nil {
-				return fmt.Errorf("result.RowsAffected() failed: %s", err)
-			}
 			if dryRun {
 				fmt.Fprintf(cmd.OutOrStdout(), "Would delete %v password\n", affected)
 				ctx.NoWriteBack = true
@@ -18,7 +18,7 @@ func TestDelete(t *testing.T) {
 	expectedPassword := "mypassword"
 	var expectedType PasswordType = "plain"
 	secure := false
-	_, err := createPassword(&ctx, expectedMachine, expectedService, expectedUser, expectedPassword, expectedType, secure)
+	_, err := createPassword(&ctx, expectedMachine, expectedService, expectedUser, expectedPassword, expectedType, secure, "", 0, "")
 	if err != nil {
 		t.Fatalf("createPassword() = %q, want nil", err)
 	}
@@ -51,7 +51,7 @@ func TestInteractiveDelete(t *testing.T) {
 	expectedPassword := "mypassword"
 	var expectedType PasswordType = "plain"
 	secure := false
-	_, err := createPassword(&ctx, expectedMachine, expectedService, expectedUser, expectedPassword, expectedType, secure)
+	_, err := createPassword(&ctx, expectedMachine, expectedService, expectedUser, expectedPassword, expectedType, secure, "", 0, "")
 	if err != nil {
 		t.Fatalf("createPassword() = %q, want nil", err)
 	}
@@ -90,7 +90,7 @@ func TestDryRunDelete(t *testing.T) {
 	expectedPassword := "mypassword"
 	var expectedType PasswordType = "plain"
 	secure := false
-	_, err := createPassword(&ctx, expectedMachine, expectedService, expectedUser, expectedPassword, expectedType, secure)
+	_, err := createPassword(&ctx, expectedMachine, expectedService, expectedUser, expectedPassword, expectedType, secure, "", 0, "")
 	if err != nil {
 		t.Fatalf("createPassword() = %q, want nil", err)
 	}
@@ -115,3 +115,27 @@ func TestDryRunDelete(t *testing.T) {
 		t.Fatalf("actualLength = %q, want %q", actualLength, expectedLength)
 	}
 }
+
+func TestDeleteHistory(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	_, err := ctx.Database.Exec(`insert into passwords (machine, service, user, password, type) values('mymachine', 'http', 'myuser', 'newpassword', 'plain');
+	                             insert into history (password_id, password, type, replaced) values(1, 'oldpassword', 'plain', '2020-05-01T00:00:00+02:00');`)
+	if err != nil {
+		t.Fatalf("db.Exec() = %q, want nil", err)
+	}
+	os.Args = []string{"", "delete", "-i", "1"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	// The old values are deleted as well.
+	history := getHistoryForTesting(t, ctx.Database, 1)
+	if len(history) != 0 {
+		t.Fatalf("history = %q, want empty", history)
+	}
+}
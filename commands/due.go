@@ -0,0 +1,122 @@
+// Copyright 2026 Miklos Vajna
+//
+// SPDX-License-Identifier: MIT
+
+package commands
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// parseExpiryDate parses a YYYY-MM-DD date, an empty date means no expiry.
+func parseExpiryDate(date string) (string, error) {
+	if len(date) == 0 {
+		return "", nil
+	}
+
+	t, err := time.ParseInLocation("2006-01-02", date, Now().Location())
+	if err != nil {
+		return "", fmt.Errorf("time.ParseInLocation() failed: %s", err)
+	}
+
+	return t.Format(time.RFC3339), nil
+}
+
+// getExpires decides the expiry of a new password: an explicit date wins, otherwise the rotation
+// interval starts now.
+func getExpires(date string, rotateEvery int) (string, error) {
+	if len(date) == 0 && rotateEvery > 0 {
+		return Now().AddDate(0, 0, rotateEvery).Format(time.RFC3339), nil
+	}
+
+	expires, err := parseExpiryDate(date)
+	if err != nil {
+		return "", fmt.Errorf("parseExpiryDate() failed: %s", err)
+	}
+
+	return expires, nil
+}
+
+// getDuePasswords lists the non-archived passwords which are expired or expire within `within`
+// days, soonest first.
+func getDuePasswords(db *sql.DB, within int) ([]string, error) {
+	var results []string
+	rows, err := db.Query("select id, machine, service, user, type, expires from passwords where archived = 0 and expires != '' order by expires, id")
+	if err != nil {
+		return nil, fmt.Errorf("db.Query(select) failed: %s", err)
+	}
+
+	defer rows.Close()
+	now := Now()
+	limit := now.AddDate(0, 0, within)
+	for rows.Next() {
+		var id int
+		var machine string
+		var service string
+		var user string
+		var passwordType string
+		var expires string
+		err = rows.Scan(&id, &machine, &service, &user, &passwordType, &expires)
+		if err != nil {
+			return nil, fmt.Errorf("rows.Scan() failed: %s", err)
+		}
+
+		t, err := time.Parse(time.RFC3339, expires)
+		if err != nil {
+			return nil, fmt.Errorf("time.Parse() failed: %s", err)
+		}
+
+		if t.After(limit) {
+			continue
+		}
+
+		// Count calendar days in local time, rounding as a day is not always 24 hours long.
+		t = t.In(now.Location())
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, now.Location())
+		days := int(math.Round(day.Sub(today).Hours() / 24))
+		var status string
+		switch {
+		case days > 0:
+			status = fmt.Sprintf("expires in %d days", days)
+		case days < 0:
+			status = fmt.Sprintf("expired %d days ago", -days)
+		case t.After(now):
+			status = "expires today"
+		default:
+			status = "expired today"
+		}
+		results = append(results, fmt.Sprintf("id: %8d, machine: %s, service: %s, user: %s, password type: %s, expires: %s (%s)", id, machine, service, user, passwordType, t.Format("2006-01-02"), status))
+	}
+
+	return results, nil
+}
+
+func newDueCommand(ctx *Context) *cobra.Command {
+	var within int
+	var cmd = &cobra.Command{
+		Use:   "due",
+		Short: "lists passwords which are expired or expire soon",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			results, err := getDuePasswords(ctx.Database, within)
+			if err != nil {
+				return fmt.Errorf("getDuePasswords() failed: %s", err)
+			}
+
+			for _, result := range results {
+				fmt.Fprintf(cmd.OutOrStdout(), "%s\n", result)
+			}
+
+			ctx.NoWriteBack = true
+			return nil
+		},
+	}
+	cmd.Flags().IntVarP(&within, "within", "w", 14, `also list passwords which expire within this many days`)
+
+	return cmd
+}
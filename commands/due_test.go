@@ -0,0 +1,316 @@
+// Copyright 2026 Miklos Vajna
+//
+// SPDX-License-Identifier: MIT
+
+package commands
+
+import (
+	"bytes"
+	"database/sql"
+	"os"
+	"testing"
+	"time"
+)
+
+// getExpiresForTesting returns the expires and rotate_every columns of the password with `id`.
+func getExpiresForTesting(t *testing.T, db *sql.DB, id int) (string, int) {
+	var expires string
+	var rotateEvery int
+	err := db.QueryRow("select expires, rotate_every from passwords where id=?", id).Scan(&expires, &rotateEvery)
+	if err != nil {
+		t.Fatalf("row.Scan() failed: %s", err)
+	}
+	return expires, rotateEvery
+}
+
+func TestCreateExpires(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	os.Args = []string{"", "create", "-m", "mymachine", "-u", "myuser", "-p", "7U1FvIzubR95Itg", "--expires", "2020-06-01"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	results, err := readPasswords(ctx.Database, searchOptions{noid: true, verbose: true})
+	if err != nil {
+		t.Fatalf("readPasswords() err = %q, want nil", err)
+	}
+	expected := "machine: mymachine, service: http, user: myuser, password type: plain, password: 7U1FvIzubR95Itg, archived: false, created: 2020-05-10 00:00, modified: 2020-05-10 00:00, expires: 2020-06-01 00:00, strength: 4/4"
+	if !ContainsString(results, expected) {
+		t.Fatalf("results = %q, want to contain %q", results, expected)
+	}
+}
+
+func TestCreateRotateEvery(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	os.Args = []string{"", "create", "-m", "mymachine", "-u", "myuser", "-p", "7U1FvIzubR95Itg", "--rotate-every", "90"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	expires, rotateEvery := getExpiresForTesting(t, ctx.Database, 1)
+	if expires != "2020-08-08T00:00:00+02:00" || rotateEvery != 90 {
+		t.Fatalf("expires = %q, rotateEvery = %v", expires, rotateEvery)
+	}
+}
+
+// Create fails because the expiry date is not in the YYYY-MM-DD format.
+func TestCreateBadExpires(t *testing.T) {
+	CreateContextForTesting(t)
+	os.Args = []string{"", "create", "-m", "mymachine", "-u", "myuser", "-p", "7U1FvIzubR95Itg", "--expires", "06/01/2020"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 1
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q", actualRet, expectedRet)
+	}
+}
+
+func TestUpdateExpires(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	_, err := createPassword(&ctx, "mymachine", "myservice", "myuser", "mypassword", PasswordTypePlain, false, "", 0, "")
+	if err != nil {
+		t.Fatalf("createPassword() = %q, want nil", err)
+	}
+	os.Args = []string{"", "update", "-i", "1", "--expires", "2021-01-01"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	expectedBuf := "Updated 1 password\n"
+	if outBuf.String() != expectedBuf {
+		t.Fatalf("Main() output is %q, want %q", outBuf.String(), expectedBuf)
+	}
+	expires, _ := getExpiresForTesting(t, ctx.Database, 1)
+	if expires != "2021-01-01T00:00:00+02:00" {
+		t.Fatalf("expires = %q, want 2021-01-01T00:00:00+02:00", expires)
+	}
+
+	// An empty date clears the expiry.
+	os.Args = []string{"", "update", "-i", "1", "--expires", ""}
+	actualRet = Main(inBuf, outBuf)
+
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	expires, _ = getExpiresForTesting(t, ctx.Database, 1)
+	if expires != "" {
+		t.Fatalf("expires = %q, want empty", expires)
+	}
+}
+
+// Update fails because the expiry date is not in the YYYY-MM-DD format.
+func TestUpdateBadExpires(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	_, err := createPassword(&ctx, "mymachine", "myservice", "myuser", "mypassword", PasswordTypePlain, false, "", 0, "")
+	if err != nil {
+		t.Fatalf("createPassword() = %q, want nil", err)
+	}
+	os.Args = []string{"", "update", "-i", "1", "--expires", "tomorrow"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 1
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q", actualRet, expectedRet)
+	}
+}
+
+func TestUpdateRotateEvery(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	_, err := createPassword(&ctx, "mymachine", "myservice", "myuser", "mypassword", PasswordTypePlain, false, "", 0, "")
+	if err != nil {
+		t.Fatalf("createPassword() = %q, want nil", err)
+	}
+	os.Args = []string{"", "update", "-i", "1", "--rotate-every", "30"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	expires, rotateEvery := getExpiresForTesting(t, ctx.Database, 1)
+	if expires != "2020-06-09T00:00:00+02:00" || rotateEvery != 30 {
+		t.Fatalf("expires = %q, rotateEvery = %v", expires, rotateEvery)
+	}
+
+	// A new password restarts the rotation.
+	_, err = ctx.Database.Exec("update passwords set expires = '2020-05-01T00:00:00+02:00' where id = 1")
+	if err != nil {
+		t.Fatalf("db.Exec() = %q, want nil", err)
+	}
+	os.Args = []string{"", "update", "-i", "1", "-p", "7U1FvIzubR95Itg"}
+	actualRet = Main(inBuf, outBuf)
+
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	expires, _ = getExpiresForTesting(t, ctx.Database, 1)
+	if expires != "2020-06-09T00:00:00+02:00" {
+		t.Fatalf("expires = %q, want 2020-06-09T00:00:00+02:00", expires)
+	}
+
+	// Disabling the rotation clears the expiry date.
+	os.Args = []string{"", "update", "-i", "1", "--rotate-every", "0"}
+	actualRet = Main(inBuf, outBuf)
+
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	expires, rotateEvery = getExpiresForTesting(t, ctx.Database, 1)
+	if expires != "" || rotateEvery != 0 {
+		t.Fatalf("expires = %q, rotateEvery = %v", expires, rotateEvery)
+	}
+}
+
+// Create and update fail because the rotation interval is negative.
+func TestBadRotateEvery(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	_, err := createPassword(&ctx, "mymachine", "myservice", "myuser", "mypassword", PasswordTypePlain, false, "", 0, "")
+	if err != nil {
+		t.Fatalf("createPassword() = %q, want nil", err)
+	}
+	for _, args := range [][]string{
+		{"", "create", "-m", "othermachine", "-u", "myuser", "-p", "7U1FvIzubR95Itg", "--rotate-every", "-1"},
+		{"", "update", "-i", "1", "--rotate-every", "-1"},
+	} {
+		os.Args = args
+		inBuf := new(bytes.Buffer)
+		outBuf := new(bytes.Buffer)
+
+		actualRet := Main(inBuf, outBuf)
+
+		expectedRet := 1
+		if actualRet != expectedRet {
+			t.Fatalf("Main() = %q, want %q, args are %q", actualRet, expectedRet, args)
+		}
+	}
+	expires, rotateEvery := getExpiresForTesting(t, ctx.Database, 1)
+	if expires != "" || rotateEvery != 0 {
+		t.Fatalf("expires = %q, rotateEvery = %v", expires, rotateEvery)
+	}
+	results, err := readPasswords(ctx.Database, searchOptions{})
+	if err != nil {
+		t.Fatalf("readPasswords() err = %q, want nil", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("results = %q, want only the original password", results)
+	}
+}
+
+func TestDue(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	_, err := ctx.Database.Exec(`insert into passwords (machine, service, user, password, type, expires) values('soonmachine', 'http', 'myuser', 'mypassword', 'plain', '2020-05-20T00:00:00+02:00');
+	                             insert into passwords (machine, service, user, password, type, expires) values('expiredmachine', 'http', 'myuser', 'mypassword', 'plain', '2020-05-07T00:00:00+02:00');
+	                             insert into passwords (machine, service, user, password, type, expires) values('latermachine', 'http', 'myuser', 'mypassword', 'plain', '2020-08-01T00:00:00+02:00');
+	                             insert into passwords (machine, service, user, password, type, expires, archived) values('archivedmachine', 'http', 'myuser', 'mypassword', 'plain', '2020-05-01T00:00:00+02:00', 1);
+	                             insert into passwords (machine, service, user, password, type) values('nevermachine', 'http', 'myuser', 'mypassword', 'plain');`)
+	if err != nil {
+		t.Fatalf("db.Exec() = %q, want nil", err)
+	}
+	os.Args = []string{"", "due"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	// Soonest first, passwords expiring after the window are not listed.
+	expectedBuf := "id:        2, machine: expiredmachine, service: http, user: myuser, password type: plain, expires: 2020-05-07 (expired 3 days ago)\n" +
+		"id:        1, machine: soonmachine, service: http, user: myuser, password type: plain, expires: 2020-05-20 (expires in 10 days)\n"
+	if outBuf.String() != expectedBuf {
+		t.Fatalf("Main() output is %q, want %q", outBuf.String(), expectedBuf)
+	}
+
+	os.Args = []string{"", "due", "--within", "0"}
+	outBuf = new(bytes.Buffer)
+	actualRet = Main(inBuf, outBuf)
+
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	expectedBuf = "id:        2, machine: expiredmachine, service: http, user: myuser, password type: plain, expires: 2020-05-07 (expired 3 days ago)\n"
+	if outBuf.String() != expectedBuf {
+		t.Fatalf("Main() output is %q, want %q", outBuf.String(), expectedBuf)
+	}
+}
+
+// Days are counted in calendar days, also for expiry dates less than a day away.
+func TestDueCalendarDays(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	Now = func() time.Time { return NowForTesting().Add(18 * time.Hour) }
+	_, err := ctx.Database.Exec(`insert into passwords (machine, service, user, password, type, expires) values('yesterday', 'http', 'myuser', 'mypassword', 'plain', '2020-05-09T20:00:00+02:00');
+	                             insert into passwords (machine, service, user, password, type, expires) values('earlier', 'http', 'myuser', 'mypassword', 'plain', '2020-05-10T06:00:00+02:00');
+	                             insert into passwords (machine, service, user, password, type, expires) values('later', 'http', 'myuser', 'mypassword', 'plain', '2020-05-10T20:00:00+02:00');
+	                             insert into passwords (machine, service, user, password, type, expires) values('tomorrow', 'http', 'myuser', 'mypassword', 'plain', '2020-05-11T06:00:00+02:00');`)
+	if err != nil {
+		t.Fatalf("db.Exec() = %q, want nil", err)
+	}
+	os.Args = []string{"", "due"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	expectedBuf := "id:        1, machine: yesterday, service: http, user: myuser, password type: plain, expires: 2020-05-09 (expired 1 days ago)\n" +
+		"id:        2, machine: earlier, service: http, user: myuser, password type: plain, expires: 2020-05-10 (expired today)\n" +
+		"id:        3, machine: later, service: http, user: myuser, password type: plain, expires: 2020-05-10 (expires today)\n" +
+		"id:        4, machine: tomorrow, service: http, user: myuser, password type: plain, expires: 2020-05-11 (expires in 1 days)\n"
+	if outBuf.String() != expectedBuf {
+		t.Fatalf("Main() output is %q, want %q", outBuf.String(), expectedBuf)
+	}
+}
+
+func TestSelectExpired(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	_, err := ctx.Database.Exec(`insert into passwords (machine, service, user, password, type, expires) values('mymachine', 'http', 'myuser', 'mypassword', 'plain', '2020-05-07T00:00:00+02:00');
+	                             insert into passwords (machine, service, user, password, type, expires) values('mymachine', 'http', 'otheruser', 'mypassword', 'plain', '2020-05-20T00:00:00+02:00');`)
+	if err != nil {
+		t.Fatalf("db.Exec() = %q, want nil", err)
+	}
+	os.Args = []string{"", "search", "--noid", "-m", "mymachine"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	// Only the expired password is marked.
+	expectedBuf := "machine: mymachine, service: http, user: myuser, password type: plain, password: mypassword, warning: expired on 2020-05-07\n" +
+		"machine: mymachine, service: http, user: otheruser, password type: plain, password: mypassword\n"
+	if outBuf.String() != expectedBuf {
+		t.Fatalf("Main() output is %q, want %q", outBuf.String(), expectedBuf)
+	}
+}
@@ -0,0 +1,328 @@
+// Copyright 2026 Miklos Vajna
+//
+// SPDX-License-Identifier: MIT
+
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go.yaml.in/yaml/v3"
+)
+
+// editHeader is written before the YAML document to explain the fields.
+const editHeader = `# Edit the password and save the file, unchanged fields are kept as-is.
+# type: "plain", "totp" or "recovery-codes"
+# password: "-" generates a new one, recovery codes are separated by spaces
+# expires: YYYY-MM-DD or "", rotate_every: days, 0 disables it
+# notes: free-form text, use "|" for multiple lines
+`
+
+// editDocument is a password, as shown in the editor.
+type editDocument struct {
+	Machine     string       `yaml:"machine"`
+	Service     string       `yaml:"service"`
+	User        string       `yaml:"user"`
+	Type        PasswordType `yaml:"type"`
+	Password    string       `yaml:"password"`
+	Archived    bool         `yaml:"archived"`
+	Expires     string       `yaml:"expires"`
+	RotateEvery int          `yaml:"rotate_every"`
+	Notes       string       `yaml:"notes"`
+}
+
+// readEditDocument reads the password with `id` for editing.
+func readEditDocument(transaction *sql.Tx, id string) (editDocument, error) {
+	var doc editDocument
+	var expires string
+	row := transaction.QueryRow("select machine, service, user, type, password, archived, expires, rotate_every, notes from passwords where id=?", id)
+	err := row.Scan(&doc.Machine, &doc.Service, &doc.User, &doc.Type, &doc.Password, &doc.Archived, &expires, &doc.RotateEvery, &doc.Notes)
+	if err == sql.ErrNoRows {
+		return doc, fmt.Errorf("no password with id '%s'", id)
+	}
+	if err != nil {
+		return doc, fmt.Errorf("row.Scan() failed: %s", err)
+	}
+
+	if doc.Type == PasswordTypeRecoveryCodes {
+		codes, err := decodeRecoveryCodes(doc.Password)
+		if err != nil {
+			return doc, fmt.Errorf("decodeRecoveryCodes() failed: %s", err)
+		}
+
+		doc.Password = strings.Join(getUnusedRecoveryCodes(codes), " ")
+	}
+
+	if len(expires) > 0 {
+		t, err := time.Parse(time.RFC3339, expires)
+		if err != nil {
+			return doc, fmt.Errorf("time.Parse() failed: %s", err)
+		}
+
+		doc.Expires = t.In(Now().Location()).Format("2006-01-02")
+	}
+
+	return doc, nil
+}
+
+// formatEditDocument returns the YAML form of `doc`, with comments explaining the fields.
+func formatEditDocument(doc editDocument) ([]byte, error) {
+	buf, err := yaml.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("yaml.Marshal() failed: %s", err)
+	}
+
+	return append([]byte(editHeader), buf...), nil
+}
+
+// parseEditDocument parses and validates the YAML form of a password.
+func parseEditDocument(content []byte) (editDocument, error) {
+	var doc editDocument
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	// Catch typos in the field names.
+	decoder.KnownFields(true)
+	err := decoder.Decode(&doc)
+	if err != nil {
+		return doc, fmt.Errorf("decoder.Decode() failed: %s", err)
+	}
+
+	for _, field := range []struct{ name, value string }{{"machine", doc.Machine}, {"service", doc.Service}, {"user", doc.User}, {"password", doc.Password}} {
+		if len(field.value) == 0 {
+			return doc, fmt.Errorf("%s can't be empty", field.name)
+		}
+	}
+
+	var passwordType PasswordType
+	err = passwordType.Set(string(doc.Type))
+	if err != nil {
+		return doc, fmt.Errorf("invalid type: %s", err)
+	}
+
+	_, err = parseExpiryDate(doc.Expires)
+	if err != nil {
+		return doc, fmt.Errorf("parseExpiryDate() failed: %s", err)
+	}
+
+	if doc.RotateEvery < 0 {
+		return doc, fmt.Errorf("rotate_every can't be negative")
+	}
+
+	return doc, nil
+}
+
+// getEditUpdate returns the update which turns `old` into `doc`, and if there is anything to update.
+func getEditUpdate(old, doc editDocument) (passwordUpdate, bool) {
+	var update passwordUpdate
+	if doc.Machine != old.Machine {
+		update.machine = doc.Machine
+	}
+	if doc.Service != old.Service {
+		update.service = doc.Service
+	}
+	if doc.User != old.User {
+		update.user = doc.User
+	}
+	if doc.Type != old.Type {
+		update.passwordType = doc.Type
+		if typeChangeNeedsPassword(old.Type, doc.Type) {
+			update.password = doc.Password
+		}
+	}
+	if doc.Password != old.Password {
+		update.password = doc.Password
+	}
+	if doc.Archived != old.Archived {
+		update.archived = strconv.FormatBool(doc.Archived)
+	}
+	if doc.Expires != old.Expires {
+		update.expires = doc.Expires
+		update.setExpires = true
+	}
+	if doc.RotateEvery != old.RotateEvery {
+		update.rotateEvery = doc.RotateEvery
+		update.setRotateEvery = true
+	}
+	if doc.Notes != old.Notes {
+		update.notes = doc.Notes
+		update.setNotes = true
+	}
+
+	return update, update != passwordUpdate{}
+}
+
+// shredDirectory overwrites the files in `dir` with zeros before removing them and `dir` itself.
+// Editors may create backup or swap files next to the edited file, these are shredded as well.
+func shredDirectory(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("os.ReadDir() failed: %s", err)
+	}
+
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		info, err := entry.Info()
+		if err != nil {
+			return fmt.Errorf("entry.Info() failed: %s", err)
+		}
+
+		if info.Mode().IsRegular() {
+			file, err := os.OpenFile(path, os.O_WRONLY, 0)
+			if err != nil {
+				return fmt.Errorf("os.OpenFile() failed: %s", err)
+			}
+
+			_, err = file.Write(make([]byte, info.Size()))
+			if err != nil {
+				file.Close()
+				return fmt.Errorf("file.Write() failed: %s", err)
+			}
+
+			err = file.Sync()
+			file.Close()
+			if err != nil {
+				return fmt.Errorf("file.Sync() failed: %s", err)
+			}
+		}
+
+		err = Remove(path)
+		if err != nil {
+			return fmt.Errorf("Remove() failed: %s", err)
+		}
+	}
+
+	err = Remove(dir)
+	if err != nil {
+		return fmt.Errorf("Remove() failed: %s", err)
+	}
+
+	return nil
+}
+
+// editFile opens `path` in the editor of the user.
+func editFile(path string) error {
+	editor := strings.Fields(os.Getenv("EDITOR"))
+	if len(editor) == 0 {
+		editor = []string{"vi"}
+	}
+
+	err := runCommand(editor[0], append(editor[1:], path)...)
+	if err != nil {
+		return fmt.Errorf("runCommand() failed: %s", err)
+	}
+
+	return nil
+}
+
+// editDocumentInEditor lets the user edit `doc` in a private temporary file, which is shredded
+// afterwards.
+func editDocumentInEditor(doc editDocument) (editDocument, error) {
+	content, err := formatEditDocument(doc)
+	if err != nil {
+		return doc, fmt.Errorf("formatEditDocument() failed: %s", err)
+	}
+
+	// Only the user can access the directory.
+	dir, err := os.MkdirTemp("", "cpm")
+	if err != nil {
+		return doc, fmt.Errorf("os.MkdirTemp() failed: %s", err)
+	}
+
+	defer shredDirectory(dir)
+
+	path := filepath.Join(dir, "password.yaml")
+	err = os.WriteFile(path, content, 0600)
+	if err != nil {
+		return doc, fmt.Errorf("os.WriteFile() failed: %s", err)
+	}
+
+	err = editFile(path)
+	if err != nil {
+		return doc, fmt.Errorf("editFile() failed: %s", err)
+	}
+
+	content, err = os.ReadFile(path)
+	if err != nil {
+		return doc, fmt.Errorf("os.ReadFile() failed: %s", err)
+	}
+
+	edited, err := parseEditDocument(content)
+	if err != nil {
+		return doc, fmt.Errorf("parseEditDocument() failed: %s", err)
+	}
+
+	return edited, nil
+}
+
+func newEditCommand(ctx *Context) *cobra.Command {
+	var dryRun bool
+	var id string
+	var strength strengthFlags
+	var cmd = &cobra.Command{
+		Use:   "edit",
+		Short: "edits an existing password in an editor",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			transaction, err := ctx.Database.Begin()
+			if err != nil {
+				return fmt.Errorf("db.Begin() failed: %s", err)
+			}
+
+			defer transaction.Rollback()
+
+			if len(id) == 0 {
+				fmt.Fprintf(cmd.OutOrStdout(), "Id: ")
+				reader := bufio.NewReader(cmd.InOrStdin())
+				line, err := reader.ReadString('\n')
+				if err != nil {
+					return fmt.Errorf("ReadString() failed: %s", err)
+				}
+				id = strings.TrimSuffix(line, "\n")
+			}
+			old, err := readEditDocument(transaction, id)
+			if err != nil {
+				return fmt.Errorf("readEditDocument() failed: %s", err)
+			}
+
+			doc, err := editDocumentInEditor(old)
+			if err != nil {
+				return fmt.Errorf("editDocumentInEditor() failed: %s", err)
+			}
+
+			update, changed := getEditUpdate(old, doc)
+			if !changed {
+				fmt.Fprintf(cmd.OutOrStdout(), "No changes\n")
+				ctx.NoWriteBack = true
+				return nil
+			}
+
+			update.strength = strength
+			result, err := updatePassword(cmd, transaction, id, update)
+			if err != nil {
+				return fmt.Errorf("updatePassword() failed: %s", err)
+			}
+
+			if dryRun {
+				fmt.Fprintf(cmd.OutOrStdout(), "Would update %v password\n", result.affected)
+				ctx.NoWriteBack = true
+			} else {
+				transaction.Commit()
+				fmt.Fprintf(cmd.OutOrStdout(), "Updated %v password\n", result.affected)
+			}
+			printGeneratedPassword(cmd, result)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVarP(&dryRun, "dry-run", "n", false, `do everything except actually perform the database action (default: false)`)
+	cmd.Flags().StringVarP(&id, "id", "i", "", `unique identifier (default: ask)`)
+	addStrengthFlags(cmd, &strength)
+
+	return cmd
+}
@@ -0,0 +1,327 @@
+// Copyright 2026 Miklos Vajna
+//
+// SPDX-License-Identifier: MIT
+
+package commands
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// editorRun describes an invocation of the editor.
+type editorRun struct {
+	path    string
+	content string
+	mode    os.FileMode
+	dirMode os.FileMode
+}
+
+// UseEditorForTesting makes the editor run `script` on the edited file, using sh. Returns what the
+// editor saw.
+func UseEditorForTesting(t *testing.T, script string) *editorRun {
+	t.Setenv("EDITOR", "myeditor --wait")
+	run := &editorRun{}
+	oldCommand := Command
+	Command = func(name string, arg ...string) *exec.Cmd {
+		if name != "myeditor" || len(arg) != 2 || arg[0] != "--wait" {
+			t.Fatalf("Command(%s, %v), want myeditor --wait PATH", name, arg)
+		}
+		run.path = arg[1]
+		content, err := os.ReadFile(run.path)
+		if err != nil {
+			t.Fatalf("os.ReadFile() failed: %s", err)
+		}
+		run.content = string(content)
+		info, err := os.Stat(run.path)
+		if err != nil {
+			t.Fatalf("os.Stat() failed: %s", err)
+		}
+		run.mode = info.Mode()
+		info, err = os.Stat(filepath.Dir(run.path))
+		if err != nil {
+			t.Fatalf("os.Stat() failed: %s", err)
+		}
+		run.dirMode = info.Mode()
+		return exec.Command("sh", "-c", script, run.path)
+	}
+	t.Cleanup(func() { Command = oldCommand })
+	return run
+}
+
+func TestEdit(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	_, err := ctx.Database.Exec(`insert into passwords (machine, service, user, password, type, expires) values('mymachine', 'http', 'myuser', 'mypassword', 'plain', '2020-06-01T00:00:00+02:00');`)
+	if err != nil {
+		t.Fatalf("db.Exec() = %q, want nil", err)
+	}
+	run := UseEditorForTesting(t, `sed -i -e 's/^machine: .*/machine: newmachine/' -e 's/^service: .*/service: ssh/' -e 's/^archived: .*/archived: true/' -e 's/^expires: .*/expires: "2020-07-01"/' -e 's/^notes: .*/notes: mynotes/' "$0"`)
+	os.Args = []string{"", "edit", "-i", "1"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	expectedBuf := "Updated 1 password\n"
+	if outBuf.String() != expectedBuf {
+		t.Fatalf("Main() output is %q, want %q", outBuf.String(), expectedBuf)
+	}
+	expectedContent := editHeader + "machine: mymachine\nservice: http\nuser: myuser\ntype: plain\npassword: mypassword\narchived: false\nexpires: \"2020-06-01\"\nrotate_every: 0\nnotes: \"\"\n"
+	if run.content != expectedContent {
+		t.Fatalf("editor content = %q, want %q", run.content, expectedContent)
+	}
+	if run.mode.Perm() != 0600 || run.dirMode.Perm() != 0700 {
+		t.Fatalf("editor file mode = %v, dir mode = %v, want private", run.mode, run.dirMode)
+	}
+	if _, err := os.Stat(filepath.Dir(run.path)); !os.IsNotExist(err) {
+		t.Fatalf("os.Stat() = %v, want the temp dir to be removed", err)
+	}
+	results, err := readPasswords(ctx.Database, searchOptions{verbose: true})
+	if err != nil {
+		t.Fatalf("readPasswords() err = %q, want nil", err)
+	}
+	expected := "id:        1, machine: newmachine, service: ssh, user: myuser, password type: plain, password: mypassword, archived: true, modified: 2020-05-10 00:00, expires: 2020-07-01 00:00, notes: mynotes, strength: 0/4"
+	if len(results) != 1 || results[0] != expected {
+		t.Fatalf("results = %q, want %q", results, expected)
+	}
+}
+
+func TestEditInteractive(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	_, err := ctx.Database.Exec(`insert into passwords (machine, service, user, password, type) values('mymachine', 'http', 'myuser', 'mypassword', 'plain');`)
+	if err != nil {
+		t.Fatalf("db.Exec() = %q, want nil", err)
+	}
+	UseEditorForTesting(t, `sed -i 's/^user: .*/user: newuser/' "$0"`)
+	os.Args = []string{"", "edit"}
+	inBuf := bytes.NewBufferString("1\n")
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	expectedBuf := "Id: Updated 1 password\n"
+	if outBuf.String() != expectedBuf {
+		t.Fatalf("Main() output is %q, want %q", outBuf.String(), expectedBuf)
+	}
+}
+
+func TestEditNoChanges(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	_, err := ctx.Database.Exec(`insert into passwords (machine, service, user, password, type) values('mymachine', 'http', 'myuser', 'mypassword', 'plain');`)
+	if err != nil {
+		t.Fatalf("db.Exec() = %q, want nil", err)
+	}
+	// Reformatting the document is not a change.
+	UseEditorForTesting(t, `sed -i -e '/^#/d' -e 's/^password: .*/password: "mypassword"/' "$0"`)
+	os.Args = []string{"", "edit", "-i", "1"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	expectedBuf := "No changes\n"
+	if outBuf.String() != expectedBuf {
+		t.Fatalf("Main() output is %q, want %q", outBuf.String(), expectedBuf)
+	}
+}
+
+func TestEditDryRun(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	_, err := ctx.Database.Exec(`insert into passwords (machine, service, user, password, type) values('mymachine', 'http', 'myuser', 'mypassword', 'plain');`)
+	if err != nil {
+		t.Fatalf("db.Exec() = %q, want nil", err)
+	}
+	UseEditorForTesting(t, `sed -i 's/^password: .*/password: "-"/' "$0"`)
+	os.Args = []string{"", "edit", "-i", "1", "-n"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	expectedBuf := "Would update 1 password\nGenerated password: output-from-pwgen\n"
+	if outBuf.String() != expectedBuf {
+		t.Fatalf("Main() output is %q, want %q", outBuf.String(), expectedBuf)
+	}
+	results, err := readPasswords(ctx.Database, searchOptions{quiet: true})
+	if err != nil {
+		t.Fatalf("readPasswords() err = %q, want nil", err)
+	}
+	if len(results) != 1 || results[0] != "mypassword" {
+		t.Fatalf("results = %q, want [mypassword]", results)
+	}
+}
+
+func TestEditRecoveryCodes(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	_, err := ctx.Database.Exec(`insert into passwords (machine, service, user, password, type, rotate_every) values('mymachine', 'http', 'myuser', '[{"Code":"code1","Used":true},{"Code":"code2"}]', 'recovery-codes', 10);
+	                             insert into passwords (machine, service, user, password, type) values('mymachine', 'http', 'otheruser', 'code3 code4', 'plain');`)
+	if err != nil {
+		t.Fatalf("db.Exec() = %q, want nil", err)
+	}
+	// Only the unused codes are shown.
+	run := UseEditorForTesting(t, `sed -i -e 's/^password: .*/password: code2 code5/' -e 's/^rotate_every: .*/rotate_every: 0/' "$0"`)
+	os.Args = []string{"", "edit", "-i", "1"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	if !strings.Contains(run.content, "\npassword: code2\n") {
+		t.Fatalf("editor content = %q, want to contain the unused codes", run.content)
+	}
+
+	// Changing the type re-encodes the password.
+	UseEditorForTesting(t, `sed -i 's/^type: .*/type: recovery-codes/' "$0"`)
+	os.Args = []string{"", "edit", "-i", "2"}
+
+	actualRet = Main(inBuf, outBuf)
+
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	results, err := readPasswords(ctx.Database, searchOptions{quiet: true})
+	if err != nil {
+		t.Fatalf("readPasswords() err = %q, want nil", err)
+	}
+	if len(results) != 2 || results[0] != "code2 code5" || results[1] != "code3 code4" {
+		t.Fatalf("results = %q, want [code2 code5, code3 code4]", results)
+	}
+}
+
+// Edit fails because the edited document is invalid, the password is not changed.
+func TestEditInvalid(t *testing.T) {
+	scripts := []string{
+		`sed -i 's/^machine: .*/machine: ""/' "$0"`,
+		`sed -i 's/^type: .*/type: foo/' "$0"`,
+		`sed -i 's/^expires: .*/expires: tomorrow/' "$0"`,
+		`sed -i 's/^rotate_every: .*/rotate_every: -1/' "$0"`,
+		`sed -i 's/^user:/usr:/' "$0"`,
+		`exit 1`,
+	}
+	for _, script := range scripts {
+		ctx := CreateContextForTesting(t)
+		_, err := ctx.Database.Exec(`insert into passwords (machine, service, user, password, type) values('mymachine', 'http', 'myuser', 'mypassword', 'plain');`)
+		if err != nil {
+			t.Fatalf("db.Exec() = %q, want nil", err)
+		}
+		UseEditorForTesting(t, script)
+		os.Args = []string{"", "edit", "-i", "1"}
+		inBuf := new(bytes.Buffer)
+		outBuf := new(bytes.Buffer)
+
+		actualRet := Main(inBuf, outBuf)
+
+		expectedRet := 1
+		if actualRet != expectedRet {
+			t.Fatalf("Main() = %q, want %q, script is %q", actualRet, expectedRet, script)
+		}
+		results, err := readPasswords(ctx.Database, searchOptions{noid: true})
+		if err != nil {
+			t.Fatalf("readPasswords() err = %q, want nil", err)
+		}
+		expected := "machine: mymachine, service: http, user: myuser, password type: plain, password: mypassword"
+		if len(results) != 1 || results[0] != expected {
+			t.Fatalf("results = %q, want %q", results, expected)
+		}
+	}
+}
+
+// Edit fails because there is no password with the ID.
+func TestEditNotFound(t *testing.T) {
+	CreateContextForTesting(t)
+	UseEditorForTesting(t, "true")
+	os.Args = []string{"", "edit", "-i", "42"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 1
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q", actualRet, expectedRet)
+	}
+}
+
+func TestEditFileDefaultEditor(t *testing.T) {
+	t.Setenv("EDITOR", "")
+	var editor string
+	oldCommand := Command
+	Command = func(name string, arg ...string) *exec.Cmd {
+		editor = name
+		return exec.Command("true")
+	}
+	t.Cleanup(func() { Command = oldCommand })
+
+	err := editFile("password.yaml")
+
+	if err != nil {
+		t.Fatalf("editFile() = %q, want nil", err)
+	}
+	if editor != "vi" {
+		t.Fatalf("editor = %q, want %q", editor, "vi")
+	}
+}
+
+func TestShredDirectory(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "edit")
+	err := os.Mkdir(dir, 0700)
+	if err != nil {
+		t.Fatalf("os.Mkdir() failed: %s", err)
+	}
+	// E.g. a swap file of the editor, next to the edited file.
+	err = os.WriteFile(filepath.Join(dir, ".password.yaml.swp"), []byte("secret"), 0600)
+	if err != nil {
+		t.Fatalf("os.WriteFile() failed: %s", err)
+	}
+	err = os.Mkdir(filepath.Join(dir, "subdir"), 0700)
+	if err != nil {
+		t.Fatalf("os.Mkdir() failed: %s", err)
+	}
+	removed := make(map[string]string)
+	oldRemove := Remove
+	Remove = func(name string) error {
+		content, _ := os.ReadFile(name)
+		removed[filepath.Base(name)] = string(content)
+		return os.Remove(name)
+	}
+	t.Cleanup(func() { Remove = oldRemove })
+
+	err = shredDirectory(dir)
+
+	if err != nil {
+		t.Fatalf("shredDirectory() = %q, want nil", err)
+	}
+	if removed[".password.yaml.swp"] != "\x00\x00\x00\x00\x00\x00" {
+		t.Fatalf("content before removal = %q, want zeros", removed[".password.yaml.swp"])
+	}
+	if _, ok := removed["subdir"]; !ok {
+		t.Fatalf("removed = %v, want to contain subdir", removed)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Fatalf("os.Stat() = %v, want the dir to be removed", err)
+	}
+}
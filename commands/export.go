@@ -5,9 +5,16 @@
 package commands
 
 import (
+	"bytes"
 	"database/sql"
+	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -22,50 +29,338 @@ type passwordRow struct {
 	Archived     bool
 	Created      string
 	Modified     string
+	Notes        string `json:",omitempty"`
+	Expires      string `json:",omitempty"`
+	// Rotation interval in days.
+	RotateEvery int `json:",omitempty"`
 }
 
-func exportPasswords(db *sql.DB) ([]byte, error) {
-	var results []passwordRow
-	rows, err := db.Query("select id, machine, service, user, password, type, archived, created, modified from passwords")
+// ExportFormat is an enum of possible export file formats.
+type ExportFormat string
+
+const (
+	// ExportFormatJSON is the own format of cpm, which can be imported again.
+	ExportFormatJSON ExportFormat = "json"
+	// ExportFormatCSV is a CSV file with one line per login.
+	ExportFormatCSV ExportFormat = "csv"
+	// ExportFormatKeepassXML is an XML file for KeePass or KeePassXC.
+	ExportFormatKeepassXML ExportFormat = "keepass-xml"
+	// ExportFormatBitwardenJSON is an unencrypted JSON file for Bitwarden.
+	ExportFormatBitwardenJSON ExportFormat = "bitwarden-json"
+	// ExportFormatPass is the password store directory of pass.
+	ExportFormatPass ExportFormat = "pass"
+)
+
+func (f *ExportFormat) String() string {
+	return string(*f)
+}
+
+// Set sets the value of `f` from `v`.
+func (f *ExportFormat) Set(v string) error {
+	switch v {
+	case "json", "csv", "keepass-xml", "bitwarden-json", "pass":
+		*f = ExportFormat(v)
+		return nil
+	default:
+		return errors.New(`must be one of "json", "csv", "keepass-xml", "bitwarden-json", or "pass"`)
+	}
+}
+
+// Type returns the type of `f` as a string.
+func (f *ExportFormat) Type() string {
+	return "ExportFormat"
+}
+
+// exportEntry is a login in the export formats of other password managers: the passwords with the
+// same machine, service and user.
+type exportEntry struct {
+	Machine  string
+	Service  string
+	User     string
+	Password string
+	// Totp is an otpauth:// URL.
+	Totp     string
+	Archived bool
+	Created  string
+	Modified string
+	Notes    string
+}
+
+// addNotes adds `notes` to the notes of `e`, unless they are there already.
+func (e *exportEntry) addNotes(notes string) {
+	if len(notes) == 0 || strings.Contains(e.Notes, notes) {
+		return
+	}
+
+	if len(e.Notes) > 0 {
+		e.Notes += "\n"
+	}
+	e.Notes += notes
+}
+
+// getURL returns the URL of a login of the http service.
+func (e *exportEntry) getURL() string {
+	if e.Service != "http" {
+		return ""
+	}
+
+	if strings.Contains(e.Machine, "://") {
+		return e.Machine
+	}
+
+	return "https://" + e.Machine
+}
+
+// getExportEntries groups passwords into logins. The unused codes of recovery-codes passwords are
+// added to the notes.
+func getExportEntries(db *sql.DB, opts searchOptions) ([]exportEntry, error) {
+	rows, err := selectPasswords(db, opts)
 	if err != nil {
-		return nil, fmt.Errorf("db.Query(select) failed: %s", err)
+		return nil, fmt.Errorf("selectPasswords() failed: %s", err)
 	}
 
-	defer rows.Close()
-	for rows.Next() {
-		var row passwordRow
-		err = rows.Scan(&row.ID, &row.Machine, &row.Service, &row.User, &row.Password, &row.PasswordType, &row.Archived, &row.Created, &row.Modified)
-		if err != nil {
-			return nil, fmt.Errorf("rows.Scan() failed: %s", err)
+	type entryKey struct {
+		machine  string
+		service  string
+		user     string
+		archived bool
+	}
+	var entries []exportEntry
+	indexes := map[entryKey]int{}
+	for _, row := range rows {
+		key := entryKey{row.Machine, row.Service, row.User, row.Archived}
+		index, ok := indexes[key]
+		if !ok {
+			index = len(entries)
+			indexes[key] = index
+			entries = append(entries, exportEntry{
+				Machine:  row.Machine,
+				Service:  row.Service,
+				User:     row.User,
+				Archived: row.Archived,
+				Created:  row.Created,
+				Modified: row.Modified,
+			})
+		}
+
+		entry := &entries[index]
+		if len(row.Created) > 0 && (len(entry.Created) == 0 || row.Created < entry.Created) {
+			entry.Created = row.Created
+		}
+		if row.Modified > entry.Modified {
+			entry.Modified = row.Modified
+		}
+		entry.addNotes(row.Notes)
+		switch row.PasswordType {
+		case PasswordTypeTotp:
+			entry.Totp = getTotpURL(row.Machine, row.User, row.Password)
+		case PasswordTypeRecoveryCodes:
+			entry.addNotes("Recovery codes: " + formatUnusedRecoveryCodes(row.Password))
+		default:
+			entry.Password = row.Password
 		}
+	}
+
+	return entries, nil
+}
+
+// formatExportedTime formats a time from the database in UTC, the way other password managers store
+// it.
+func formatExportedTime(value string) (string, error) {
+	if len(value) == 0 {
+		return "", nil
+	}
 
-		results = append(results, row)
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return "", fmt.Errorf("time.Parse() failed: %s", err)
+	}
+
+	return t.UTC().Format(time.RFC3339), nil
+}
+
+func exportPasswords(db *sql.DB, opts searchOptions) ([]byte, error) {
+	rows, err := selectPasswords(db, opts)
+	if err != nil {
+		return nil, fmt.Errorf("selectPasswords() failed: %s", err)
 	}
 
-	j, err := json.Marshal(results)
+	j, err := json.Marshal(rows)
 	if err != nil {
 		return nil, fmt.Errorf("json.Marshal() failed: %s", err)
 	}
 
-	return j, nil
+	return append(j, '\n'), nil
+}
+
+// exportCSV writes one line per login, the TOTP shared secret is in the otpauth column.
+func exportCSV(entries []exportEntry) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	writer.Write([]string{"url", "machine", "service", "username", "password", "otpauth", "notes", "archived", "created", "modified"})
+	for _, entry := range entries {
+		writer.Write([]string{entry.getURL(), entry.Machine, entry.Service, entry.User, entry.Password, entry.Totp, entry.Notes, strconv.FormatBool(entry.Archived), entry.Created, entry.Modified})
+	}
+	writer.Flush()
+	err := writer.Error()
+	if err != nil {
+		return nil, fmt.Errorf("writer.Error() failed: %s", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// encryptFile encrypts `content` with gpg for `recipients` and writes the result to `path`.
+func encryptFile(path string, content []byte, recipients []string) error {
+	args := []string{"--encrypt", "--quiet", "--yes", "--output", path}
+	for _, recipient := range recipients {
+		args = append(args, "--recipient", recipient)
+	}
+	cmd := Command("gpg", args...)
+	cmd.Stdin = bytes.NewReader(content)
+	cmd.Stderr = os.Stderr
+	err := cmd.Run()
+	if err != nil {
+		return fmt.Errorf("cmd.Run(gpg) failed: %s", err)
+	}
+
+	return nil
+}
+
+// formatExport formats logins in `format`, which is written to the standard output.
+func formatExport(db *sql.DB, format ExportFormat, opts searchOptions) ([]byte, error) {
+	if format == ExportFormatJSON {
+		content, err := exportPasswords(db, opts)
+		if err != nil {
+			return nil, fmt.Errorf("exportPasswords() failed: %s", err)
+		}
+		return content, nil
+	}
+
+	entries, err := getExportEntries(db, opts)
+	if err != nil {
+		return nil, fmt.Errorf("getExportEntries() failed: %s", err)
+	}
+
+	var content []byte
+	switch format {
+	case ExportFormatKeepassXML:
+		content, err = exportKeepassXML(entries)
+		if err != nil {
+			return nil, fmt.Errorf("exportKeepassXML() failed: %s", err)
+		}
+	case ExportFormatBitwardenJSON:
+		content, err = exportBitwardenJSON(entries)
+		if err != nil {
+			return nil, fmt.Errorf("exportBitwardenJSON() failed: %s", err)
+		}
+	default:
+		content, err = exportCSV(entries)
+		if err != nil {
+			return nil, fmt.Errorf("exportCSV() failed: %s", err)
+		}
+	}
+
+	return content, nil
+}
+
+// writeExport writes the export to `path`, encrypted for `recipients` if there are any.
+func writeExport(path string, content []byte, recipients []string) error {
+	if len(recipients) > 0 {
+		err := encryptFile(path, content, recipients)
+		if err != nil {
+			return fmt.Errorf("encryptFile() failed: %s", err)
+		}
+		return nil
+	}
+
+	err := os.WriteFile(path, content, 0600)
+	if err != nil {
+		return fmt.Errorf("os.WriteFile() failed: %s", err)
+	}
+
+	return nil
 }
 
 func newExportCommand(ctx *Context) *cobra.Command {
+	var format ExportFormat = ExportFormatJSON
+	var machineFlag string
+	var serviceFlag string
+	var userFlag string
+	var typeFlag PasswordType
+	var archivedFlag bool
+	var excludeArchived bool
+	var outputPath string
+	var recipients []string
 	var cmd = &cobra.Command{
-		Use:   "export",
-		Short: "exports passwords as JSON",
+		Use:   "export [DIR]",
+		Short: "exports passwords as JSON or for other password managers",
+		Args:  cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			j, err := exportPasswords(ctx.Database)
+			ctx.NoWriteBack = true
+			if format == ExportFormatPass && len(args) == 0 {
+				return errors.New("the pass format needs an initialized password store directory to export to")
+			}
+			if format != ExportFormatPass && len(args) > 0 {
+				return fmt.Errorf("the %s format is written to the standard output or --output, not to a directory", format)
+			}
+			if format == ExportFormatPass && (len(outputPath) > 0 || len(recipients) > 0) {
+				return errors.New("the pass format is encrypted for the keys of the password store, --output and --encrypt-to are not supported")
+			}
+			if len(recipients) > 0 && len(outputPath) == 0 {
+				return errors.New("--encrypt-to needs --output")
+			}
+
+			var opts searchOptions
+			opts.wantedMachine = machineFlag
+			opts.wantedService = serviceFlag
+			opts.wantedUser = userFlag
+			opts.wantedType = typeFlag
+			// Verbose search includes archived passwords.
+			opts.verbose = !excludeArchived
+			opts.archived = archivedFlag
+			if format == ExportFormatPass {
+				entries, err := getExportEntries(ctx.Database, opts)
+				if err != nil {
+					return fmt.Errorf("getExportEntries() failed: %s", err)
+				}
+
+				err = exportPasswordStore(args[0], entries)
+				if err != nil {
+					return fmt.Errorf("exportPasswordStore() failed: %s", err)
+				}
+
+				fmt.Fprintf(cmd.OutOrStdout(), "Exported %v entries to '%s'\n", len(entries), args[0])
+				return nil
+			}
+
+			content, err := formatExport(ctx.Database, format, opts)
 			if err != nil {
-				return fmt.Errorf("readPasswords() failed: %s", err)
+				return fmt.Errorf("formatExport() failed: %s", err)
 			}
 
-			fmt.Fprintf(cmd.OutOrStdout(), "%s\n", j)
+			if len(outputPath) > 0 {
+				err = writeExport(outputPath, content, recipients)
+				if err != nil {
+					return fmt.Errorf("writeExport() failed: %s", err)
+				}
+				return nil
+			}
 
-			ctx.NoWriteBack = true
+			cmd.OutOrStdout().Write(content)
 			return nil
 		},
 	}
+	cmd.Flags().VarP(&format, "format", "f", `format of the export ("json", "csv", "keepass-xml", "bitwarden-json" or "pass"; default: "json", "pass" writes to an initialized password store directory)`)
+	cmd.Flags().StringVarP(&machineFlag, "machine", "m", "", `only export passwords of this machine (default: "")`)
+	cmd.Flags().StringVarP(&serviceFlag, "service", "s", "", `only export passwords of this service (default: "")`)
+	cmd.Flags().StringVarP(&userFlag, "user", "u", "", `only export passwords of this user (default: "")`)
+	cmd.Flags().VarP(&typeFlag, "type", "t", `only export passwords of this type ("plain", "totp" or "recovery-codes", default: "")`)
+	cmd.Flags().BoolVarP(&archivedFlag, "archived", "", false, "only export archived passwords (default: false)")
+	cmd.Flags().BoolVarP(&excludeArchived, "exclude-archived", "", false, "leave out archived passwords (default: false)")
+	cmd.MarkFlagsMutuallyExclusive("archived", "exclude-archived")
+	cmd.Flags().StringVarP(&outputPath, "output", "o", "", `write the export to this file, instead of the standard output (default: "")`)
+	cmd.Flags().StringArrayVarP(&recipients, "encrypt-to", "", nil, `encrypt the --output file with gpg for this recipient, can be repeated (default: none)`)
 
 	return cmd
 }
@@ -0,0 +1,97 @@
+// Copyright 2026 Miklos Vajna
+//
+// SPDX-License-Identifier: MIT
+
+package commands
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+)
+
+// newBitwardenUUID returns a random version 4 UUID, the ID of a folder.
+func newBitwardenUUID() (string, error) {
+	uuid := make([]byte, 16)
+	_, err := rand.Read(uuid)
+	if err != nil {
+		return "", fmt.Errorf("rand.Read() failed: %s", err)
+	}
+
+	uuid[6] = uuid[6]&0x0f | 0x40
+	uuid[8] = uuid[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", uuid[0:4], uuid[4:6], uuid[6:8], uuid[8:10], uuid[10:]), nil
+}
+
+// getBitwardenFolder returns the folder name of a login: its service, unless that is http, archived
+// logins are in the Archive folder.
+func getBitwardenFolder(entry *exportEntry) string {
+	var folder string
+	if entry.Service != "http" {
+		folder = entry.Service
+	}
+	if entry.Archived {
+		if len(folder) > 0 {
+			return "Archive/" + folder
+		}
+		return "Archive"
+	}
+
+	return folder
+}
+
+// exportBitwardenJSON writes an unencrypted Bitwarden JSON export, the TOTP shared secret is in the
+// totp field of the login.
+func exportBitwardenJSON(entries []exportEntry) ([]byte, error) {
+	export := bitwardenExport{
+		Folders: []bitwardenFolder{},
+		Items:   []bitwardenItem{},
+	}
+	folderIDs := map[string]string{}
+	for i := range entries {
+		entry := &entries[i]
+		item := bitwardenItem{
+			Type:  bitwardenLogin,
+			Name:  entry.Machine,
+			Notes: entry.Notes,
+		}
+		folder := getBitwardenFolder(entry)
+		if len(folder) > 0 {
+			id, ok := folderIDs[folder]
+			if !ok {
+				var err error
+				id, err = newBitwardenUUID()
+				if err != nil {
+					return nil, fmt.Errorf("newBitwardenUUID() failed: %s", err)
+				}
+
+				folderIDs[folder] = id
+				export.Folders = append(export.Folders, bitwardenFolder{ID: id, Name: folder})
+			}
+			item.FolderID = id
+		}
+		var err error
+		item.CreationDate, err = formatExportedTime(entry.Created)
+		if err != nil {
+			return nil, fmt.Errorf("formatExportedTime() failed: %s", err)
+		}
+		item.RevisionDate, err = formatExportedTime(entry.Modified)
+		if err != nil {
+			return nil, fmt.Errorf("formatExportedTime() failed: %s", err)
+		}
+		if link := entry.getURL(); len(link) > 0 {
+			item.Login.URIs = []bitwardenURI{{URI: link}}
+		}
+		item.Login.Username = entry.User
+		item.Login.Password = entry.Password
+		item.Login.Totp = entry.Totp
+		export.Items = append(export.Items, item)
+	}
+
+	content, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("json.MarshalIndent() failed: %s", err)
+	}
+
+	return append(content, '\n'), nil
+}
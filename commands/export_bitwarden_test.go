@@ -0,0 +1,59 @@
+// Copyright 2026 Miklos Vajna
+//
+// SPDX-License-Identifier: MIT
+
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"slices"
+	"testing"
+)
+
+// Exported logins can be imported again, archived ones are in the Archive folder.
+func TestExportBitwardenJSON(t *testing.T) {
+	createExportPasswordsForTesting(t)
+	os.Args = []string{"", "export", "-f", "bitwarden-json"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q", actualRet, expectedRet)
+	}
+	var export bitwardenExport
+	err := json.Unmarshal(outBuf.Bytes(), &export)
+	if err != nil {
+		t.Fatalf("json.Unmarshal() err = %q, want nil", err)
+	}
+	if len(export.Folders) != 2 || export.Folders[0].Name != "Archive/email" || export.Folders[1].Name != "Archive" {
+		t.Fatalf("export.Folders = %v, want Archive/email and Archive", export.Folders)
+	}
+	folderIDs := []string{export.Items[0].FolderID, export.Items[1].FolderID, export.Items[2].FolderID, export.Items[3].FolderID}
+	expectedFolderIDs := []string{"", export.Folders[0].ID, export.Folders[0].ID, export.Folders[1].ID}
+	if !slices.Equal(folderIDs, expectedFolderIDs) {
+		t.Fatalf("folderIDs = %q, want %q", folderIDs, expectedFolderIDs)
+	}
+	rows, unmapped, err := parseBitwardenJSON(outBuf.Bytes())
+	if err != nil {
+		t.Fatalf("parseBitwardenJSON() err = %q, want nil", err)
+	}
+	if len(unmapped) != 0 {
+		t.Fatalf("unmapped = %q, want none", unmapped)
+	}
+	notes := "PIN: 1234\nRecovery codes: b2"
+	expected := []passwordRow{
+		{Machine: "example.com", Service: "http", User: "alice", Password: "alicepassword", PasswordType: PasswordTypePlain, Created: "2020-04-01T12:00:00+02:00", Modified: "2020-05-03T12:00:00+02:00", Notes: notes},
+		{Machine: "example.com", Service: "http", User: "alice", Password: "otpauth://totp/example.com:alice?issuer=example.com&secret=JBSWY3DPEHPK3PXP", PasswordType: PasswordTypeTotp, Created: "2020-04-01T12:00:00+02:00", Modified: "2020-05-03T12:00:00+02:00", Notes: notes},
+		{Machine: "gmail.com", Service: "http", User: "bob", Password: "bobpassword", PasswordType: PasswordTypePlain},
+		{Machine: "outlook.com", Service: "http", User: "bob", Password: "outlookpassword", PasswordType: PasswordTypePlain},
+		{Machine: "file:///home/carol/form.html", Service: "http", User: "carol", Password: "carolpassword", PasswordType: PasswordTypePlain},
+	}
+	if !slices.Equal(rows, expected) {
+		t.Fatalf("rows = %v, want %v", rows, expected)
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright 2026 Miklos Vajna
+//
+// SPDX-License-Identifier: MIT
+
+package commands
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// newKeepassUUID returns a random UUID, encoded the way KeePass XML stores it.
+func newKeepassUUID() (string, error) {
+	uuid := make([]byte, 16)
+	_, err := rand.Read(uuid)
+	if err != nil {
+		return "", fmt.Errorf("rand.Read() failed: %s", err)
+	}
+
+	return base64.StdEncoding.EncodeToString(uuid), nil
+}
+
+// newKeepassGroup returns an empty group with a new UUID.
+func newKeepassGroup(name string) (keepassGroup, error) {
+	uuid, err := newKeepassUUID()
+	if err != nil {
+		return keepassGroup{}, fmt.Errorf("newKeepassUUID() failed: %s", err)
+	}
+
+	return keepassGroup{UUID: uuid, Name: name}, nil
+}
+
+// addKeepassEntry adds `entry` to the group at `path` below `group`, creating missing groups.
+func addKeepassEntry(group *keepassGroup, path []string, entry keepassEntry) error {
+	if len(path) == 0 {
+		group.Entries = append(group.Entries, entry)
+		return nil
+	}
+
+	index := -1
+	for i := range group.Groups {
+		if group.Groups[i].Name == path[0] {
+			index = i
+		}
+	}
+	if index == -1 {
+		child, err := newKeepassGroup(path[0])
+		if err != nil {
+			return fmt.Errorf("newKeepassGroup() failed: %s", err)
+		}
+
+		index = len(group.Groups)
+		group.Groups = append(group.Groups, child)
+	}
+
+	return addKeepassEntry(&group.Groups[index], path[1:], entry)
+}
+
+// getKeepassEntry turns a login into an entry, the TOTP shared secret is in the otp field of
+// KeePassXC.
+func getKeepassEntry(entry *exportEntry) (keepassEntry, error) {
+	uuid, err := newKeepassUUID()
+	if err != nil {
+		return keepassEntry{}, fmt.Errorf("newKeepassUUID() failed: %s", err)
+	}
+
+	result := keepassEntry{
+		UUID: uuid,
+		Strings: []keepassString{
+			{Key: "Title", Value: entry.Machine},
+			{Key: "UserName", Value: entry.User},
+			{Key: "Password", Value: entry.Password},
+			{Key: "URL", Value: entry.getURL()},
+			{Key: "Notes", Value: entry.Notes},
+		},
+	}
+	if len(entry.Totp) > 0 {
+		result.Strings = append(result.Strings, keepassString{Key: "otp", Value: entry.Totp})
+	}
+	result.Times.CreationTime, err = formatExportedTime(entry.Created)
+	if err != nil {
+		return keepassEntry{}, fmt.Errorf("formatExportedTime() failed: %s", err)
+	}
+	result.Times.LastModificationTime, err = formatExportedTime(entry.Modified)
+	if err != nil {
+		return keepassEntry{}, fmt.Errorf("formatExportedTime() failed: %s", err)
+	}
+
+	return result, nil
+}
+
+// exportKeepassXML writes a KeePass XML document. The group of an entry is its service, unless that
+// is http, archived entries are in the recycle bin.
+func exportKeepassXML(entries []exportEntry) ([]byte, error) {
+	root, err := newKeepassGroup("cpm")
+	if err != nil {
+		return nil, fmt.Errorf("newKeepassGroup() failed: %s", err)
+	}
+
+	recycleBin, err := newKeepassGroup("Recycle Bin")
+	if err != nil {
+		return nil, fmt.Errorf("newKeepassGroup() failed: %s", err)
+	}
+
+	for i := range entries {
+		entry := &entries[i]
+		keepassEntry, err := getKeepassEntry(entry)
+		if err != nil {
+			return nil, fmt.Errorf("getKeepassEntry() failed: %s", err)
+		}
+
+		var path []string
+		if entry.Service != "http" {
+			path = strings.Split(entry.Service, "/")
+		}
+		group := &root
+		if entry.Archived {
+			group = &recycleBin
+		}
+		err = addKeepassEntry(group, path, keepassEntry)
+		if err != nil {
+			return nil, fmt.Errorf("addKeepassEntry() failed: %s", err)
+		}
+	}
+
+	var file keepassFile
+	file.Meta.Generator = "cpm"
+	if len(recycleBin.Entries) > 0 || len(recycleBin.Groups) > 0 {
+		file.Meta.RecycleBinEnabled = "True"
+		file.Meta.RecycleBinUUID = recycleBin.UUID
+		root.Groups = append(root.Groups, recycleBin)
+	}
+	file.Root.Groups = []keepassGroup{root}
+	content, err := xml.MarshalIndent(file, "", "\t")
+	if err != nil {
+		return nil, fmt.Errorf("xml.MarshalIndent() failed: %s", err)
+	}
+
+	return append([]byte(xml.Header), append(content, '\n')...), nil
+}
@@ -0,0 +1,46 @@
+// Copyright 2026 Miklos Vajna
+//
+// SPDX-License-Identifier: MIT
+
+package commands
+
+import (
+	"bytes"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// Exported logins can be imported again, archived ones are in the recycle bin.
+func TestExportKeepassXML(t *testing.T) {
+	createExportPasswordsForTesting(t)
+	os.Args = []string{"", "export", "-f", "keepass-xml"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q", actualRet, expectedRet)
+	}
+	if !strings.Contains(outBuf.String(), "<RecycleBinEnabled>True</RecycleBinEnabled>") {
+		t.Fatalf("Main() output is %q, want a recycle bin", outBuf.String())
+	}
+	rows, err := parseKeepassXML(outBuf.Bytes(), nil)
+	if err != nil {
+		t.Fatalf("parseKeepassXML() err = %q, want nil", err)
+	}
+	notes := "PIN: 1234\nRecovery codes: b2"
+	expected := []passwordRow{
+		{Machine: "example.com", Service: "http", User: "alice", Password: "alicepassword", PasswordType: PasswordTypePlain, Created: "2020-04-01T12:00:00+02:00", Modified: "2020-05-03T12:00:00+02:00", Notes: notes},
+		{Machine: "example.com", Service: "http", User: "alice", Password: "otpauth://totp/example.com:alice?issuer=example.com&secret=JBSWY3DPEHPK3PXP", PasswordType: PasswordTypeTotp, Created: "2020-04-01T12:00:00+02:00", Modified: "2020-05-03T12:00:00+02:00", Notes: notes},
+		{Machine: "file:///home/carol/form.html", Service: "http", User: "carol", Password: "carolpassword", PasswordType: PasswordTypePlain, Archived: true},
+		{Machine: "gmail.com", Service: "email", User: "bob", Password: "bobpassword", PasswordType: PasswordTypePlain, Archived: true},
+		{Machine: "outlook.com", Service: "email", User: "bob", Password: "outlookpassword", PasswordType: PasswordTypePlain, Archived: true},
+	}
+	if !slices.Equal(rows, expected) {
+		t.Fatalf("rows = %v, want %v", rows, expected)
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright 2026 Miklos Vajna
+//
+// SPDX-License-Identifier: MIT
+
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// escapePassName makes `name` usable as a single component of a path in a password store.
+func escapePassName(name string) string {
+	name = strings.ReplaceAll(name, "/", "_")
+	if name == "." || name == ".." {
+		// Would refer to the current or the parent directory.
+		return strings.ReplaceAll(name, ".", "_")
+	}
+
+	return name
+}
+
+// getPassEntryPath returns the path of a login in a password store, the inverse of
+// readPasswordStore(): the service is omitted if it's http, archived logins are in the archive
+// directory.
+func getPassEntryPath(entry *exportEntry) string {
+	var names []string
+	if entry.Archived {
+		names = append(names, "archive")
+	}
+	if entry.Service != "http" {
+		// Only the service may create directories.
+		for _, name := range strings.Split(entry.Service, "/") {
+			names = append(names, escapePassName(name))
+		}
+	}
+	names = append(names, escapePassName(entry.Machine))
+	if len(entry.User) > 0 {
+		names = append(names, escapePassName(entry.User))
+	}
+
+	return filepath.Join(names...) + ".gpg"
+}
+
+// formatPassEntry formats a login the way pass and pass-otp store it: the first line is the
+// password, followed by the otpauth:// URL and the notes.
+func formatPassEntry(entry *exportEntry) string {
+	lines := []string{entry.Password}
+	if len(entry.Totp) > 0 {
+		lines = append(lines, entry.Totp)
+	}
+	if len(entry.Notes) > 0 {
+		lines = append(lines, entry.Notes)
+	}
+
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// exportPasswordStore encrypts the logins to the password store at `dir`, which is already
+// initialized with 'pass init', for the keys in its .gpg-id file.
+func exportPasswordStore(dir string, entries []exportEntry) error {
+	gpgID, err := os.ReadFile(filepath.Join(dir, ".gpg-id"))
+	if err != nil {
+		return fmt.Errorf("os.ReadFile() failed: %s", err)
+	}
+
+	recipients := strings.Fields(string(gpgID))
+	// gpg overwrites files, so check all paths before writing anything.
+	paths := make([]string, len(entries))
+	seen := make(map[string]bool)
+	for i := range entries {
+		path := filepath.Join(dir, getPassEntryPath(&entries[i]))
+		if seen[path] || pathExists(path) {
+			return fmt.Errorf("'%s' already exists, refusing to overwrite it", path)
+		}
+
+		seen[path] = true
+		paths[i] = path
+	}
+
+	for i := range entries {
+		entry := &entries[i]
+		path := paths[i]
+		err = os.MkdirAll(filepath.Dir(path), 0700)
+		if err != nil {
+			return fmt.Errorf("os.MkdirAll() failed: %s", err)
+		}
+
+		err = encryptFile(path, []byte(formatPassEntry(entry)), recipients)
+		if err != nil {
+			return fmt.Errorf("encryptFile() failed: %s", err)
+		}
+	}
+
+	return nil
+}
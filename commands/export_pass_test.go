@@ -0,0 +1,155 @@
+// Copyright 2026 Miklos Vajna
+//
+// SPDX-License-Identifier: MIT
+
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// Exported logins can be imported again, CommandForTesting encrypts files by just writing them.
+func TestExportPass(t *testing.T) {
+	createExportPasswordsForTesting(t)
+	UseCommandForTesting(t)
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, ".gpg-id"), []byte("alice@example.com\n"), 0600)
+	if err != nil {
+		t.Fatalf("os.WriteFile() failed: %s", err)
+	}
+	os.Args = []string{"", "export", "-f", "pass", dir}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q", actualRet, expectedRet)
+	}
+	expectedBuf := fmt.Sprintf("Exported 4 entries to '%s'\n", dir)
+	if outBuf.String() != expectedBuf {
+		t.Fatalf("Main() output is %q, want %q", outBuf.String(), expectedBuf)
+	}
+	content, err := os.ReadFile(filepath.Join(dir, "example.com", "alice.gpg"))
+	if err != nil {
+		t.Fatalf("os.ReadFile() err = %q, want nil", err)
+	}
+	expectedContent := "alicepassword\notpauth://totp/example.com:alice?issuer=example.com&secret=JBSWY3DPEHPK3PXP\nPIN: 1234\nRecovery codes: b2\n"
+	if string(content) != expectedContent {
+		t.Fatalf("content = %q, want %q", content, expectedContent)
+	}
+	rows, err := readPasswordStore(dir)
+	if err != nil {
+		t.Fatalf("readPasswordStore() err = %q, want nil", err)
+	}
+	notes := "PIN: 1234\nRecovery codes: b2"
+	expected := []passwordRow{
+		{Machine: "gmail.com", Service: "archive/email", User: "bob", Password: "bobpassword", PasswordType: PasswordTypePlain},
+		{Machine: "outlook.com", Service: "archive/email", User: "bob", Password: "outlookpassword", PasswordType: PasswordTypePlain},
+		{Machine: "file:___home_carol_form.html", Service: "archive", User: "carol", Password: "carolpassword", PasswordType: PasswordTypePlain},
+		{Machine: "example.com", Service: "http", User: "alice", Password: "alicepassword", PasswordType: PasswordTypePlain, Notes: notes},
+		{Machine: "example.com", Service: "http", User: "alice", Password: "otpauth://totp/example.com:alice?issuer=example.com&secret=JBSWY3DPEHPK3PXP", PasswordType: PasswordTypeTotp, Notes: notes},
+	}
+	if !slices.Equal(rows, expected) {
+		t.Fatalf("rows = %v, want %v", rows, expected)
+	}
+}
+
+// Names can't escape the password store or refer to an existing directory.
+func TestGetPassEntryPath(t *testing.T) {
+	for _, test := range []struct {
+		entry    exportEntry
+		expected string
+	}{
+		{exportEntry{Machine: "example.com", Service: "http", User: "alice"}, "example.com/alice.gpg"},
+		{exportEntry{Machine: "example.com", Service: "email/work", User: "alice"}, "email/work/example.com/alice.gpg"},
+		{exportEntry{Machine: "example.com", Service: "../../etc", User: "alice"}, "__/__/etc/example.com/alice.gpg"},
+		{exportEntry{Machine: "..", Service: "http", User: "alice"}, "__/alice.gpg"},
+		{exportEntry{Machine: "example.com", Service: "./email", User: "a/b"}, "_/email/example.com/a_b.gpg"},
+	} {
+		actual := getPassEntryPath(&test.entry)
+		if actual != test.expected {
+			t.Fatalf("getPassEntryPath(%v) = %q, want %q", test.entry, actual, test.expected)
+		}
+	}
+}
+
+// Existing entries of the password store are not overwritten, and nothing is written in that case.
+func TestExportPassExisting(t *testing.T) {
+	createExportPasswordsForTesting(t)
+	UseCommandForTesting(t)
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, ".gpg-id"), []byte("alice@example.com\n"), 0600)
+	if err != nil {
+		t.Fatalf("os.WriteFile() failed: %s", err)
+	}
+	existing := filepath.Join(dir, "example.com", "alice.gpg")
+	err = os.MkdirAll(filepath.Dir(existing), 0700)
+	if err != nil {
+		t.Fatalf("os.MkdirAll() failed: %s", err)
+	}
+	err = os.WriteFile(existing, []byte("oldpassword\n"), 0600)
+	if err != nil {
+		t.Fatalf("os.WriteFile() failed: %s", err)
+	}
+	os.Args = []string{"", "export", "-f", "pass", dir}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 1
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q", actualRet, expectedRet)
+	}
+	content, err := os.ReadFile(existing)
+	if err != nil {
+		t.Fatalf("os.ReadFile() err = %q, want nil", err)
+	}
+	expectedContent := "oldpassword\n"
+	if string(content) != expectedContent {
+		t.Fatalf("content = %q, want %q", content, expectedContent)
+	}
+	rows, err := readPasswordStore(dir)
+	if err != nil {
+		t.Fatalf("readPasswordStore() err = %q, want nil", err)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("rows = %v, want only the existing one", rows)
+	}
+}
+
+// Logins which would be written to the same file are refused.
+func TestExportPassDuplicate(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	_, err := ctx.Database.Exec(`insert into passwords (machine, service, user, password, type) values('a/b', 'http', 'alice', 'password1', 'plain');
+	                             insert into passwords (machine, service, user, password, type) values('a_b', 'http', 'alice', 'password2', 'plain');`)
+	if err != nil {
+		t.Fatalf("db.Exec() = %q, want nil", err)
+	}
+	UseCommandForTesting(t)
+	dir := t.TempDir()
+	err = os.WriteFile(filepath.Join(dir, ".gpg-id"), []byte("alice@example.com\n"), 0600)
+	if err != nil {
+		t.Fatalf("os.WriteFile() failed: %s", err)
+	}
+	os.Args = []string{"", "export", "-f", "pass", dir}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 1
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q", actualRet, expectedRet)
+	}
+	if pathExists(filepath.Join(dir, "a_b", "alice.gpg")) {
+		t.Fatalf("pathExists() = true, want nothing written")
+	}
+}
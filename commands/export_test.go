@@ -8,6 +8,8 @@ import (
 	"bytes"
 	"encoding/json"
 	"os"
+	"path/filepath"
+	"slices"
 	"testing"
 )
 
@@ -65,3 +67,176 @@ func TestExport(t *testing.T) {
 		t.Fatalf("password.Modified = %q, want %q", password.Modified, "")
 	}
 }
+
+// createExportPasswordsForTesting creates a login with a plain password, a TOTP shared secret and
+// recovery codes, and archived passwords.
+func createExportPasswordsForTesting(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	_, err := ctx.Database.Exec(`insert into passwords (machine, service, user, password, type, created, modified, notes) values('example.com', 'http', 'alice', 'alicepassword', 'plain', '2020-05-01T12:00:00+02:00', '2020-05-02T12:00:00+02:00', 'PIN: 1234');
+	                             insert into passwords (machine, service, user, password, type, created, modified) values('example.com', 'http', 'alice', 'JBSWY3DPEHPK3PXP', 'totp', '2020-04-01T12:00:00+02:00', '2020-05-03T12:00:00+02:00');
+	                             insert into passwords (machine, service, user, password, type) values('example.com', 'http', 'alice', '[{"Code":"a1","Used":true},{"Code":"b2"}]', 'recovery-codes');
+	                             insert into passwords (machine, service, user, password, type, archived) values('gmail.com', 'email', 'bob', 'bobpassword', 'plain', 1);
+	                             insert into passwords (machine, service, user, password, type, archived) values('outlook.com', 'email', 'bob', 'outlookpassword', 'plain', 1);
+	                             insert into passwords (machine, service, user, password, type, archived) values('file:///home/carol/form.html', 'http', 'carol', 'carolpassword', 'plain', 1);`)
+	if err != nil {
+		t.Fatalf("db.Exec() = %q, want nil", err)
+	}
+}
+
+func TestExportCSV(t *testing.T) {
+	createExportPasswordsForTesting(t)
+	os.Args = []string{"", "export", "-f", "csv"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q", actualRet, expectedRet)
+	}
+	expectedBuf := `url,machine,service,username,password,otpauth,notes,archived,created,modified
+https://example.com,example.com,http,alice,alicepassword,otpauth://totp/example.com:alice?issuer=example.com&secret=JBSWY3DPEHPK3PXP,"PIN: 1234
+Recovery codes: b2",false,2020-04-01T12:00:00+02:00,2020-05-03T12:00:00+02:00
+,gmail.com,email,bob,bobpassword,,,true,,
+,outlook.com,email,bob,outlookpassword,,,true,,
+file:///home/carol/form.html,file:///home/carol/form.html,http,carol,carolpassword,,,true,,
+`
+	if outBuf.String() != expectedBuf {
+		t.Fatalf("Main() output is %q, want %q", outBuf.String(), expectedBuf)
+	}
+}
+
+// Archived passwords are left out on request.
+func TestExportExcludeArchived(t *testing.T) {
+	createExportPasswordsForTesting(t)
+	os.Args = []string{"", "export", "--exclude-archived"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q", actualRet, expectedRet)
+	}
+	var passwords []passwordRow
+	err := json.NewDecoder(outBuf).Decode(&passwords)
+	if err != nil {
+		t.Fatalf("json.Decode() = %q, want nil", err)
+	}
+	if len(passwords) != 3 {
+		t.Fatalf("passwords len = %q, want %q", len(passwords), 3)
+	}
+	for _, password := range passwords {
+		if password.Archived {
+			t.Fatalf("password.Archived = %t, want %t", password.Archived, false)
+		}
+	}
+}
+
+// Export fails because the directory is missing, not wanted or not a password store, or the flags
+// are inconsistent.
+func TestExportInvalid(t *testing.T) {
+	tests := [][]string{
+		{"-f", "pass"},
+		{"-f", "csv", t.TempDir()},
+		{"-f", "pass", t.TempDir()},
+		{"--encrypt-to", "alice@example.com"},
+		{"-f", "pass", "-o", "export.json", t.TempDir()},
+		{"--archived", "--exclude-archived"},
+	}
+	for _, args := range tests {
+		ctx := CreateContextForTesting(t)
+		UseCommandForTesting(t)
+		_, err := ctx.Database.Exec(`insert into passwords (machine, service, user, password, type) values('mymachine', 'http', 'myuser', 'mypassword', 'plain');`)
+		if err != nil {
+			t.Fatalf("db.Exec() = %q, want nil", err)
+		}
+		os.Args = append([]string{"", "export"}, args...)
+		inBuf := new(bytes.Buffer)
+		outBuf := new(bytes.Buffer)
+
+		actualRet := Main(inBuf, outBuf)
+
+		expectedRet := 1
+		if actualRet != expectedRet {
+			t.Fatalf("Main() = %q, want %q, args are %q", actualRet, expectedRet, args)
+		}
+	}
+}
+
+// Only the passwords which match the filters are exported.
+func TestExportFilters(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected []string
+	}{
+		{[]string{"-m", "example.com", "-t", "totp"}, []string{"example.com totp"}},
+		{[]string{"-s", "email", "-u", "bob"}, []string{"gmail.com plain", "outlook.com plain"}},
+		{[]string{"--archived"}, []string{"gmail.com plain", "outlook.com plain", "file:///home/carol/form.html plain"}},
+	}
+	for _, test := range tests {
+		createExportPasswordsForTesting(t)
+		os.Args = append([]string{"", "export"}, test.args...)
+		inBuf := new(bytes.Buffer)
+		outBuf := new(bytes.Buffer)
+
+		actualRet := Main(inBuf, outBuf)
+
+		expectedRet := 0
+		if actualRet != expectedRet {
+			t.Fatalf("Main() = %q, want %q, args are %q", actualRet, expectedRet, test.args)
+		}
+		var passwords []passwordRow
+		err := json.NewDecoder(outBuf).Decode(&passwords)
+		if err != nil {
+			t.Fatalf("json.Decode() = %q, want nil", err)
+		}
+		var actual []string
+		for _, password := range passwords {
+			actual = append(actual, password.Machine+" "+string(password.PasswordType))
+		}
+		if !slices.Equal(actual, test.expected) {
+			t.Fatalf("actual = %q, want %q, args are %q", actual, test.expected, test.args)
+		}
+	}
+}
+
+// The export is written to a file, encrypted if wanted. CommandForTesting encrypts files by just
+// writing them.
+func TestExportOutput(t *testing.T) {
+	tests := [][]string{
+		{"-o"},
+		{"--encrypt-to", "alice@example.com", "--encrypt-to", "bob@example.com", "-o"},
+	}
+	for _, test := range tests {
+		createExportPasswordsForTesting(t)
+		UseCommandForTesting(t)
+		path := filepath.Join(t.TempDir(), "export.csv")
+		os.Args = append(append([]string{"", "export", "-f", "csv", "-s", "email"}, test...), path)
+		inBuf := new(bytes.Buffer)
+		outBuf := new(bytes.Buffer)
+
+		actualRet := Main(inBuf, outBuf)
+
+		expectedRet := 0
+		if actualRet != expectedRet {
+			t.Fatalf("Main() = %q, want %q, args are %q", actualRet, expectedRet, test)
+		}
+		if outBuf.Len() != 0 {
+			t.Fatalf("Main() output is %q, want none", outBuf.String())
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("os.ReadFile() err = %q, want nil", err)
+		}
+		expectedContent := `url,machine,service,username,password,otpauth,notes,archived,created,modified
+,gmail.com,email,bob,bobpassword,,,true,,
+,outlook.com,email,bob,outlookpassword,,,true,,
+`
+		if string(content) != expectedContent {
+			t.Fatalf("content = %q, want %q", content, expectedContent)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright 2026 Miklos Vajna
+//
+// SPDX-License-Identifier: MIT
+
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func newGenerateCommand(ctx *Context) *cobra.Command {
+	var secure bool
+	var count int
+	var policy policyFlags
+	var cmd = &cobra.Command{
+		Use:   "generate",
+		Short: "generates passwords without storing them",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx.NoWriteBack = true
+			if count < 1 {
+				return fmt.Errorf("count must be positive, got %d", count)
+			}
+
+			var entropy float64
+			for i := 0; i < count; i++ {
+				var password string
+				var err error
+				password, entropy, err = generateFlagsPassword(cmd, policy, "", secure)
+				if err != nil {
+					return fmt.Errorf("generateFlagsPassword() failed: %s", err)
+				}
+
+				fmt.Fprintln(cmd.OutOrStdout(), password)
+			}
+
+			if entropy > 0 {
+				// Keep stdout to just the passwords, so it's easy to use from scripts.
+				fmt.Fprintf(cmd.ErrOrStderr(), "Entropy: %.1f bits\n", entropy)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVarP(&secure, "secure", "y", false, `increase number of symbols from 0 to 3 (default: false)`)
+	cmd.Flags().IntVarP(&count, "count", "c", 1, `number of passwords to generate`)
+	addPolicyFlags(cmd, &policy)
+
+	return cmd
+}
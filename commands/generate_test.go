@@ -0,0 +1,124 @@
+// Copyright 2026 Miklos Vajna
+//
+// SPDX-License-Identifier: MIT
+
+package commands
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+)
+
+// UseNoDatabaseForTesting makes any access to the database fail.
+func UseNoDatabaseForTesting(t *testing.T) {
+	oldOpenDatabase := OpenDatabase
+	OpenDatabase = func(ctx *Context) error {
+		return errors.New("OpenDatabase() is not expected to be called")
+	}
+	t.Cleanup(func() { OpenDatabase = oldOpenDatabase })
+	oldCloseDatabase := CloseDatabase
+	CloseDatabase = func(ctx *Context) error {
+		return errors.New("CloseDatabase() is not expected to be called")
+	}
+	t.Cleanup(func() { CloseDatabase = oldCloseDatabase })
+}
+
+func TestGenerate(t *testing.T) {
+	CreateContextForTesting(t)
+	UseNoDatabaseForTesting(t)
+	actualArgs := UseGeneratePasswordRecorderForTesting(t)
+	os.Args = []string{"", "generate", "-c", "2", "--length", "20", "-y"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	expectedArgs := "length: 20, digits: 3, symbols: 3, no upper: false, allow repeat: false"
+	if *actualArgs != expectedArgs {
+		t.Fatalf("actualArgs = %q, want %q", *actualArgs, expectedArgs)
+	}
+	expectedBuf := "output-from-pwgen\noutput-from-pwgen\n"
+	if outBuf.String() != expectedBuf {
+		t.Fatalf("Main() output is %q, want %q", outBuf.String(), expectedBuf)
+	}
+}
+
+func TestGeneratePolicy(t *testing.T) {
+	CreateContextForTesting(t)
+	UseNoDatabaseForTesting(t)
+	actualArgs := UseGeneratePasswordRecorderForTesting(t)
+	UseConfigForTesting(t, `{"Policies": {"mypolicy": {"Length": 8, "NoUpper": true}}}`)
+	os.Args = []string{"", "generate", "--policy", "mypolicy"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	expectedArgs := "length: 8, digits: 3, symbols: 0, no upper: true, allow repeat: false"
+	if *actualArgs != expectedArgs {
+		t.Fatalf("actualArgs = %q, want %q", *actualArgs, expectedArgs)
+	}
+}
+
+func TestGenerateCommandPassphrase(t *testing.T) {
+	CreateContextForTesting(t)
+	UseNoDatabaseForTesting(t)
+	UseRandIntForTesting(t)
+	os.Args = []string{"", "generate", "--passphrase", "--words", "3", "-c", "2"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	// The entropy goes to stderr, only the passphrases are on stdout.
+	expectedBuf := "abacus-abdomen-abdominal\nabide-abiding-ability\n"
+	if outBuf.String() != expectedBuf {
+		t.Fatalf("Main() output is %q, want %q", outBuf.String(), expectedBuf)
+	}
+}
+
+// Generate fails because the count is not positive.
+func TestGenerateBadCount(t *testing.T) {
+	CreateContextForTesting(t)
+	UseNoDatabaseForTesting(t)
+	os.Args = []string{"", "generate", "-c", "0"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 1
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q", actualRet, expectedRet)
+	}
+}
+
+// Generate fails because the policy is not in the config.
+func TestGenerateBadPolicy(t *testing.T) {
+	CreateContextForTesting(t)
+	UseNoDatabaseForTesting(t)
+	os.Args = []string{"", "generate", "--policy", "nosuchpolicy"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 1
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q", actualRet, expectedRet)
+	}
+}
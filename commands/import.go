@@ -5,14 +5,24 @@
 package commands
 
 import (
+	"bufio"
 	"bytes"
+	"compress/gzip"
+	"database/sql"
+	"encoding/csv"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
+	"net/url"
 	"os"
 	"os/user"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
+	"golang.org/x/text/encoding/charmap"
 )
 
 // XMLPassword is the 4th <node> element from cpm's XML database.
@@ -49,85 +59,584 @@ type XMLMachines struct {
 	Machines []XMLMachine `xml:"node"`
 }
 
-func newImportCommand(ctx *Context) *cobra.Command {
-	var cmd = &cobra.Command{
-		Use:   "import",
-		Short: "imports an old XML database",
-		RunE: func(cmd *cobra.Command, args []string) error {
-			// Decrypt and uncompress ~/.cpmdb to a temp file.
-			usr, err := user.Current()
+// getXMLDatabasePath returns the path of the old XML database.
+func getXMLDatabasePath() (string, error) {
+	usr, err := user.Current()
+	if err != nil {
+		return "", fmt.Errorf("user.Current() failed: %s", err)
+	}
+
+	return usr.HomeDir + "/.cpmdb", nil
+}
+
+// decryptFile decrypts `path` with gpg, without writing the result to disk.
+func decryptFile(path string) ([]byte, error) {
+	cmd := Command("gpg", "--decrypt", "--quiet", path)
+	cmd.Stdin = os.Stdin
+	cmd.Stderr = os.Stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("cmd.Output(gpg) failed: %s", err)
+	}
+
+	return output, nil
+}
+
+// xmlCharsetReader decodes the non-UTF-8 encodings of old XML databases.
+func xmlCharsetReader(label string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(label) {
+	case "iso-8859-1", "latin1":
+		return charmap.ISO8859_1.NewDecoder().Reader(input), nil
+	default:
+		return nil, fmt.Errorf("unsupported encoding: '%s'", label)
+	}
+}
+
+// parseXMLDatabase parses the decrypted old XML database, which is compressed.
+func parseXMLDatabase(content []byte) ([]passwordRow, error) {
+	gzipReader, err := gzip.NewReader(bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("gzip.NewReader() failed: %s", err)
+	}
+
+	decoder := xml.NewDecoder(gzipReader)
+	decoder.CharsetReader = xmlCharsetReader
+	var machines XMLMachines
+	err = decoder.Decode(&machines)
+	if err != nil {
+		return nil, fmt.Errorf("decoder.Decode() failed: %s", err)
+	}
+
+	var rows []passwordRow
+	now := Now().Format(time.RFC3339)
+	for _, machine := range machines.Machines {
+		for _, service := range machine.Services {
+			for _, user := range service.Users {
+				for _, password := range user.Passwords {
+					row := passwordRow{
+						Machine:      machine.Label,
+						Service:      service.Label,
+						User:         user.Label,
+						Password:     password.Label,
+						PasswordType: PasswordTypePlain,
+						Created:      now,
+						Modified:     now,
+					}
+					if password.Totp == "true" {
+						row.PasswordType = PasswordTypeTotp
+					}
+					rows = append(rows, row)
+				}
+			}
+		}
+	}
+
+	return rows, nil
+}
+
+// ImportFormat is an enum of possible import file formats.
+type ImportFormat string
+
+const (
+	// ImportFormatXML is the old XML database of cpm.
+	ImportFormatXML ImportFormat = "xml"
+	// ImportFormatJSON is the output of 'cpm export'.
+	ImportFormatJSON ImportFormat = "json"
+	// ImportFormatKdbx is a KeePass or KeePassXC database, in the KDBX 4 format.
+	ImportFormatKdbx ImportFormat = "kdbx"
+	// ImportFormatKeepassXML is an XML export from KeePass or KeePassXC.
+	ImportFormatKeepassXML ImportFormat = "keepass-xml"
+	// ImportFormatBitwardenJSON is an unencrypted JSON export from Bitwarden.
+	ImportFormatBitwardenJSON ImportFormat = "bitwarden-json"
+	// ImportFormatOnePassword1pux is a 1PUX export from 1Password.
+	ImportFormatOnePassword1pux ImportFormat = "1password-1pux"
+	// ImportFormatOnePasswordCSV is a CSV export from 1Password.
+	ImportFormatOnePasswordCSV ImportFormat = "1password-csv"
+	// ImportFormatPass is the password store directory of pass.
+	ImportFormatPass ImportFormat = "pass"
+	// ImportFormatBrowserCSV is the saved logins, exported from Firefox or Chromium.
+	ImportFormatBrowserCSV ImportFormat = "browser-csv"
+)
+
+func (f *ImportFormat) String() string {
+	return string(*f)
+}
+
+// Set sets the value of `f` from `v`.
+func (f *ImportFormat) Set(v string) error {
+	switch v {
+	case "xml", "json", "kdbx", "keepass-xml", "bitwarden-json", "1password-1pux", "1password-csv", "pass", "browser-csv":
+		*f = ImportFormat(v)
+		return nil
+	default:
+		return errors.New(`must be one of "xml", "json", "kdbx", "keepass-xml", "bitwarden-json", "1password-1pux", "1password-csv", "pass", or "browser-csv"`)
+	}
+}
+
+// Type returns the type of `f` as a string.
+func (f *ImportFormat) Type() string {
+	return "ImportFormat"
+}
+
+// ConflictStrategy is an enum of possible ways to handle an imported password which conflicts with
+// an existing one.
+type ConflictStrategy string
+
+const (
+	// ConflictStrategyFail aborts the import.
+	ConflictStrategyFail ConflictStrategy = "fail"
+	// ConflictStrategySkip keeps the existing password.
+	ConflictStrategySkip ConflictStrategy = "skip"
+	// ConflictStrategyOverwrite replaces the existing password.
+	ConflictStrategyOverwrite ConflictStrategy = "overwrite"
+	// ConflictStrategyRename keeps both: a new ID and a new user for the imported password.
+	ConflictStrategyRename ConflictStrategy = "rename"
+)
+
+func (s *ConflictStrategy) String() string {
+	return string(*s)
+}
+
+// Set sets the value of `s` from `v`.
+func (s *ConflictStrategy) Set(v string) error {
+	switch v {
+	case "fail", "skip", "overwrite", "rename":
+		*s = ConflictStrategy(v)
+		return nil
+	default:
+		return errors.New(`must be one of "fail", "skip", "overwrite", or "rename"`)
+	}
+}
+
+// Type returns the type of `s` as a string.
+func (s *ConflictStrategy) Type() string {
+	return "ConflictStrategy"
+}
+
+// importReport counts what happened to the imported passwords.
+type importReport struct {
+	created     int
+	overwritten int
+	renamed     int
+	skipped     int
+}
+
+// findPassword returns the ID of the password with the given machine, service, user and type, or 0.
+func findPassword(transaction *sql.Tx, machine, service, user string, passwordType PasswordType) (int, error) {
+	var id int
+	row := transaction.QueryRow("select id from passwords where machine=? and service=? and user=? and type=?", machine, service, user, passwordType)
+	err := row.Scan(&id)
+	if err != nil && err != sql.ErrNoRows {
+		return 0, fmt.Errorf("row.Scan() failed: %s", err)
+	}
+
+	return id, nil
+}
+
+// importPassword inserts a single imported password, handling conflicts with existing passwords
+// according to `strategy`. An ID of 0 means the imported password has no ID.
+func importPassword(transaction *sql.Tx, row passwordRow, strategy ConflictStrategy, report *importReport) error {
+	var passwordType PasswordType
+	err := passwordType.Set(string(row.PasswordType))
+	if err != nil {
+		return fmt.Errorf("invalid password type of machine '%s', user '%s': %s", row.Machine, row.User, err)
+	}
+
+	if passwordType == PasswordTypeRecoveryCodes {
+		_, err = decodeRecoveryCodes(row.Password)
+		if err != nil {
+			return fmt.Errorf("invalid recovery codes of machine '%s', user '%s': %s", row.Machine, row.User, err)
+		}
+	}
+
+	idConflict := false
+	if row.ID > 0 {
+		var count int
+		err = transaction.QueryRow("select count(*) from passwords where id=?", row.ID).Scan(&count)
+		if err != nil {
+			return fmt.Errorf("row.Scan() failed: %s", err)
+		}
+		idConflict = count > 0
+	}
+	duplicate, err := findPassword(transaction, row.Machine, row.Service, row.User, row.PasswordType)
+	if err != nil {
+		return fmt.Errorf("findPassword() failed: %s", err)
+	}
+
+	if idConflict || duplicate > 0 {
+		switch strategy {
+		case ConflictStrategySkip:
+			report.skipped++
+			return nil
+		case ConflictStrategyOverwrite:
+			// Only a password with the same machine, service, user and type is replaced, an ID
+			// which belongs to a different password is not reused.
+			if idConflict && row.ID != duplicate {
+				row.ID = 0
+			}
+			if duplicate == 0 {
+				report.created++
+				break
+			}
+
+			_, err = deletePassword(transaction, strconv.Itoa(duplicate))
 			if err != nil {
-				return fmt.Errorf("user.Current() failed: %s", err)
+				return fmt.Errorf("deletePassword() failed: %s", err)
 			}
+			report.overwritten++
+		case ConflictStrategyRename:
+			if idConflict {
+				row.ID = 0
+			}
+			user := row.User
+			for i := 2; duplicate > 0; i++ {
+				row.User = fmt.Sprintf("%s (%d)", user, i)
+				duplicate, err = findPassword(transaction, row.Machine, row.Service, row.User, row.PasswordType)
+				if err != nil {
+					return fmt.Errorf("findPassword() failed: %s", err)
+				}
+			}
+			report.renamed++
+		default:
+			return fmt.Errorf("password of machine '%s', service '%s', user '%s', type '%s' conflicts with an existing password", row.Machine, row.Service, row.User, row.PasswordType)
+		}
+	} else {
+		report.created++
+	}
+
+	// Let the database pick the ID if there is none.
+	var id any
+	if row.ID > 0 {
+		id = row.ID
+	}
+	query, err := transaction.Prepare("insert into passwords (id, machine, service, user, password, type, archived, created, modified, expires, rotate_every, notes) values(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
+	if err != nil {
+		return fmt.Errorf("db.Prepare() failed: %s", err)
+	}
+
+	_, err = query.Exec(id, row.Machine, row.Service, row.User, row.Password, row.PasswordType, row.Archived, row.Created, row.Modified, row.Expires, row.RotateEvery, row.Notes)
+	if err != nil {
+		return fmt.Errorf("query.Exec() failed: %s", err)
+	}
 
-			encryptedPath := usr.HomeDir + "/.cpmdb"
-			decryptedFile, err := os.CreateTemp("", "cpm")
+	return nil
+}
+
+// importPasswords inserts imported passwords, keeping their timestamps.
+func importPasswords(transaction *sql.Tx, rows []passwordRow, strategy ConflictStrategy) (importReport, error) {
+	var report importReport
+	for _, row := range rows {
+		err := importPassword(transaction, row, strategy, &report)
+		if err != nil {
+			return report, fmt.Errorf("importPassword() failed: %s", err)
+		}
+	}
+
+	return report, nil
+}
+
+// getURLHost returns the host of `link`, which may lack a scheme, or an empty string.
+func getURLHost(link string) string {
+	if !strings.Contains(link, "://") {
+		link = "https://" + link
+	}
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+
+	return u.Hostname()
+}
+
+// formatImportedTime formats a time from an imported file the way the database stores it.
+func formatImportedTime(t time.Time) string {
+	return t.In(Now().Location()).Format(time.RFC3339)
+}
+
+// getImportedRows returns a plain and / or a TOTP password for an imported login, based on `row`.
+func getImportedRows(row passwordRow, password, totp string) []passwordRow {
+	var rows []passwordRow
+	if len(password) > 0 || len(totp) == 0 {
+		plain := row
+		plain.Password = password
+		plain.PasswordType = PasswordTypePlain
+		rows = append(rows, plain)
+	}
+	if len(totp) > 0 {
+		row.Password = totp
+		row.PasswordType = PasswordTypeTotp
+		rows = append(rows, row)
+	}
+
+	return rows
+}
+
+// csvTable is a parsed CSV file, its columns are found by their names in the header.
+type csvTable struct {
+	columns map[string]int
+	records [][]string
+}
+
+// parseCSVTable parses a CSV file with a header, which has to contain the `required` columns.
+func parseCSVTable(content []byte, required ...string) (csvTable, error) {
+	var table csvTable
+	records, err := csv.NewReader(bytes.NewReader(content)).ReadAll()
+	if err != nil {
+		return table, fmt.Errorf("ReadAll() failed: %s", err)
+	}
+
+	if len(records) == 0 {
+		return table, errors.New("no header")
+	}
+
+	table.columns = map[string]int{}
+	for i, name := range records[0] {
+		table.columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range required {
+		if _, ok := table.columns[name]; !ok {
+			return table, fmt.Errorf("no '%s' column", name)
+		}
+	}
+	table.records = records[1:]
+
+	return table, nil
+}
+
+// get returns the value of the first column of `record`, which exists with one of `names`.
+func (t *csvTable) get(record []string, names ...string) string {
+	for _, name := range names {
+		if i, ok := t.columns[name]; ok && i < len(record) {
+			return record[i]
+		}
+	}
+
+	return ""
+}
+
+// readImportFile reads the file to be imported, "-" is the standard input.
+func readImportFile(cmd *cobra.Command, path string) ([]byte, error) {
+	if path == "-" {
+		content, err := io.ReadAll(cmd.InOrStdin())
+		if err != nil {
+			return nil, fmt.Errorf("io.ReadAll() failed: %s", err)
+		}
+		return content, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("os.ReadFile() failed: %s", err)
+	}
+	return content, nil
+}
+
+// readMasterPassword reads a line from the standard input, without echoing it in case that's a
+// terminal.
+func readMasterPassword(cmd *cobra.Command) (string, error) {
+	if file, ok := cmd.InOrStdin().(*os.File); ok && IsTerminal(int(file.Fd())) {
+		password, err := ReadPassword(int(file.Fd()))
+		// The newline is not echoed either.
+		fmt.Fprintln(cmd.OutOrStdout())
+		if err != nil {
+			return "", fmt.Errorf("ReadPassword() failed: %s", err)
+		}
+
+		return string(password), nil
+	}
+
+	reader := bufio.NewReader(cmd.InOrStdin())
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return "", fmt.Errorf("ReadString() failed: %s", err)
+	}
+
+	return strings.TrimSuffix(line, "\n"), nil
+}
+
+// parseImportFile parses the passwords from the content of an imported file. Items which can't be
+// imported are described in the returned unmapped list.
+func parseImportFile(cmd *cobra.Command, content []byte, format ImportFormat, keyfilePath string) ([]passwordRow, []string, error) {
+	switch format {
+	case ImportFormatKdbx:
+		var keyfile []byte
+		if len(keyfilePath) > 0 {
+			keyfileContent, err := os.ReadFile(keyfilePath)
 			if err != nil {
-				return fmt.Errorf("os.CreateTemp() failed: %s", err)
+				return nil, nil, fmt.Errorf("os.ReadFile() failed: %s", err)
 			}
 
-			decryptedPath := decryptedFile.Name()
-			defer Remove(decryptedPath)
+			keyfile, err = readKeepassKeyfile(keyfileContent)
+			if err != nil {
+				return nil, nil, fmt.Errorf("readKeepassKeyfile() failed: %s", err)
+			}
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "Master password: ")
+		password, err := readMasterPassword(cmd)
+		if err != nil {
+			return nil, nil, fmt.Errorf("readMasterPassword() failed: %s", err)
+		}
+		if len(password) == 0 && len(keyfile) == 0 {
+			return nil, nil, fmt.Errorf("either a master password or a keyfile is needed")
+		}
 
-			err = runCommand("gpg", "--decrypt", "-a", "-o", decryptedPath+".gz", encryptedPath)
+		rows, err := parseKdbx(content, password, keyfile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("parseKdbx() failed: %s", err)
+		}
+		return rows, nil, nil
+	case ImportFormatKeepassXML:
+		rows, err := parseKeepassXML(content, nil)
+		if err != nil {
+			return nil, nil, fmt.Errorf("parseKeepassXML() failed: %s", err)
+		}
+		return rows, nil, nil
+	case ImportFormatBitwardenJSON:
+		rows, unmapped, err := parseBitwardenJSON(content)
+		if err != nil {
+			return nil, nil, fmt.Errorf("parseBitwardenJSON() failed: %s", err)
+		}
+		return rows, unmapped, nil
+	case ImportFormatOnePassword1pux:
+		rows, unmapped, err := parseOnePassword1pux(content)
+		if err != nil {
+			return nil, nil, fmt.Errorf("parseOnePassword1pux() failed: %s", err)
+		}
+		return rows, unmapped, nil
+	case ImportFormatOnePasswordCSV:
+		rows, err := parseOnePasswordCSV(content)
+		if err != nil {
+			return nil, nil, fmt.Errorf("parseOnePasswordCSV() failed: %s", err)
+		}
+		return rows, nil, nil
+	case ImportFormatBrowserCSV:
+		rows, err := parseBrowserCSV(content)
+		if err != nil {
+			return nil, nil, fmt.Errorf("parseBrowserCSV() failed: %s", err)
+		}
+		return rows, nil, nil
+	default:
+		rows, err := parseJSONExport(content)
+		if err != nil {
+			return nil, nil, fmt.Errorf("parseJSONExport() failed: %s", err)
+		}
+		return rows, nil, nil
+	}
+}
+
+// readImportedPasswords reads the passwords to be imported from the file or directory in `args`.
+func readImportedPasswords(cmd *cobra.Command, args []string, format ImportFormat, keyfilePath string) ([]passwordRow, []string, error) {
+	if format == ImportFormatXML {
+		var path string
+		if len(args) > 0 {
+			path = args[0]
+		} else {
+			var err error
+			path, err = getXMLDatabasePath()
 			if err != nil {
-				return fmt.Errorf("runCommand() failed: %s", err)
+				return nil, nil, fmt.Errorf("getXMLDatabasePath() failed: %s", err)
 			}
+		}
+
+		content, err := decryptFile(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("decryptFile() failed: %s", err)
+		}
+
+		rows, err := parseXMLDatabase(content)
+		if err != nil {
+			return nil, nil, fmt.Errorf("parseXMLDatabase() failed: %s", err)
+		}
+		return rows, nil, nil
+	}
 
-			err = runCommand("gunzip", "--force", decryptedPath+".gz")
+	if format == ImportFormatPass {
+		var dir string
+		if len(args) > 0 {
+			dir = args[0]
+		} else {
+			var err error
+			dir, err = getPasswordStorePath()
 			if err != nil {
-				return fmt.Errorf("runCommand() failed: %s", err)
+				return nil, nil, fmt.Errorf("getPasswordStorePath() failed: %s", err)
 			}
+		}
 
-			// Parse the XML.
-			xmlFile, err := os.Open(decryptedPath)
+		rows, err := readPasswordStore(dir)
+		if err != nil {
+			return nil, nil, fmt.Errorf("readPasswordStore() failed: %s", err)
+		}
+		return rows, nil, nil
+	}
+
+	if len(args) == 0 {
+		return nil, nil, fmt.Errorf("the %s format needs a file to import, or '-' for the standard input", format)
+	}
+
+	content, err := readImportFile(cmd, args[0])
+	if err != nil {
+		return nil, nil, fmt.Errorf("readImportFile() failed: %s", err)
+	}
+
+	rows, unmapped, err := parseImportFile(cmd, content, format, keyfilePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parseImportFile() failed: %s", err)
+	}
+	return rows, unmapped, nil
+}
+
+func newImportCommand(ctx *Context) *cobra.Command {
+	var dryRun bool
+	var keyfilePath string
+	var format ImportFormat = ImportFormatXML
+	var strategy ConflictStrategy = ConflictStrategyFail
+	var cmd = &cobra.Command{
+		Use:   "import [FILE|DIR]",
+		Short: "imports passwords from an old XML database, a JSON export or other password managers",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rows, unmapped, err := readImportedPasswords(cmd, args, format, keyfilePath)
 			if err != nil {
-				return fmt.Errorf("os.Open(decryptedPath) failed: %s", err)
+				return fmt.Errorf("readImportedPasswords() failed: %s", err)
 			}
-			defer xmlFile.Close()
 
-			xmlBytes, err := io.ReadAll(xmlFile)
+			transaction, err := ctx.Database.Begin()
 			if err != nil {
-				return fmt.Errorf("ioutil.ReadAll(xmlFile) failed: %s", err)
+				return fmt.Errorf("db.Begin() failed: %s", err)
 			}
 
-			// Avoid 'encoding "ISO-8859-1" declared but Decoder.CharsetReader is nil'.
-			xmlBytes = bytes.ReplaceAll(xmlBytes, []byte(`encoding="ISO-8859-1"`), []byte(`encoding="UTF-8"`))
+			defer transaction.Rollback()
 
-			var machines XMLMachines
-			err = xml.Unmarshal(xmlBytes, &machines)
+			if (format == ImportFormatXML || format == ImportFormatBrowserCSV) && !cmd.Flags().Changed("on-conflict") {
+				// Importing the old database again only imports new passwords. Browsers may
+				// export the same login multiple times, e.g. for http and https.
+				strategy = ConflictStrategySkip
+			}
+			report, err := importPasswords(transaction, rows, strategy)
 			if err != nil {
-				return fmt.Errorf("xml.Unmarshal() failed: %s", err)
-			}
-
-			// Import the parsed data.
-			for _, machine := range machines.Machines {
-				machineLabel := machine.Label
-				for _, service := range machine.Services {
-					serviceLabel := service.Label
-					for _, user := range service.Users {
-						userLabel := user.Label
-						for _, password := range user.Passwords {
-							passwordLabel := password.Label
-							var passwordType PasswordType
-							if password.Totp == "true" {
-								passwordType = "totp"
-							} else {
-								passwordType = "plain"
-							}
-
-							_, err = createPassword(ctx, machineLabel, serviceLabel, userLabel, passwordLabel, passwordType /*secure=*/, false)
-							if err != nil {
-								return fmt.Errorf("createPassword(machine='%s', service='%s', user='%s', type='%s') failed: %s", machineLabel, serviceLabel, userLabel, passwordType, err)
-							}
-						}
-					}
-				}
+				return fmt.Errorf("importPasswords() failed: %s", err)
 			}
 
+			verb := "Imported"
+			if dryRun {
+				verb = "Would import"
+				ctx.NoWriteBack = true
+			} else {
+				transaction.Commit()
+			}
+			imported := report.created + report.overwritten + report.renamed
+			fmt.Fprintf(cmd.OutOrStdout(), "%s %v passwords: %v created, %v overwritten, %v renamed, %v skipped\n", verb, imported, report.created, report.overwritten, report.renamed, report.skipped)
+			for _, item := range unmapped {
+				fmt.Fprintf(cmd.OutOrStdout(), "Not imported, unsupported: %s\n", item)
+			}
 			return nil
 		},
 	}
+	cmd.Flags().BoolVarP(&dryRun, "dry-run", "n", false, `do everything except actually perform the database action (default: false)`)
+	cmd.Flags().VarP(&format, "format", "f", `format of the imported file ("xml", "json", "kdbx", "keepass-xml", "bitwarden-json", "1password-1pux", "1password-csv", "pass" or "browser-csv"; default: "xml", which imports ~/.cpmdb without a file)`)
+	cmd.Flags().StringVarP(&keyfilePath, "keyfile", "", "", `keyfile of the kdbx database, in addition to or instead of the master password (default: none)`)
+	cmd.Flags().VarP(&strategy, "on-conflict", "", `what to do if an imported password has the ID, or the machine, service, user and type of an existing password ("fail", "skip", "overwrite" or "rename"; default: "fail", "skip" for xml and browser-csv)`)
 
 	return cmd
 }
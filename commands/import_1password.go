@@ -0,0 +1,187 @@
+// Copyright 2026 Miklos Vajna
+//
+// SPDX-License-Identifier: MIT
+
+package commands
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+const (
+	// onePasswordLogin is the category of login items.
+	onePasswordLogin = "001"
+	// onePasswordPassword is the category of password items, which have no user.
+	onePasswordPassword = "005"
+)
+
+// getOnePasswordCategory names a category of 1Password, which is not a login or a password.
+func getOnePasswordCategory(category string) string {
+	switch category {
+	case "002":
+		return "credit card"
+	case "003":
+		return "secure note"
+	case "004":
+		return "identity"
+	case "006":
+		return "document"
+	case "114":
+		return "SSH key"
+	default:
+		return fmt.Sprintf("category %s", category)
+	}
+}
+
+// onePasswordItem is a single item in the export.data file of a 1PUX export.
+type onePasswordItem struct {
+	CreatedAt    int64  `json:"createdAt"`
+	UpdatedAt    int64  `json:"updatedAt"`
+	State        string `json:"state"`
+	CategoryUUID string `json:"categoryUuid"`
+	Details      struct {
+		LoginFields []struct {
+			Value       string `json:"value"`
+			Designation string `json:"designation"`
+		} `json:"loginFields"`
+		NotesPlain string `json:"notesPlain"`
+		Sections   []struct {
+			Fields []struct {
+				Value struct {
+					Totp string `json:"totp"`
+				} `json:"value"`
+			} `json:"fields"`
+		} `json:"sections"`
+		Password string `json:"password"`
+	} `json:"details"`
+	Overview struct {
+		Title string `json:"title"`
+		URL   string `json:"url"`
+	} `json:"overview"`
+}
+
+// onePasswordExport is the export.data file of a 1PUX export.
+type onePasswordExport struct {
+	Accounts []struct {
+		Vaults []struct {
+			Items []onePasswordItem `json:"items"`
+		} `json:"vaults"`
+	} `json:"accounts"`
+}
+
+// getOnePasswordRows turns a login or password item into passwords.
+func getOnePasswordRows(item *onePasswordItem) []passwordRow {
+	row := passwordRow{
+		Machine:  item.Overview.Title,
+		Service:  "http",
+		Archived: item.State == "archived",
+		Notes:    item.Details.NotesPlain,
+	}
+	host := getURLHost(item.Overview.URL)
+	if len(host) > 0 {
+		row.Machine = host
+	}
+	if item.CreatedAt > 0 {
+		row.Created = formatImportedTime(time.Unix(item.CreatedAt, 0))
+	}
+	if item.UpdatedAt > 0 {
+		row.Modified = formatImportedTime(time.Unix(item.UpdatedAt, 0))
+	}
+
+	password := item.Details.Password
+	for _, field := range item.Details.LoginFields {
+		switch field.Designation {
+		case "username":
+			row.User = field.Value
+		case "password":
+			password = field.Value
+		}
+	}
+	var totp string
+	for _, section := range item.Details.Sections {
+		for _, field := range section.Fields {
+			if len(field.Value.Totp) > 0 {
+				totp = field.Value.Totp
+			}
+		}
+	}
+
+	return getImportedRows(row, password, totp)
+}
+
+// parseOnePassword1pux parses a 1PUX export, which is a zip archive. Items which are not logins or
+// passwords are returned as unmapped.
+func parseOnePassword1pux(content []byte) ([]passwordRow, []string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return nil, nil, fmt.Errorf("zip.NewReader() failed: %s", err)
+	}
+
+	file, err := archive.Open("export.data")
+	if err != nil {
+		return nil, nil, fmt.Errorf("archive.Open() failed: %s", err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, nil, fmt.Errorf("io.ReadAll() failed: %s", err)
+	}
+
+	var export onePasswordExport
+	err = json.Unmarshal(data, &export)
+	if err != nil {
+		return nil, nil, fmt.Errorf("json.Unmarshal() failed: %s", err)
+	}
+
+	var rows []passwordRow
+	var unmapped []string
+	for _, account := range export.Accounts {
+		for _, vault := range account.Vaults {
+			for i := range vault.Items {
+				item := &vault.Items[i]
+				if item.CategoryUUID != onePasswordLogin && item.CategoryUUID != onePasswordPassword {
+					unmapped = append(unmapped, fmt.Sprintf("%s '%s'", getOnePasswordCategory(item.CategoryUUID), item.Overview.Title))
+					continue
+				}
+
+				rows = append(rows, getOnePasswordRows(item)...)
+			}
+		}
+	}
+
+	return rows, unmapped, nil
+}
+
+// parseOnePasswordCSV parses a 1Password CSV export.
+func parseOnePasswordCSV(content []byte) ([]passwordRow, error) {
+	table, err := parseCSVTable(content, "title", "username", "password")
+	if err != nil {
+		return nil, fmt.Errorf("parseCSVTable() failed: %s", err)
+	}
+
+	var rows []passwordRow
+	for _, record := range table.records {
+		archived, _ := strconv.ParseBool(table.get(record, "archived"))
+		row := passwordRow{
+			Machine:  table.get(record, "title"),
+			Service:  "http",
+			User:     table.get(record, "username"),
+			Archived: archived,
+			Notes:    table.get(record, "notes", "notesplain"),
+		}
+		host := getURLHost(table.get(record, "url", "website"))
+		if len(host) > 0 {
+			row.Machine = host
+		}
+		rows = append(rows, getImportedRows(row, table.get(record, "password"), table.get(record, "otpauth", "one-time password"))...)
+	}
+
+	return rows, nil
+}
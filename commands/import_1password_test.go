@@ -0,0 +1,218 @@
+// Copyright 2026 Miklos Vajna
+//
+// SPDX-License-Identifier: MIT
+
+package commands
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// write1puxForTesting creates a 1PUX archive with the given files.
+func write1puxForTesting(t *testing.T, files map[string]string) string {
+	buf := new(bytes.Buffer)
+	writer := zip.NewWriter(buf)
+	for name, content := range files {
+		file, err := writer.Create(name)
+		if err != nil {
+			t.Fatalf("writer.Create() = %q, want nil", err)
+		}
+		file.Write([]byte(content))
+	}
+	writer.Close()
+	path := filepath.Join(t.TempDir(), "export.1pux")
+	err := os.WriteFile(path, buf.Bytes(), 0600)
+	if err != nil {
+		t.Fatalf("os.WriteFile() failed: %s", err)
+	}
+	return path
+}
+
+func TestImportOnePassword1pux(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	path := write1puxForTesting(t, map[string]string{
+		"export.attributes": `{"version": 3}`,
+		"export.data": `{
+  "accounts": [{
+    "attrs": {"accountName": "Alice"},
+    "vaults": [{
+      "attrs": {"name": "Personal"},
+      "items": [
+        {
+          "createdAt": 1588320000,
+          "updatedAt": 1588406400,
+          "state": "active",
+          "categoryUuid": "001",
+          "details": {
+            "loginFields": [
+              {"value": "alice", "name": "username", "fieldType": "T", "designation": "username"},
+              {"value": "alicepassword", "name": "password", "fieldType": "P", "designation": "password"}
+            ],
+            "notesPlain": "PIN: 1234",
+            "sections": [{"title": "", "fields": [{"title": "one-time password", "value": {"totp": "JBSWY3DPEHPK3PXP"}}]}]
+          },
+          "overview": {"title": "GitHub", "url": "https://github.com/login"}
+        },
+        {
+          "state": "archived",
+          "categoryUuid": "005",
+          "details": {"password": "routerpassword", "sections": []},
+          "overview": {"title": "router", "url": ""}
+        },
+        {"categoryUuid": "002", "details": {}, "overview": {"title": "Visa"}},
+        {"categoryUuid": "003", "details": {}, "overview": {"title": "Wifi"}},
+        {"categoryUuid": "004", "details": {}, "overview": {"title": "Me"}},
+        {"categoryUuid": "006", "details": {}, "overview": {"title": "Contract"}},
+        {"categoryUuid": "114", "details": {}, "overview": {"title": "Server"}},
+        {"categoryUuid": "110", "details": {}, "overview": {"title": "Server software"}}
+      ]
+    }]
+  }]
+}`,
+	})
+	os.Args = []string{"", "import", "-f", "1password-1pux", path}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	expectedBuf := `Imported 3 passwords: 3 created, 0 overwritten, 0 renamed, 0 skipped
+Not imported, unsupported: credit card 'Visa'
+Not imported, unsupported: secure note 'Wifi'
+Not imported, unsupported: identity 'Me'
+Not imported, unsupported: document 'Contract'
+Not imported, unsupported: SSH key 'Server'
+Not imported, unsupported: category 110 'Server software'
+`
+	if outBuf.String() != expectedBuf {
+		t.Fatalf("Main() output is %q, want %q", outBuf.String(), expectedBuf)
+	}
+	results, err := readPasswords(ctx.Database, searchOptions{verbose: true})
+	if err != nil {
+		t.Fatalf("readPasswords() err = %q, want nil", err)
+	}
+	expected := []string{
+		"id:        1, machine: github.com, service: http, user: alice, password type: plain, password: alicepassword, archived: false, created: 2020-05-01 10:00, modified: 2020-05-02 10:00, notes: PIN: 1234, strength: 0/4",
+		"id:        2, machine: github.com, service: http, user: alice, password type: TOTP shared secret, password: JBSWY3DPEHPK3PXP, archived: false, created: 2020-05-01 10:00, modified: 2020-05-02 10:00, notes: PIN: 1234",
+		"id:        3, machine: router, service: http, user: , password type: plain, password: routerpassword, archived: true, strength: 0/4",
+	}
+	if !slices.Equal(results, expected) {
+		t.Fatalf("results = %q, want %q", results, expected)
+	}
+}
+
+// Import fails because the input is not a valid 1PUX archive, nothing is imported.
+func TestImportOnePassword1puxInvalid(t *testing.T) {
+	CreateContextForTesting(t)
+	paths := []string{
+		write1puxForTesting(t, map[string]string{"export.attributes": `{"version": 3}`}),
+		write1puxForTesting(t, map[string]string{"export.data": `{`}),
+		filepath.Join(t.TempDir(), "export.1pux"),
+	}
+	err := os.WriteFile(paths[2], []byte("not a zip"), 0600)
+	if err != nil {
+		t.Fatalf("os.WriteFile() failed: %s", err)
+	}
+	for _, path := range paths {
+		os.Args = []string{"", "import", "-f", "1password-1pux", path}
+		inBuf := new(bytes.Buffer)
+		outBuf := new(bytes.Buffer)
+
+		actualRet := Main(inBuf, outBuf)
+
+		expectedRet := 1
+		if actualRet != expectedRet {
+			t.Fatalf("Main() = %q, want %q, path is %q", actualRet, expectedRet, path)
+		}
+	}
+}
+
+func TestImportOnePasswordCSV(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	os.Args = []string{"", "import", "-f", "1password-csv", "-"}
+	inBuf := bytes.NewBufferString(`Title,Url,Username,Password,OTPAuth,Favorite,Archived,Tags,Notes
+GitHub,https://github.com/login,alice,alicepassword,otpauth://totp/GitHub:alice?secret=JBSWY3DPEHPK3PXP,false,false,,"PIN: 1234"
+router,,admin,routerpassword,,false,true,,
+`)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	results, err := readPasswords(ctx.Database, searchOptions{verbose: true})
+	if err != nil {
+		t.Fatalf("readPasswords() err = %q, want nil", err)
+	}
+	expected := []string{
+		"id:        1, machine: github.com, service: http, user: alice, password type: plain, password: alicepassword, archived: false, notes: PIN: 1234, strength: 0/4",
+		"id:        2, machine: github.com, service: http, user: alice, password type: TOTP shared secret, password: otpauth://totp/GitHub:alice?secret=JBSWY3DPEHPK3PXP, archived: false, notes: PIN: 1234",
+		"id:        3, machine: router, service: http, user: admin, password type: plain, password: routerpassword, archived: true, strength: 0/4",
+	}
+	if !slices.Equal(results, expected) {
+		t.Fatalf("results = %q, want %q", results, expected)
+	}
+}
+
+// Import fails because the CSV is invalid or misses columns, nothing is imported.
+func TestImportOnePasswordCSVInvalid(t *testing.T) {
+	inputs := []string{
+		``,
+		"Title,Username\nGitHub,alice\n",
+		"Title,Username,Password\n\"GitHub,alice,mypassword\n",
+	}
+	for _, input := range inputs {
+		ctx := CreateContextForTesting(t)
+		os.Args = []string{"", "import", "-f", "1password-csv", "-"}
+		inBuf := bytes.NewBufferString(input)
+		outBuf := new(bytes.Buffer)
+
+		actualRet := Main(inBuf, outBuf)
+
+		expectedRet := 1
+		if actualRet != expectedRet {
+			t.Fatalf("Main() = %q, want %q, input is %q", actualRet, expectedRet, input)
+		}
+		results, err := readPasswords(ctx.Database, searchOptions{})
+		if err != nil {
+			t.Fatalf("readPasswords() err = %q, want nil", err)
+		}
+		if len(results) != 0 {
+			t.Fatalf("results = %q, want none", results)
+		}
+	}
+}
+
+// Only the required columns, as older 1Password versions name them.
+func TestImportOnePasswordCSVMinimal(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	os.Args = []string{"", "import", "-f", "1password-csv", "-"}
+	inBuf := bytes.NewBufferString("title,website,username,password\nGitHub,github.com,alice,alicepassword\n")
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	results, err := readPasswords(ctx.Database, searchOptions{})
+	if err != nil {
+		t.Fatalf("readPasswords() err = %q, want nil", err)
+	}
+	expected := []string{"id:        1, machine: github.com, service: http, user: alice, password type: plain, password: alicepassword"}
+	if !slices.Equal(results, expected) {
+		t.Fatalf("results = %q, want %q", results, expected)
+	}
+}
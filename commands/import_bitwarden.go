@@ -0,0 +1,121 @@
+// Copyright 2026 Miklos Vajna
+//
+// SPDX-License-Identifier: MIT
+
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// bitwardenLogin is the type of login items.
+const bitwardenLogin = 1
+
+// getBitwardenItemType names an item type of Bitwarden, which is not a login.
+func getBitwardenItemType(itemType int) string {
+	switch itemType {
+	case 2:
+		return "secure note"
+	case 3:
+		return "card"
+	case 4:
+		return "identity"
+	case 5:
+		return "SSH key"
+	default:
+		return fmt.Sprintf("item type %d", itemType)
+	}
+}
+
+// bitwardenFolder is a folder in a Bitwarden JSON export, nested folders have "/" in their name.
+type bitwardenFolder struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// bitwardenURI is an URI of a login item.
+type bitwardenURI struct {
+	URI string `json:"uri"`
+}
+
+// bitwardenItem is a single item in a Bitwarden JSON export.
+type bitwardenItem struct {
+	FolderID     string `json:"folderId,omitempty"`
+	Type         int    `json:"type"`
+	Name         string `json:"name"`
+	Notes        string `json:"notes"`
+	CreationDate string `json:"creationDate,omitempty"`
+	RevisionDate string `json:"revisionDate,omitempty"`
+	Login        struct {
+		URIs     []bitwardenURI `json:"uris"`
+		Username string         `json:"username"`
+		Password string         `json:"password"`
+		Totp     string         `json:"totp"`
+	} `json:"login"`
+}
+
+// bitwardenExport is an unencrypted Bitwarden JSON export.
+type bitwardenExport struct {
+	Encrypted bool              `json:"encrypted"`
+	Folders   []bitwardenFolder `json:"folders"`
+	Items     []bitwardenItem   `json:"items"`
+}
+
+// parseBitwardenJSON parses an unencrypted Bitwarden JSON export. Items which are not logins are
+// returned as unmapped.
+func parseBitwardenJSON(content []byte) ([]passwordRow, []string, error) {
+	var export bitwardenExport
+	err := json.Unmarshal(content, &export)
+	if err != nil {
+		return nil, nil, fmt.Errorf("json.Unmarshal() failed: %s", err)
+	}
+
+	if export.Encrypted {
+		return nil, nil, errors.New("encrypted Bitwarden exports are not supported, export as unencrypted JSON")
+	}
+
+	var rows []passwordRow
+	var unmapped []string
+	for _, item := range export.Items {
+		if item.Type != bitwardenLogin {
+			unmapped = append(unmapped, fmt.Sprintf("%s '%s'", getBitwardenItemType(item.Type), item.Name))
+			continue
+		}
+
+		row := passwordRow{
+			Machine: item.Name,
+			Service: "http",
+			User:    item.Login.Username,
+			Notes:   item.Notes,
+		}
+		for _, uri := range item.Login.URIs {
+			host := getURLHost(uri.URI)
+			if len(host) > 0 {
+				row.Machine = host
+				break
+			}
+		}
+		for _, date := range []struct {
+			value string
+			field *string
+		}{{item.CreationDate, &row.Created}, {item.RevisionDate, &row.Modified}} {
+			if len(date.value) == 0 {
+				continue
+			}
+
+			t, err := time.Parse(time.RFC3339, date.value)
+			if err != nil {
+				return nil, nil, fmt.Errorf("time.Parse() failed: %s", err)
+			}
+
+			*date.field = formatImportedTime(t)
+		}
+
+		rows = append(rows, getImportedRows(row, item.Login.Password, item.Login.Totp)...)
+	}
+
+	return rows, unmapped, nil
+}
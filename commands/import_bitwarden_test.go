@@ -0,0 +1,111 @@
+// Copyright 2026 Miklos Vajna
+//
+// SPDX-License-Identifier: MIT
+
+package commands
+
+import (
+	"bytes"
+	"os"
+	"slices"
+	"testing"
+)
+
+func TestImportBitwardenJSON(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	os.Args = []string{"", "import", "-f", "bitwarden-json", "-"}
+	inBuf := bytes.NewBufferString(`{
+  "encrypted": false,
+  "folders": [],
+  "items": [
+    {
+      "type": 1,
+      "name": "GitHub",
+      "notes": "PIN: 1234",
+      "creationDate": "2020-05-01T10:00:00.123Z",
+      "revisionDate": "2020-05-02T10:00:00.123Z",
+      "login": {
+        "uris": [{"match": null, "uri": "https://github.com/login"}],
+        "username": "alice",
+        "password": "alicepassword",
+        "totp": "otpauth://totp/GitHub:alice?secret=JBSWY3DPEHPK3PXP"
+      }
+    },
+    {
+      "type": 1,
+      "name": "router",
+      "notes": null,
+      "login": {
+        "uris": [{"match": null, "uri": "http://[::1"}],
+        "username": "admin",
+        "password": "routerpassword",
+        "totp": null
+      }
+    },
+    {"type": 2, "name": "Wifi", "notes": "secret", "secureNote": {"type": 0}},
+    {"type": 3, "name": "Visa", "card": {"number": "4111111111111111"}},
+    {"type": 4, "name": "Me", "identity": {"firstName": "Alice"}},
+    {"type": 5, "name": "Server", "sshKey": {"privateKey": "secret"}},
+    {"type": 9, "name": "Future"}
+  ]
+}`)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	expectedBuf := `Imported 3 passwords: 3 created, 0 overwritten, 0 renamed, 0 skipped
+Not imported, unsupported: secure note 'Wifi'
+Not imported, unsupported: card 'Visa'
+Not imported, unsupported: identity 'Me'
+Not imported, unsupported: SSH key 'Server'
+Not imported, unsupported: item type 9 'Future'
+`
+	if outBuf.String() != expectedBuf {
+		t.Fatalf("Main() output is %q, want %q", outBuf.String(), expectedBuf)
+	}
+	results, err := readPasswords(ctx.Database, searchOptions{verbose: true})
+	if err != nil {
+		t.Fatalf("readPasswords() err = %q, want nil", err)
+	}
+	expected := []string{
+		"id:        1, machine: github.com, service: http, user: alice, password type: plain, password: alicepassword, archived: false, created: 2020-05-01 12:00, modified: 2020-05-02 12:00, notes: PIN: 1234, strength: 0/4",
+		"id:        2, machine: github.com, service: http, user: alice, password type: TOTP shared secret, password: otpauth://totp/GitHub:alice?secret=JBSWY3DPEHPK3PXP, archived: false, created: 2020-05-01 12:00, modified: 2020-05-02 12:00, notes: PIN: 1234",
+		"id:        3, machine: router, service: http, user: admin, password type: plain, password: routerpassword, archived: false, strength: 0/4",
+	}
+	if !slices.Equal(results, expected) {
+		t.Fatalf("results = %q, want %q", results, expected)
+	}
+}
+
+// Import fails because the input is invalid or encrypted, nothing is imported.
+func TestImportBitwardenJSONInvalid(t *testing.T) {
+	inputs := []string{
+		`{`,
+		`{"encrypted": true, "encKeyValidation_DO_NOT_EDIT": "2.abc", "data": "2.def"}`,
+		`{"items": [{"type": 1, "name": "GitHub", "creationDate": "yesterday", "login": {"password": "mypassword"}}]}`,
+	}
+	for _, input := range inputs {
+		ctx := CreateContextForTesting(t)
+		os.Args = []string{"", "import", "-f", "bitwarden-json", "-"}
+		inBuf := bytes.NewBufferString(input)
+		outBuf := new(bytes.Buffer)
+
+		actualRet := Main(inBuf, outBuf)
+
+		expectedRet := 1
+		if actualRet != expectedRet {
+			t.Fatalf("Main() = %q, want %q, input is %q", actualRet, expectedRet, input)
+		}
+		results, err := readPasswords(ctx.Database, searchOptions{})
+		if err != nil {
+			t.Fatalf("readPasswords() err = %q, want nil", err)
+		}
+		if len(results) != 0 {
+			t.Fatalf("results = %q, want none", results)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright 2026 Miklos Vajna
+//
+// SPDX-License-Identifier: MIT
+
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseBrowserCSV parses the saved logins, as exported by Firefox or Chromium. The machine is the host
+// of the URL, without "www.".
+func parseBrowserCSV(content []byte) ([]passwordRow, error) {
+	table, err := parseCSVTable(content, "url", "username", "password")
+	if err != nil {
+		return nil, fmt.Errorf("parseCSVTable() failed: %s", err)
+	}
+
+	var rows []passwordRow
+	for _, record := range table.records {
+		link := table.get(record, "url")
+		row := passwordRow{
+			Machine:      strings.TrimPrefix(getURLHost(link), "www."),
+			Service:      "http",
+			User:         table.get(record, "username"),
+			Password:     table.get(record, "password"),
+			PasswordType: PasswordTypePlain,
+			Notes:        table.get(record, "note"),
+		}
+		if len(row.Machine) == 0 {
+			row.Machine = link
+		}
+		// Firefox has times in milliseconds.
+		for _, column := range []struct {
+			name  string
+			field *string
+		}{{"timecreated", &row.Created}, {"timepasswordchanged", &row.Modified}} {
+			millis, err := strconv.ParseInt(table.get(record, column.name), 10, 64)
+			if err != nil {
+				continue
+			}
+
+			*column.field = formatImportedTime(time.UnixMilli(millis))
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
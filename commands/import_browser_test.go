@@ -0,0 +1,138 @@
+// Copyright 2026 Miklos Vajna
+//
+// SPDX-License-Identifier: MIT
+
+package commands
+
+import (
+	"bytes"
+	"os"
+	"slices"
+	"testing"
+)
+
+// firefoxCSVForTesting is a Firefox export, with the same login for http and https.
+const firefoxCSVForTesting = `"url","username","password","httpRealm","formActionOrigin","guid","timeCreated","timeLastUsed","timePasswordChanged"
+"https://www.example.com","alice","alicepassword",,"https://www.example.com","{1}","1588320000000","1588320000000","1588406400000"
+"http://example.com","alice","alicepassword",,"http://example.com","{2}","1588320000000","1588320000000","1588406400000"
+"https://github.com","bob","bobpassword",,"https://github.com","{3}","1588320000000","1588320000000","1588320000000"
+"file:///home/alice/form.html","carol","carolpassword",,"","{4}","","",""
+`
+
+func TestImportBrowserCSVFirefox(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	// Already in the database.
+	_, err := ctx.Database.Exec(`insert into passwords (machine, service, user, password, type) values('github.com', 'http', 'bob', 'oldpassword', 'plain');`)
+	if err != nil {
+		t.Fatalf("db.Exec() = %q, want nil", err)
+	}
+	os.Args = []string{"", "import", "-f", "browser-csv", "-"}
+	inBuf := bytes.NewBufferString(firefoxCSVForTesting)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	expectedBuf := "Imported 2 passwords: 2 created, 0 overwritten, 0 renamed, 2 skipped\n"
+	if outBuf.String() != expectedBuf {
+		t.Fatalf("Main() output is %q, want %q", outBuf.String(), expectedBuf)
+	}
+	results, err := readPasswords(ctx.Database, searchOptions{verbose: true})
+	if err != nil {
+		t.Fatalf("readPasswords() err = %q, want nil", err)
+	}
+	expected := []string{
+		"id:        1, machine: github.com, service: http, user: bob, password type: plain, password: oldpassword, archived: false, strength: 0/4",
+		"id:        2, machine: example.com, service: http, user: alice, password type: plain, password: alicepassword, archived: false, created: 2020-05-01 10:00, modified: 2020-05-02 10:00, strength: 0/4",
+		"id:        3, machine: file:///home/alice/form.html, service: http, user: carol, password type: plain, password: carolpassword, archived: false, strength: 0/4",
+	}
+	if !slices.Equal(results, expected) {
+		t.Fatalf("results = %q, want %q", results, expected)
+	}
+}
+
+func TestImportBrowserCSVChromium(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	os.Args = []string{"", "import", "-f", "browser-csv", "-"}
+	inBuf := bytes.NewBufferString(`name,url,username,password,note
+www.example.com,https://www.example.com/login,alice,alicepassword,PIN: 1234
+`)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	results, err := readPasswords(ctx.Database, searchOptions{verbose: true})
+	if err != nil {
+		t.Fatalf("readPasswords() err = %q, want nil", err)
+	}
+	expected := []string{
+		"id:        1, machine: example.com, service: http, user: alice, password type: plain, password: alicepassword, archived: false, notes: PIN: 1234, strength: 0/4",
+	}
+	if !slices.Equal(results, expected) {
+		t.Fatalf("results = %q, want %q", results, expected)
+	}
+}
+
+// Dry run shows what would be skipped, without importing anything.
+func TestImportBrowserCSVDryRun(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	os.Args = []string{"", "import", "-f", "browser-csv", "--dry-run", "-"}
+	inBuf := bytes.NewBufferString(firefoxCSVForTesting)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	expectedBuf := "Would import 3 passwords: 3 created, 0 overwritten, 0 renamed, 1 skipped\n"
+	if outBuf.String() != expectedBuf {
+		t.Fatalf("Main() output is %q, want %q", outBuf.String(), expectedBuf)
+	}
+	results, err := readPasswords(ctx.Database, searchOptions{})
+	if err != nil {
+		t.Fatalf("readPasswords() err = %q, want nil", err)
+	}
+	if len(results) != 0 {
+		t.Fatalf("results = %q, want none", results)
+	}
+}
+
+// Import fails because the CSV misses a column, or duplicates are not allowed explicitly.
+func TestImportBrowserCSVInvalid(t *testing.T) {
+	tests := []struct {
+		input string
+		args  []string
+	}{
+		{"url,username\nhttps://example.com,alice\n", nil},
+		{firefoxCSVForTesting, []string{"--on-conflict", "fail"}},
+	}
+	for _, test := range tests {
+		ctx := CreateContextForTesting(t)
+		os.Args = append([]string{"", "import", "-f", "browser-csv", "-"}, test.args...)
+		inBuf := bytes.NewBufferString(test.input)
+		outBuf := new(bytes.Buffer)
+
+		actualRet := Main(inBuf, outBuf)
+
+		expectedRet := 1
+		if actualRet != expectedRet {
+			t.Fatalf("Main() = %q, want %q, input is %q", actualRet, expectedRet, test.input)
+		}
+		results, err := readPasswords(ctx.Database, searchOptions{})
+		if err != nil {
+			t.Fatalf("readPasswords() err = %q, want nil", err)
+		}
+		if len(results) != 0 {
+			t.Fatalf("results = %q, want none", results)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+// Copyright 2026 Miklos Vajna
+//
+// SPDX-License-Identifier: MIT
+
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// parseJSONExport parses the output of 'cpm export'.
+func parseJSONExport(content []byte) ([]passwordRow, error) {
+	var rows []passwordRow
+	err := json.Unmarshal(content, &rows)
+	if err != nil {
+		return nil, fmt.Errorf("json.Unmarshal() failed: %s", err)
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,126 @@
+// Copyright 2026 Miklos Vajna
+//
+// SPDX-License-Identifier: MIT
+
+package commands
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestImportJSONRoundTrip(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	_, err := ctx.Database.Exec(`insert into passwords (machine, service, user, password, type, archived, created, modified, expires, rotate_every) values('mymachine', 'http', 'myuser', 'mypassword', 'plain', 1, '2020-01-01T00:00:00+02:00', '2020-02-01T00:00:00+02:00', '2020-03-02T00:00:00+02:00', 30);
+	                             insert into passwords (machine, service, user, password, type) values('mymachine', 'http', 'myuser', 'JBSWY3DPEHPK3PXP', 'totp');`)
+	if err != nil {
+		t.Fatalf("db.Exec() = %q, want nil", err)
+	}
+	exported, err := exportPasswords(ctx.Database, searchOptions{verbose: true})
+	if err != nil {
+		t.Fatalf("exportPasswords() = %q, want nil", err)
+	}
+	expected := `"Expires":"2020-03-02T00:00:00+02:00","RotateEvery":30`
+	if !strings.Contains(string(exported), expected) {
+		t.Fatalf("exportPasswords() = %s, want it to contain %s", exported, expected)
+	}
+	path := filepath.Join(t.TempDir(), "export.json")
+	err = os.WriteFile(path, exported, 0600)
+	if err != nil {
+		t.Fatalf("os.WriteFile() failed: %s", err)
+	}
+	// Import into an empty database.
+	ctx = CreateContextForTesting(t)
+	os.Args = []string{"", "import", "-f", "json", path}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	expectedBuf := "Imported 2 passwords: 2 created, 0 overwritten, 0 renamed, 0 skipped\n"
+	if outBuf.String() != expectedBuf {
+		t.Fatalf("Main() output is %q, want %q", outBuf.String(), expectedBuf)
+	}
+	// IDs, the archived state, timestamps and the rotation are kept.
+	reexported, err := exportPasswords(ctx.Database, searchOptions{verbose: true})
+	if err != nil {
+		t.Fatalf("exportPasswords() = %q, want nil", err)
+	}
+	if string(reexported) != string(exported) {
+		t.Fatalf("exportPasswords() = %s, want %s", reexported, exported)
+	}
+}
+
+func TestImportJSONStdin(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	os.Args = []string{"", "import", "--format", "json", "-"}
+	inBuf := bytes.NewBufferString(`[{"Machine":"mymachine","Service":"http","User":"myuser","Password":"mypassword","PasswordType":"plain"}]`)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	results, err := readPasswords(ctx.Database, searchOptions{})
+	if err != nil {
+		t.Fatalf("readPasswords() err = %q, want nil", err)
+	}
+	expected := "id:        1, machine: mymachine, service: http, user: myuser, password type: plain, password: mypassword"
+	if len(results) != 1 || results[0] != expected {
+		t.Fatalf("results = %q, want %q", results, expected)
+	}
+}
+
+// Import fails because the input is invalid, nothing is imported.
+func TestImportJSONInvalid(t *testing.T) {
+	inputs := []string{
+		`{`,
+		`[{"Machine":"mymachine","Service":"http","User":"myuser","Password":"mypassword","PasswordType":"plain"},
+		  {"Machine":"mymachine","Service":"http","User":"myuser","Password":"mypassword","PasswordType":"foo"}]`,
+		`[{"Machine":"mymachine","Service":"http","User":"myuser","Password":"notjson","PasswordType":"recovery-codes"}]`,
+	}
+	for _, input := range inputs {
+		ctx := CreateContextForTesting(t)
+		os.Args = []string{"", "import", "-f", "json", "-"}
+		inBuf := bytes.NewBufferString(input)
+		outBuf := new(bytes.Buffer)
+
+		actualRet := Main(inBuf, outBuf)
+
+		expectedRet := 1
+		if actualRet != expectedRet {
+			t.Fatalf("Main() = %q, want %q, input is %q", actualRet, expectedRet, input)
+		}
+		results, err := readPasswords(ctx.Database, searchOptions{})
+		if err != nil {
+			t.Fatalf("readPasswords() err = %q, want nil", err)
+		}
+		if len(results) != 0 {
+			t.Fatalf("results = %q, want none", results)
+		}
+	}
+}
+
+// Import fails because the file is missing.
+func TestImportJSONNoFile(t *testing.T) {
+	CreateContextForTesting(t)
+	os.Args = []string{"", "import", "-f", "json"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 1
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q", actualRet, expectedRet)
+	}
+}
@@ -0,0 +1,668 @@
+// Copyright 2026 Miklos Vajna
+//
+// SPDX-License-Identifier: MIT
+
+package commands
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/tobischo/argon2"
+	xargon2 "golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20"
+)
+
+const (
+	// kdbxSignature1 and kdbxSignature2 start every KeePass database.
+	kdbxSignature1 = 0x9AA2D903
+	kdbxSignature2 = 0xB54BFB67
+
+	// Outer header fields.
+	kdbxHeaderEnd         = 0
+	kdbxHeaderCipher      = 2
+	kdbxHeaderCompression = 3
+	kdbxHeaderMasterSeed  = 4
+	kdbxHeaderIV          = 7
+	kdbxHeaderKdf         = 11
+
+	// Inner header fields.
+	kdbxInnerHeaderEnd       = 0
+	kdbxInnerHeaderStreamID  = 1
+	kdbxInnerHeaderStreamKey = 2
+
+	// kdbxStreamChaCha20 is the only inner random stream KDBX 4 writers use.
+	kdbxStreamChaCha20 = 3
+
+	kdbxCipherAES      = "31c1f2e6bf714350be5805216afc5aff"
+	kdbxCipherChaCha20 = "d6038a2b8b6f4cb5a524339a31dbb59a"
+	kdbxKdfAES         = "c9d9f39a628a4460bf740d08c18a4fea"
+	kdbxKdfAESLegacy   = "7c02bb8279a74ac0927d114a00648238"
+	kdbxKdfArgon2d     = "ef636ddf8c29444b91f7a9a403e30a0c"
+	kdbxKdfArgon2id    = "9e298b1956db4773b23dfc3ec6f0a1e6"
+)
+
+// keepassFile is the <KeePassFile> element of a KeePass XML document.
+type keepassFile struct {
+	XMLName xml.Name `xml:"KeePassFile"`
+	Meta    struct {
+		Generator         string `xml:",omitempty"`
+		RecycleBinEnabled string `xml:",omitempty"`
+		RecycleBinUUID    string `xml:",omitempty"`
+	}
+	Root struct {
+		Groups []keepassGroup `xml:"Group"`
+	}
+}
+
+// keepassGroup is a <Group> element, groups can be nested.
+type keepassGroup struct {
+	UUID    string
+	Name    string
+	Entries []keepassEntry `xml:"Entry"`
+	Groups  []keepassGroup `xml:"Group"`
+}
+
+// keepassString is a <String> element, a named field of an entry.
+type keepassString struct {
+	Key   string
+	Value string
+}
+
+// keepassEntry is an <Entry> element, its old versions in <History> are ignored.
+type keepassEntry struct {
+	UUID    string          `xml:",omitempty"`
+	Strings []keepassString `xml:"String"`
+	Times   struct {
+		CreationTime         string `xml:",omitempty"`
+		LastModificationTime string `xml:",omitempty"`
+		ExpiryTime           string `xml:",omitempty"`
+		Expires              string `xml:",omitempty"`
+	}
+}
+
+// get returns the string field of `e` with the name `key`.
+func (e *keepassEntry) get(key string) string {
+	for _, field := range e.Strings {
+		if field.Key == key {
+			return field.Value
+		}
+	}
+
+	return ""
+}
+
+// keepassTokenReader decrypts protected values in document order, while the XML is decoded.
+type keepassTokenReader struct {
+	decoder   *xml.Decoder
+	stream    cipher.Stream
+	protected bool
+}
+
+// Token returns the next token, the text of protected values is decrypted.
+func (r *keepassTokenReader) Token() (xml.Token, error) {
+	token, err := r.decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := token.(type) {
+	case xml.StartElement:
+		r.protected = false
+		for _, attr := range t.Attr {
+			if attr.Name.Local == "Protected" && strings.EqualFold(attr.Value, "true") {
+				r.protected = r.stream != nil
+			}
+		}
+	case xml.EndElement:
+		r.protected = false
+	case xml.CharData:
+		if r.protected {
+			value, err := base64.StdEncoding.DecodeString(string(t))
+			if err != nil {
+				return nil, fmt.Errorf("base64.DecodeString() failed: %s", err)
+			}
+
+			r.stream.XORKeyStream(value, value)
+			return xml.CharData(value), nil
+		}
+	}
+
+	return token, nil
+}
+
+// parseKeepassTime parses a time from KeePass XML: either RFC 3339 or base64-encoded seconds since
+// 0001-01-01.
+func parseKeepassTime(value string) (string, error) {
+	if len(value) == 0 {
+		return "", nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		buf, err := base64.StdEncoding.DecodeString(value)
+		if err != nil || len(buf) != 8 {
+			return "", fmt.Errorf("invalid time: '%s'", value)
+		}
+
+		// Seconds between 0001-01-01 and 1970-01-01.
+		const unixOffset = 62135596800
+		t = time.Unix(int64(binary.LittleEndian.Uint64(buf))-unixOffset, 0)
+	}
+
+	return formatImportedTime(t), nil
+}
+
+// getKeepassMachine returns the machine of an entry: the host of its URL, or its title.
+func getKeepassMachine(entry *keepassEntry) string {
+	host := getURLHost(entry.get("URL"))
+	if len(host) > 0 {
+		return host
+	}
+
+	return entry.get("Title")
+}
+
+// getKeepassTotp returns the TOTP secret of an entry, as stored by KeePassXC, KeePass or plugins.
+func getKeepassTotp(entry *keepassEntry) string {
+	for _, key := range []string{"otp", "TimeOtp-Secret-Base32", "TOTP Seed"} {
+		value := entry.get(key)
+		if len(value) > 0 {
+			return value
+		}
+	}
+
+	return ""
+}
+
+// getKeepassEntryRows turns an entry into a plain and / or a TOTP password.
+func getKeepassEntryRows(entry *keepassEntry, service string, archived bool) ([]passwordRow, error) {
+	row := passwordRow{
+		Machine:  getKeepassMachine(entry),
+		Service:  service,
+		User:     entry.get("UserName"),
+		Archived: archived,
+		Notes:    entry.get("Notes"),
+	}
+	var err error
+	row.Created, err = parseKeepassTime(entry.Times.CreationTime)
+	if err != nil {
+		return nil, fmt.Errorf("parseKeepassTime() failed: %s", err)
+	}
+	row.Modified, err = parseKeepassTime(entry.Times.LastModificationTime)
+	if err != nil {
+		return nil, fmt.Errorf("parseKeepassTime() failed: %s", err)
+	}
+	if strings.EqualFold(entry.Times.Expires, "true") {
+		row.Expires, err = parseKeepassTime(entry.Times.ExpiryTime)
+		if err != nil {
+			return nil, fmt.Errorf("parseKeepassTime() failed: %s", err)
+		}
+	}
+
+	return getImportedRows(row, entry.get("Password"), getKeepassTotp(entry)), nil
+}
+
+// getKeepassGroupRows returns the passwords of `group` and its sub-groups. The service is the
+// path of the group below the root group, entries in the recycle bin are archived.
+func getKeepassGroupRows(group *keepassGroup, path []string, recycleBin string, archived bool) ([]passwordRow, error) {
+	var rows []passwordRow
+	service := "http"
+	if len(path) > 0 {
+		service = strings.Join(path, "/")
+	}
+	for i := range group.Entries {
+		entryRows, err := getKeepassEntryRows(&group.Entries[i], service, archived)
+		if err != nil {
+			return nil, fmt.Errorf("getKeepassEntryRows() failed: %s", err)
+		}
+
+		rows = append(rows, entryRows...)
+	}
+
+	for i := range group.Groups {
+		child := &group.Groups[i]
+		childPath := append(path[:len(path):len(path)], child.Name)
+		childArchived := archived
+		if len(recycleBin) > 0 && child.UUID == recycleBin {
+			childPath = path
+			childArchived = true
+		}
+		childRows, err := getKeepassGroupRows(child, childPath, recycleBin, childArchived)
+		if err != nil {
+			return nil, fmt.Errorf("getKeepassGroupRows() failed: %s", err)
+		}
+
+		rows = append(rows, childRows...)
+	}
+
+	return rows, nil
+}
+
+// parseKeepassXML parses a KeePass XML document, `stream` decrypts protected values if not nil.
+func parseKeepassXML(content []byte, stream cipher.Stream) ([]passwordRow, error) {
+	reader := &keepassTokenReader{
+		decoder: xml.NewDecoder(bytes.NewReader(content)),
+		stream:  stream,
+	}
+	var file keepassFile
+	err := xml.NewTokenDecoder(reader).Decode(&file)
+	if err != nil {
+		return nil, fmt.Errorf("Decode() failed: %s", err)
+	}
+
+	recycleBin := file.Meta.RecycleBinUUID
+	if recycleBin == base64.StdEncoding.EncodeToString(make([]byte, 16)) {
+		recycleBin = ""
+	}
+	var rows []passwordRow
+	for i := range file.Root.Groups {
+		// The root group itself is not part of the service.
+		groupRows, err := getKeepassGroupRows(&file.Root.Groups[i], nil, recycleBin, false)
+		if err != nil {
+			return nil, fmt.Errorf("getKeepassGroupRows() failed: %s", err)
+		}
+
+		rows = append(rows, groupRows...)
+	}
+
+	return rows, nil
+}
+
+// readKdbxField reads a type-length-value header field.
+func readKdbxField(reader *bytes.Reader) (byte, []byte, error) {
+	var header struct {
+		ID   byte
+		Size uint32
+	}
+	err := binary.Read(reader, binary.LittleEndian, &header)
+	if err != nil {
+		return 0, nil, fmt.Errorf("binary.Read() failed: %s", err)
+	}
+
+	// The size is not trusted, don't allocate more than what's left.
+	if uint64(header.Size) > uint64(reader.Len()) {
+		return 0, nil, errors.New("truncated file")
+	}
+
+	data := make([]byte, header.Size)
+	_, err = io.ReadFull(reader, data)
+	if err != nil {
+		return 0, nil, fmt.Errorf("io.ReadFull() failed: %s", err)
+	}
+
+	return header.ID, data, nil
+}
+
+// parseVariantDictionary parses the KDF parameters, values are kept in their little-endian form.
+func parseVariantDictionary(data []byte) (map[string][]byte, error) {
+	reader := bytes.NewReader(data)
+	var version uint16
+	err := binary.Read(reader, binary.LittleEndian, &version)
+	if err != nil {
+		return nil, fmt.Errorf("binary.Read() failed: %s", err)
+	}
+
+	if version>>8 != 1 {
+		return nil, fmt.Errorf("unsupported variant dictionary version: %#x", version)
+	}
+
+	dict := map[string][]byte{}
+	for {
+		valueType, err := reader.ReadByte()
+		if err != nil {
+			return nil, fmt.Errorf("ReadByte() failed: %s", err)
+		}
+
+		if valueType == 0 {
+			return dict, nil
+		}
+
+		var fields [2][]byte
+		for i := range fields {
+			var size uint32
+			err = binary.Read(reader, binary.LittleEndian, &size)
+			if err != nil {
+				return nil, fmt.Errorf("binary.Read() failed: %s", err)
+			}
+
+			if uint64(size) > uint64(reader.Len()) {
+				return nil, errors.New("truncated file")
+			}
+
+			fields[i] = make([]byte, size)
+			_, err = io.ReadFull(reader, fields[i])
+			if err != nil {
+				return nil, fmt.Errorf("io.ReadFull() failed: %s", err)
+			}
+		}
+		dict[string(fields[0])] = fields[1]
+	}
+}
+
+// getVariantUint returns an unsigned integer from the KDF parameters.
+func getVariantUint(dict map[string][]byte, key string) (uint64, error) {
+	value := dict[key]
+	switch len(value) {
+	case 4:
+		return uint64(binary.LittleEndian.Uint32(value)), nil
+	case 8:
+		return binary.LittleEndian.Uint64(value), nil
+	default:
+		return 0, fmt.Errorf("missing or invalid KDF parameter '%s'", key)
+	}
+}
+
+// readKeepassKeyfile returns the key from a KeePass keyfile: XML, 32 raw bytes, 64 hex digits, or
+// the hash of any other file.
+func readKeepassKeyfile(content []byte) ([]byte, error) {
+	var keyfile struct {
+		XMLName xml.Name `xml:"KeyFile"`
+		Meta    struct {
+			Version string
+		}
+		Key struct {
+			Data string
+		}
+	}
+	if xml.Unmarshal(content, &keyfile) == nil {
+		data := strings.Join(strings.Fields(keyfile.Key.Data), "")
+		if strings.HasPrefix(keyfile.Meta.Version, "2.") {
+			key, err := hex.DecodeString(data)
+			if err != nil {
+				return nil, fmt.Errorf("hex.DecodeString() failed: %s", err)
+			}
+			return key, nil
+		}
+
+		key, err := base64.StdEncoding.DecodeString(data)
+		if err != nil {
+			return nil, fmt.Errorf("base64.DecodeString() failed: %s", err)
+		}
+		return key, nil
+	}
+
+	if len(content) == 32 {
+		return content, nil
+	}
+
+	if len(content) == 64 {
+		key, err := hex.DecodeString(string(content))
+		if err == nil {
+			return key, nil
+		}
+	}
+
+	hash := sha256.Sum256(content)
+	return hash[:], nil
+}
+
+// getKdbxCompositeKey combines the master password and the keyfile, either of them is optional.
+func getKdbxCompositeKey(password string, keyfile []byte) []byte {
+	composite := sha256.New()
+	if len(password) > 0 {
+		hash := sha256.Sum256([]byte(password))
+		composite.Write(hash[:])
+	}
+	if len(keyfile) > 0 {
+		composite.Write(keyfile)
+	}
+
+	return composite.Sum(nil)
+}
+
+// transformKdbxKey runs the key derivation function on the composite key.
+func transformKdbxKey(composite []byte, kdf map[string][]byte) ([]byte, error) {
+	salt := kdf["S"]
+	switch hex.EncodeToString(kdf["$UUID"]) {
+	case kdbxKdfAES, kdbxKdfAESLegacy:
+		rounds, err := getVariantUint(kdf, "R")
+		if err != nil {
+			return nil, fmt.Errorf("getVariantUint() failed: %s", err)
+		}
+
+		block, err := aes.NewCipher(salt)
+		if err != nil {
+			return nil, fmt.Errorf("aes.NewCipher() failed: %s", err)
+		}
+
+		key := bytes.Clone(composite)
+		for i := uint64(0); i < rounds; i++ {
+			block.Encrypt(key[:16], key[:16])
+			block.Encrypt(key[16:], key[16:])
+		}
+		hash := sha256.Sum256(key)
+		return hash[:], nil
+	case kdbxKdfArgon2d, kdbxKdfArgon2id:
+		var params [3]uint64
+		for i, name := range []string{"I", "M", "P"} {
+			var err error
+			params[i], err = getVariantUint(kdf, name)
+			if err != nil {
+				return nil, fmt.Errorf("getVariantUint() failed: %s", err)
+			}
+		}
+
+		iterations, memory, parallelism := uint32(params[0]), uint32(params[1]/1024), uint8(params[2])
+		if hex.EncodeToString(kdf["$UUID"]) == kdbxKdfArgon2d {
+			return argon2.DKey(composite, salt, iterations, memory, parallelism, 32), nil
+		}
+		return xargon2.IDKey(composite, salt, iterations, memory, parallelism, 32), nil
+	default:
+		return nil, fmt.Errorf("unsupported key derivation function")
+	}
+}
+
+// getKdbxBlockKey returns the HMAC key of the block with `index`.
+func getKdbxBlockKey(index uint64, hmacKey []byte) []byte {
+	hash := sha512.New()
+	binary.Write(hash, binary.LittleEndian, index)
+	hash.Write(hmacKey)
+	return hash.Sum(nil)
+}
+
+// decryptKdbx decrypts a KDBX 4 database, returning its XML document and the stream which decrypts
+// protected values.
+func decryptKdbx(content []byte, password string, keyfile []byte) ([]byte, cipher.Stream, error) {
+	reader := bytes.NewReader(content)
+	var signature struct {
+		Signature1 uint32
+		Signature2 uint32
+		Minor      uint16
+		Major      uint16
+	}
+	err := binary.Read(reader, binary.LittleEndian, &signature)
+	if err != nil || signature.Signature1 != kdbxSignature1 || signature.Signature2 != kdbxSignature2 {
+		return nil, nil, errors.New("not a KeePass database")
+	}
+
+	if signature.Major != 4 {
+		return nil, nil, fmt.Errorf("unsupported KDBX version %d.%d, only 4.x is supported", signature.Major, signature.Minor)
+	}
+
+	header := map[byte][]byte{}
+	for {
+		id, data, err := readKdbxField(reader)
+		if err != nil {
+			return nil, nil, fmt.Errorf("readKdbxField() failed: %s", err)
+		}
+
+		if id == kdbxHeaderEnd {
+			break
+		}
+		header[id] = data
+	}
+	headerBytes := content[:len(content)-reader.Len()]
+
+	var headerHashes [2][32]byte
+	err = binary.Read(reader, binary.LittleEndian, &headerHashes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("binary.Read() failed: %s", err)
+	}
+
+	if sha256.Sum256(headerBytes) != headerHashes[0] {
+		return nil, nil, errors.New("corrupted header")
+	}
+
+	kdf, err := parseVariantDictionary(header[kdbxHeaderKdf])
+	if err != nil {
+		return nil, nil, fmt.Errorf("parseVariantDictionary() failed: %s", err)
+	}
+
+	transformed, err := transformKdbxKey(getKdbxCompositeKey(password, keyfile), kdf)
+	if err != nil {
+		return nil, nil, fmt.Errorf("transformKdbxKey() failed: %s", err)
+	}
+
+	masterSeed := header[kdbxHeaderMasterSeed]
+	encryptionKey := sha256.Sum256(append(bytes.Clone(masterSeed), transformed...))
+	hmacKey := sha512.Sum512(append(append(bytes.Clone(masterSeed), transformed...), 1))
+
+	mac := hmac.New(sha256.New, getKdbxBlockKey(^uint64(0), hmacKey[:]))
+	mac.Write(headerBytes)
+	if !hmac.Equal(mac.Sum(nil), headerHashes[1][:]) {
+		return nil, nil, errors.New("wrong master password or keyfile")
+	}
+
+	// Read the HMAC-protected blocks.
+	var encrypted []byte
+	for index := uint64(0); ; index++ {
+		var block struct {
+			Hash [32]byte
+			Size uint32
+		}
+		err = binary.Read(reader, binary.LittleEndian, &block)
+		if err != nil {
+			return nil, nil, fmt.Errorf("binary.Read() failed: %s", err)
+		}
+
+		if uint64(block.Size) > uint64(reader.Len()) {
+			return nil, nil, errors.New("truncated file")
+		}
+
+		data := make([]byte, block.Size)
+		_, err = io.ReadFull(reader, data)
+		if err != nil {
+			return nil, nil, fmt.Errorf("io.ReadFull() failed: %s", err)
+		}
+
+		mac := hmac.New(sha256.New, getKdbxBlockKey(index, hmacKey[:]))
+		binary.Write(mac, binary.LittleEndian, index)
+		binary.Write(mac, binary.LittleEndian, block.Size)
+		mac.Write(data)
+		if !hmac.Equal(mac.Sum(nil), block.Hash[:]) {
+			return nil, nil, fmt.Errorf("corrupted block %d", index)
+		}
+
+		if block.Size == 0 {
+			break
+		}
+		encrypted = append(encrypted, data...)
+	}
+
+	iv := header[kdbxHeaderIV]
+	var plain []byte
+	switch hex.EncodeToString(header[kdbxHeaderCipher]) {
+	case kdbxCipherAES:
+		block, err := aes.NewCipher(encryptionKey[:])
+		if err != nil {
+			return nil, nil, fmt.Errorf("aes.NewCipher() failed: %s", err)
+		}
+
+		if len(iv) != aes.BlockSize || len(encrypted) == 0 || len(encrypted)%aes.BlockSize != 0 {
+			return nil, nil, errors.New("invalid AES payload")
+		}
+		plain = make([]byte, len(encrypted))
+		cipher.NewCBCDecrypter(block, iv).CryptBlocks(plain, encrypted)
+		padding := int(plain[len(plain)-1])
+		if padding == 0 || padding > aes.BlockSize {
+			return nil, nil, errors.New("invalid AES padding")
+		}
+		plain = plain[:len(plain)-padding]
+	case kdbxCipherChaCha20:
+		stream, err := chacha20.NewUnauthenticatedCipher(encryptionKey[:], iv)
+		if err != nil {
+			return nil, nil, fmt.Errorf("chacha20.NewUnauthenticatedCipher() failed: %s", err)
+		}
+
+		plain = make([]byte, len(encrypted))
+		stream.XORKeyStream(plain, encrypted)
+	default:
+		return nil, nil, errors.New("unsupported cipher")
+	}
+
+	if compression := header[kdbxHeaderCompression]; len(compression) == 4 && binary.LittleEndian.Uint32(compression) == 1 {
+		gzipReader, err := gzip.NewReader(bytes.NewReader(plain))
+		if err != nil {
+			return nil, nil, fmt.Errorf("gzip.NewReader() failed: %s", err)
+		}
+
+		plain, err = io.ReadAll(gzipReader)
+		if err != nil {
+			return nil, nil, fmt.Errorf("io.ReadAll() failed: %s", err)
+		}
+	}
+
+	// The inner header describes how protected values are encrypted.
+	reader = bytes.NewReader(plain)
+	var streamID uint32
+	var streamKey []byte
+	for {
+		id, data, err := readKdbxField(reader)
+		if err != nil {
+			return nil, nil, fmt.Errorf("readKdbxField() failed: %s", err)
+		}
+
+		if id == kdbxInnerHeaderEnd {
+			break
+		}
+		switch id {
+		case kdbxInnerHeaderStreamID:
+			if len(data) == 4 {
+				streamID = binary.LittleEndian.Uint32(data)
+			}
+		case kdbxInnerHeaderStreamKey:
+			streamKey = data
+		}
+	}
+
+	if streamID != kdbxStreamChaCha20 {
+		return nil, nil, fmt.Errorf("unsupported inner random stream: %d", streamID)
+	}
+	hash := sha512.Sum512(streamKey)
+	stream, err := chacha20.NewUnauthenticatedCipher(hash[:32], hash[32:44])
+	if err != nil {
+		return nil, nil, fmt.Errorf("chacha20.NewUnauthenticatedCipher() failed: %s", err)
+	}
+
+	return plain[len(plain)-reader.Len():], stream, nil
+}
+
+// parseKdbx decrypts and parses a KDBX 4 database.
+func parseKdbx(content []byte, password string, keyfile []byte) ([]passwordRow, error) {
+	document, stream, err := decryptKdbx(content, password, keyfile)
+	if err != nil {
+		return nil, fmt.Errorf("decryptKdbx() failed: %s", err)
+	}
+
+	rows, err := parseKeepassXML(document, stream)
+	if err != nil {
+		return nil, fmt.Errorf("parseKeepassXML() failed: %s", err)
+	}
+
+	return rows, nil
+}
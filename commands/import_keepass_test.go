@@ -0,0 +1,497 @@
+// Copyright 2026 Miklos Vajna
+//
+// SPDX-License-Identifier: MIT
+
+package commands
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/chacha20"
+)
+
+// kdbxOptionsForTesting describes how writeKdbxForTesting encrypts a database.
+type kdbxOptionsForTesting struct {
+	cipher   string
+	kdf      map[string][]byte
+	compress bool
+}
+
+// writeKdbxFieldForTesting writes a type-length-value header field.
+func writeKdbxFieldForTesting(buf *bytes.Buffer, id byte, data []byte) {
+	buf.WriteByte(id)
+	binary.Write(buf, binary.LittleEndian, uint32(len(data)))
+	buf.Write(data)
+}
+
+// writeVariantDictionaryForTesting writes KDF parameters, all values are written as byte arrays.
+func writeVariantDictionaryForTesting(dict map[string][]byte) []byte {
+	buf := new(bytes.Buffer)
+	binary.Write(buf, binary.LittleEndian, uint16(0x0100))
+	var keys []string
+	for key := range dict {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		buf.WriteByte(0x42)
+		binary.Write(buf, binary.LittleEndian, uint32(len(key)))
+		buf.WriteString(key)
+		binary.Write(buf, binary.LittleEndian, uint32(len(dict[key])))
+		buf.Write(dict[key])
+	}
+	buf.WriteByte(0)
+	return buf.Bytes()
+}
+
+// writeKdbxForTesting creates a KDBX 4 database, `document` gets a function to encrypt protected
+// values in document order.
+func writeKdbxForTesting(t *testing.T, document func(protect func(string) string) string, password string, keyfile []byte, opts kdbxOptionsForTesting) []byte {
+	streamKey := bytes.Repeat([]byte{0x5a}, 64)
+	hash := sha512.Sum512(streamKey)
+	stream, err := chacha20.NewUnauthenticatedCipher(hash[:32], hash[32:44])
+	if err != nil {
+		t.Fatalf("chacha20.NewUnauthenticatedCipher() = %q, want nil", err)
+	}
+	protect := func(value string) string {
+		buf := []byte(value)
+		stream.XORKeyStream(buf, buf)
+		return base64.StdEncoding.EncodeToString(buf)
+	}
+	inner := new(bytes.Buffer)
+	writeKdbxFieldForTesting(inner, kdbxInnerHeaderStreamID, binary.LittleEndian.AppendUint32(nil, kdbxStreamChaCha20))
+	writeKdbxFieldForTesting(inner, kdbxInnerHeaderStreamKey, streamKey)
+	writeKdbxFieldForTesting(inner, kdbxInnerHeaderEnd, nil)
+	inner.WriteString(document(protect))
+	plain := inner.Bytes()
+	compression := uint32(0)
+	if opts.compress {
+		compression = 1
+		compressed := new(bytes.Buffer)
+		writer := gzip.NewWriter(compressed)
+		writer.Write(plain)
+		writer.Close()
+		plain = compressed.Bytes()
+	}
+
+	masterSeed := bytes.Repeat([]byte{0x01}, 32)
+	iv := bytes.Repeat([]byte{0x02}, 16)
+	if opts.cipher == kdbxCipherChaCha20 {
+		iv = iv[:12]
+	}
+	cipherID, _ := hex.DecodeString(opts.cipher)
+	header := new(bytes.Buffer)
+	binary.Write(header, binary.LittleEndian, []uint32{kdbxSignature1, kdbxSignature2})
+	binary.Write(header, binary.LittleEndian, []uint16{1, 4})
+	writeKdbxFieldForTesting(header, kdbxHeaderCipher, cipherID)
+	writeKdbxFieldForTesting(header, kdbxHeaderCompression, binary.LittleEndian.AppendUint32(nil, compression))
+	writeKdbxFieldForTesting(header, kdbxHeaderMasterSeed, masterSeed)
+	writeKdbxFieldForTesting(header, kdbxHeaderIV, iv)
+	writeKdbxFieldForTesting(header, kdbxHeaderKdf, writeVariantDictionaryForTesting(opts.kdf))
+	writeKdbxFieldForTesting(header, kdbxHeaderEnd, []byte("\r\n\r\n"))
+	headerBytes := bytes.Clone(header.Bytes())
+
+	transformed, err := transformKdbxKey(getKdbxCompositeKey(password, keyfile), opts.kdf)
+	if err != nil {
+		t.Fatalf("transformKdbxKey() = %q, want nil", err)
+	}
+	encryptionKey := sha256.Sum256(append(bytes.Clone(masterSeed), transformed...))
+	hmacKey := sha512.Sum512(append(append(bytes.Clone(masterSeed), transformed...), 1))
+	headerHash := sha256.Sum256(headerBytes)
+	header.Write(headerHash[:])
+	mac := hmac.New(sha256.New, getKdbxBlockKey(^uint64(0), hmacKey[:]))
+	mac.Write(headerBytes)
+	header.Write(mac.Sum(nil))
+
+	var encrypted []byte
+	if opts.cipher == kdbxCipherChaCha20 {
+		encrypted = make([]byte, len(plain))
+		stream, err := chacha20.NewUnauthenticatedCipher(encryptionKey[:], iv)
+		if err != nil {
+			t.Fatalf("chacha20.NewUnauthenticatedCipher() = %q, want nil", err)
+		}
+		stream.XORKeyStream(encrypted, plain)
+	} else {
+		padding := aes.BlockSize - len(plain)%aes.BlockSize
+		plain = append(plain, bytes.Repeat([]byte{byte(padding)}, padding)...)
+		encrypted = make([]byte, len(plain))
+		block, err := aes.NewCipher(encryptionKey[:])
+		if err != nil {
+			t.Fatalf("aes.NewCipher() = %q, want nil", err)
+		}
+		cipher.NewCBCEncrypter(block, iv).CryptBlocks(encrypted, plain)
+	}
+
+	for index, data := range [][]byte{encrypted, nil} {
+		mac := hmac.New(sha256.New, getKdbxBlockKey(uint64(index), hmacKey[:]))
+		binary.Write(mac, binary.LittleEndian, uint64(index))
+		binary.Write(mac, binary.LittleEndian, uint32(len(data)))
+		mac.Write(data)
+		header.Write(mac.Sum(nil))
+		binary.Write(header, binary.LittleEndian, uint32(len(data)))
+		header.Write(data)
+	}
+	return header.Bytes()
+}
+
+// getKeepassTimeForTesting returns the binary form of a time, as KDBX 4 stores it.
+func getKeepassTimeForTesting(value string) string {
+	t, _ := time.Parse(time.RFC3339, value)
+	buf := binary.LittleEndian.AppendUint64(nil, uint64(t.Unix()+62135596800))
+	return base64.StdEncoding.EncodeToString(buf)
+}
+
+// keepassDocumentForTesting returns a KeePass XML document with a recycle bin, nested groups and
+// history.
+func keepassDocumentForTesting(protect func(string) string) string {
+	return fmt.Sprintf(`<?xml version="1.0" encoding="utf-8" standalone="yes"?>
+<KeePassFile>
+	<Meta>
+		<RecycleBinEnabled>True</RecycleBinEnabled>
+		<RecycleBinUUID>cmVjeWNsZWJpbnJlY3ljbA==</RecycleBinUUID>
+	</Meta>
+	<Root>
+		<Group>
+			<UUID>cm9vdHJvb3Ryb290cm9vdA==</UUID>
+			<Name>Passwords</Name>
+			<Entry>
+				<String><Key>Title</Key><Value>Example</Value></String>
+				<String><Key>UserName</Key><Value>alice</Value></String>
+				<String><Key>Password</Key><Value Protected="True">%s</Value></String>
+				<String><Key>URL</Key><Value>https://www.example.com/login</Value></String>
+				<String><Key>Notes</Key><Value>PIN: 1234</Value></String>
+				<String><Key>otp</Key><Value Protected="True">%s</Value></String>
+				<Times>
+					<CreationTime>%s</CreationTime>
+					<LastModificationTime>2020-02-01T00:00:00Z</LastModificationTime>
+					<ExpiryTime>2020-03-01T00:00:00Z</ExpiryTime>
+					<Expires>False</Expires>
+				</Times>
+				<History>
+					<Entry>
+						<String><Key>Password</Key><Value Protected="True">%s</Value></String>
+					</Entry>
+				</History>
+			</Entry>
+			<Group>
+				<UUID>bmV0d29ya25ldHdvcmtuZQ==</UUID>
+				<Name>Network</Name>
+				<Entry>
+					<String><Key>Title</Key><Value>gitlab</Value></String>
+					<String><Key>UserName</Key><Value>alice</Value></String>
+					<String><Key>Password</Key><Value Protected="True"></Value></String>
+					<String><Key>TimeOtp-Secret-Base32</Key><Value Protected="True">%s</Value></String>
+				</Entry>
+				<Group>
+					<UUID>cm91dGVyc3JvdXRlcnNybw==</UUID>
+					<Name>Routers</Name>
+					<Entry>
+						<String><Key>Title</Key><Value>router.lan</Value></String>
+						<String><Key>UserName</Key><Value>admin</Value></String>
+						<String><Key>Password</Key><Value Protected="True">%s</Value></String>
+						<Times>
+							<ExpiryTime>2021-01-01T00:00:00Z</ExpiryTime>
+							<Expires>True</Expires>
+						</Times>
+					</Entry>
+				</Group>
+			</Group>
+			<Group>
+				<UUID>cmVjeWNsZWJpbnJlY3ljbA==</UUID>
+				<Name>Recycle Bin</Name>
+				<Entry>
+					<String><Key>Title</Key><Value>Old</Value></String>
+					<String><Key>UserName</Key><Value>bob</Value></String>
+					<String><Key>Password</Key><Value Protected="True">%s</Value></String>
+					<String><Key>URL</Key><Value>old.example.com</Value></String>
+				</Entry>
+			</Group>
+		</Group>
+	</Root>
+</KeePassFile>
+`, protect("alicepassword"), protect("otpauth://totp/Example:alice?secret=JBSWY3DPEHPK3PXP&issuer=Example"), getKeepassTimeForTesting("2020-01-01T00:00:00Z"), protect("oldpassword"), protect("JBSWY3DPEHPK3PXP"), protect("routerpassword"), protect("bobpassword"))
+}
+
+// getKeepassResultsForTesting returns what 'cpm search -v' shows after importing
+// keepassDocumentForTesting.
+func getKeepassResultsForTesting() []string {
+	return []string{
+		"id:        1, machine: www.example.com, service: http, user: alice, password type: plain, password: alicepassword, archived: false, created: 2020-01-01 02:00, modified: 2020-02-01 02:00, notes: PIN: 1234, strength: 0/4",
+		"id:        2, machine: www.example.com, service: http, user: alice, password type: TOTP shared secret, password: otpauth://totp/Example:alice?secret=JBSWY3DPEHPK3PXP&issuer=Example, archived: false, created: 2020-01-01 02:00, modified: 2020-02-01 02:00, notes: PIN: 1234",
+		"id:        3, machine: gitlab, service: Network, user: alice, password type: TOTP shared secret, password: JBSWY3DPEHPK3PXP, archived: false",
+		"id:        4, machine: router.lan, service: Network/Routers, user: admin, password type: plain, password: routerpassword, archived: false, expires: 2021-01-01 02:00, strength: 0/4",
+		"id:        5, machine: old.example.com, service: http, user: bob, password type: plain, password: bobpassword, archived: true, strength: 0/4",
+	}
+}
+
+// argon2KdfForTesting returns cheap Argon2 parameters.
+func argon2KdfForTesting(uuid string) map[string][]byte {
+	id, _ := hex.DecodeString(uuid)
+	return map[string][]byte{
+		"$UUID": id,
+		"S":     bytes.Repeat([]byte{0x03}, 32),
+		"I":     binary.LittleEndian.AppendUint64(nil, 2),
+		"M":     binary.LittleEndian.AppendUint64(nil, 64*1024),
+		"P":     binary.LittleEndian.AppendUint32(nil, 1),
+		"V":     binary.LittleEndian.AppendUint32(nil, 0x13),
+	}
+}
+
+// importKdbxForTesting imports `content` as a kdbx file, with the given stdin and extra arguments.
+func importKdbxForTesting(t *testing.T, content []byte, input string, args ...string) (int, string) {
+	path := filepath.Join(t.TempDir(), "passwords.kdbx")
+	err := os.WriteFile(path, content, 0600)
+	if err != nil {
+		t.Fatalf("os.WriteFile() failed: %s", err)
+	}
+	os.Args = append([]string{"", "import", "-f", "kdbx", path}, args...)
+	inBuf := bytes.NewBufferString(input)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	return actualRet, outBuf.String()
+}
+
+func TestImportKdbx(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	opts := kdbxOptionsForTesting{
+		cipher:   kdbxCipherAES,
+		kdf:      argon2KdfForTesting(kdbxKdfArgon2d),
+		compress: true,
+	}
+	content := writeKdbxForTesting(t, keepassDocumentForTesting, "secret", nil, opts)
+
+	actualRet, actualOutput := importKdbxForTesting(t, content, "secret\n")
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, actualOutput)
+	}
+	expectedOutput := "Master password: Imported 5 passwords: 5 created, 0 overwritten, 0 renamed, 0 skipped\n"
+	if actualOutput != expectedOutput {
+		t.Fatalf("Main() output is %q, want %q", actualOutput, expectedOutput)
+	}
+	results, err := readPasswords(ctx.Database, searchOptions{verbose: true})
+	if err != nil {
+		t.Fatalf("readPasswords() err = %q, want nil", err)
+	}
+	expected := getKeepassResultsForTesting()
+	if !slices.Equal(results, expected) {
+		t.Fatalf("results = %q, want %q", results, expected)
+	}
+}
+
+// The master password is read without echo from a terminal.
+func TestImportKdbxTerminal(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	oldIsTerminal := IsTerminal
+	IsTerminal = func(fd int) bool { return true }
+	t.Cleanup(func() { IsTerminal = oldIsTerminal })
+	oldReadPassword := ReadPassword
+	ReadPassword = func(fd int) ([]byte, error) { return []byte("secret"), nil }
+	t.Cleanup(func() { ReadPassword = oldReadPassword })
+	opts := kdbxOptionsForTesting{
+		cipher:   kdbxCipherAES,
+		kdf:      argon2KdfForTesting(kdbxKdfArgon2d),
+		compress: true,
+	}
+	content := writeKdbxForTesting(t, keepassDocumentForTesting, "secret", nil, opts)
+	path := filepath.Join(t.TempDir(), "passwords.kdbx")
+	err := os.WriteFile(path, content, 0600)
+	if err != nil {
+		t.Fatalf("os.WriteFile() failed: %s", err)
+	}
+	os.Args = []string{"", "import", "-f", "kdbx", path}
+	inFile, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatalf("os.Open() failed: %s", err)
+	}
+	defer inFile.Close()
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inFile, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	expectedOutput := "Master password: \nImported 5 passwords: 5 created, 0 overwritten, 0 renamed, 0 skipped\n"
+	if outBuf.String() != expectedOutput {
+		t.Fatalf("Main() output is %q, want %q", outBuf.String(), expectedOutput)
+	}
+	results, err := readPasswords(ctx.Database, searchOptions{verbose: true})
+	if err != nil {
+		t.Fatalf("readPasswords() err = %q, want nil", err)
+	}
+	expected := getKeepassResultsForTesting()
+	if !slices.Equal(results, expected) {
+		t.Fatalf("results = %q, want %q", results, expected)
+	}
+}
+
+// A keyfile instead of the master password, and the other cipher.
+func TestImportKdbxKeyfile(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	keyfileContent := `<?xml version="1.0" encoding="UTF-8"?>
+<KeyFile>
+	<Meta><Version>2.0</Version></Meta>
+	<Key><Data Hash="00000000">
+		0102030405060708 090A0B0C0D0E0F10
+		1112131415161718 191A1B1C1D1E1F20
+	</Data></Key>
+</KeyFile>
+`
+	keyfilePath := filepath.Join(t.TempDir(), "passwords.keyx")
+	err := os.WriteFile(keyfilePath, []byte(keyfileContent), 0600)
+	if err != nil {
+		t.Fatalf("os.WriteFile() failed: %s", err)
+	}
+	keyfile, err := readKeepassKeyfile([]byte(keyfileContent))
+	if err != nil {
+		t.Fatalf("readKeepassKeyfile() = %q, want nil", err)
+	}
+	opts := kdbxOptionsForTesting{
+		cipher: kdbxCipherChaCha20,
+		kdf:    argon2KdfForTesting(kdbxKdfArgon2id),
+	}
+	content := writeKdbxForTesting(t, keepassDocumentForTesting, "", keyfile, opts)
+
+	actualRet, actualOutput := importKdbxForTesting(t, content, "\n", "--keyfile", keyfilePath)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, actualOutput)
+	}
+	results, err := readPasswords(ctx.Database, searchOptions{verbose: true})
+	if err != nil {
+		t.Fatalf("readPasswords() err = %q, want nil", err)
+	}
+	expected := getKeepassResultsForTesting()
+	if !slices.Equal(results, expected) {
+		t.Fatalf("results = %q, want %q", results, expected)
+	}
+}
+
+// Import fails because of a wrong or missing master password, nothing is imported.
+func TestImportKdbxWrongPassword(t *testing.T) {
+	id, _ := hex.DecodeString(kdbxKdfAES)
+	opts := kdbxOptionsForTesting{
+		cipher: kdbxCipherAES,
+		kdf: map[string][]byte{
+			"$UUID": id,
+			"S":     bytes.Repeat([]byte{0x04}, 32),
+			"R":     binary.LittleEndian.AppendUint64(nil, 100),
+		},
+	}
+	for _, input := range []string{"wrong\n", "\n"} {
+		ctx := CreateContextForTesting(t)
+		content := writeKdbxForTesting(t, keepassDocumentForTesting, "secret", nil, opts)
+
+		actualRet, _ := importKdbxForTesting(t, content, input)
+
+		expectedRet := 1
+		if actualRet != expectedRet {
+			t.Fatalf("Main() = %q, want %q, input is %q", actualRet, expectedRet, input)
+		}
+		results, err := readPasswords(ctx.Database, searchOptions{verbose: true})
+		if err != nil {
+			t.Fatalf("readPasswords() err = %q, want nil", err)
+		}
+		if len(results) != 0 {
+			t.Fatalf("results = %q, want none", results)
+		}
+	}
+}
+
+// Sizes from a truncated or malicious file are checked before allocating memory for them.
+func TestKdbxTruncated(t *testing.T) {
+	_, _, err := readKdbxField(bytes.NewReader([]byte{0x02, 0xff, 0xff, 0xff, 0xff}))
+	if err == nil || err.Error() != "truncated file" {
+		t.Fatalf("readKdbxField() = %v, want truncated file", err)
+	}
+	_, err = parseVariantDictionary([]byte{0x00, 0x01, 0x42, 0xff, 0xff, 0xff, 0xff})
+	if err == nil || err.Error() != "truncated file" {
+		t.Fatalf("parseVariantDictionary() = %v, want truncated file", err)
+	}
+	id, _ := hex.DecodeString(kdbxKdfAES)
+	opts := kdbxOptionsForTesting{
+		cipher: kdbxCipherAES,
+		kdf: map[string][]byte{
+			"$UUID": id,
+			"S":     bytes.Repeat([]byte{0x04}, 32),
+			"R":     binary.LittleEndian.AppendUint64(nil, 100),
+		},
+	}
+	content := writeKdbxForTesting(t, keepassDocumentForTesting, "secret", nil, opts)
+	// Cut the end of the last data block, followed by an empty block of a hash and a size.
+	_, _, err = decryptKdbx(content[:len(content)-(32+4+1)], "secret", nil)
+	if err == nil || err.Error() != "truncated file" {
+		t.Fatalf("decryptKdbx() = %v, want truncated file", err)
+	}
+}
+
+func TestReadKeepassKeyfile(t *testing.T) {
+	raw := bytes.Repeat([]byte{0xab}, 32)
+	other := sha256.Sum256([]byte("some file"))
+	tests := []struct {
+		content  string
+		expected []byte
+	}{
+		{`<KeyFile><Meta><Version>1.00</Version></Meta><Key><Data>` + base64.StdEncoding.EncodeToString(raw) + `</Data></Key></KeyFile>`, raw},
+		{string(raw), raw},
+		{hex.EncodeToString(raw), raw},
+		{"some file", other[:]},
+	}
+	for _, test := range tests {
+		actual, err := readKeepassKeyfile([]byte(test.content))
+		if err != nil {
+			t.Fatalf("readKeepassKeyfile() = %q, want nil", err)
+		}
+		if !bytes.Equal(actual, test.expected) {
+			t.Fatalf("readKeepassKeyfile() = %x, want %x", actual, test.expected)
+		}
+	}
+}
+
+func TestImportKeepassXML(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	document := keepassDocumentForTesting(func(value string) string { return strings.ReplaceAll(value, "&", "&amp;") })
+	// No recycle bin: the entry is imported as a normal one.
+	document = strings.Replace(document, "<RecycleBinUUID>cmVjeWNsZWJpbnJlY3ljbA==", "<RecycleBinUUID>AAAAAAAAAAAAAAAAAAAAAA==", 1)
+	os.Args = []string{"", "import", "-f", "keepass-xml", "-"}
+	inBuf := bytes.NewBufferString(document)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	results, err := readPasswords(ctx.Database, searchOptions{verbose: true})
+	if err != nil {
+		t.Fatalf("readPasswords() err = %q, want nil", err)
+	}
+	expected := getKeepassResultsForTesting()
+	expected[4] = "id:        5, machine: old.example.com, service: Recycle Bin, user: bob, password type: plain, password: bobpassword, archived: false, strength: 0/4"
+	if !slices.Equal(results, expected) {
+		t.Fatalf("results = %q, want %q", results, expected)
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2026 Miklos Vajna
+//
+// SPDX-License-Identifier: MIT
+
+package commands
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+)
+
+const passwordStoreDir = "PASSWORD_STORE_DIR"
+
+// getPasswordStorePath returns the path of the password store of pass.
+func getPasswordStorePath() (string, error) {
+	if a := os.Getenv(passwordStoreDir); a != "" {
+		return a, nil
+	}
+
+	usr, err := user.Current()
+	if err != nil {
+		return "", fmt.Errorf("user.Current() failed: %s", err)
+	}
+
+	return filepath.Join(usr.HomeDir, ".password-store"), nil
+}
+
+// parsePassEntry turns the decrypted content of a password store file into passwords: the first line
+// is the password, otpauth:// lines are TOTP shared secrets and the rest are notes. An otpauth:// first
+// line means there is only a TOTP shared secret.
+func parsePassEntry(content string, row passwordRow) []passwordRow {
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	password := lines[0]
+	var totp string
+	if strings.HasPrefix(password, "otpauth://") {
+		totp = password
+		password = ""
+	}
+	var notes []string
+	for _, line := range lines[1:] {
+		if strings.HasPrefix(line, "otpauth://") {
+			totp = line
+			continue
+		}
+
+		notes = append(notes, line)
+	}
+	row.Notes = strings.TrimSpace(strings.Join(notes, "\n"))
+
+	return getImportedRows(row, password, totp)
+}
+
+// readPasswordStore decrypts all passwords in the password store at `dir`. The directory of a file
+// is the machine, its name is the user, outer directories are the service.
+func readPasswordStore(dir string) ([]passwordRow, error) {
+	var rows []passwordRow
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() {
+			// Skip e.g. .git.
+			if path != dir && strings.HasPrefix(entry.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !strings.HasSuffix(entry.Name(), ".gpg") {
+			return nil
+		}
+
+		relative, err := filepath.Rel(dir, path)
+		if err != nil {
+			return fmt.Errorf("filepath.Rel() failed: %s", err)
+		}
+
+		names := strings.Split(strings.TrimSuffix(filepath.ToSlash(relative), ".gpg"), "/")
+		row := passwordRow{
+			Machine: names[len(names)-1],
+			Service: "http",
+		}
+		if len(names) > 1 {
+			row.Machine = names[len(names)-2]
+			row.User = names[len(names)-1]
+		}
+		if len(names) > 2 {
+			row.Service = strings.Join(names[:len(names)-2], "/")
+		}
+
+		content, err := decryptFile(path)
+		if err != nil {
+			return fmt.Errorf("decryptFile('%s') failed: %s", relative, err)
+		}
+
+		rows = append(rows, parsePassEntry(string(content), row)...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("filepath.WalkDir() failed: %s", err)
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,137 @@
+// Copyright 2026 Miklos Vajna
+//
+// SPDX-License-Identifier: MIT
+
+package commands
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// createPasswordStoreForTesting creates a password store, CommandForTesting decrypts its files by
+// just reading them.
+func createPasswordStoreForTesting(t *testing.T) string {
+	dir := t.TempDir()
+	files := map[string]string{
+		".gpg-id":                   "alice@example.com\n",
+		".git/config.gpg":           "not a password\n",
+		"email/gmail.com/alice.gpg": "alicepassword\nPIN: 1234\notpauth://totp/Gmail:alice?secret=JBSWY3DPEHPK3PXP\n\nsecond note\n",
+		"example.org/bob.gpg":       "\notpauth://totp/Example:bob?secret=JBSWY3DPEHPK3PXP\n",
+		"example.net/carol.gpg":     "otpauth://totp/Example:carol?secret=JBSWY3DPEHPK3PXP\nrecovery in the safe\n",
+		"github.com.gpg":            "toplevelpassword\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		err := os.MkdirAll(filepath.Dir(path), 0700)
+		if err != nil {
+			t.Fatalf("os.MkdirAll() failed: %s", err)
+		}
+		err = os.WriteFile(path, []byte(content), 0600)
+		if err != nil {
+			t.Fatalf("os.WriteFile() failed: %s", err)
+		}
+	}
+	return dir
+}
+
+func TestImportPass(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	UseCommandForTesting(t)
+	dir := createPasswordStoreForTesting(t)
+	os.Args = []string{"", "import", "-f", "pass", dir}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	expectedBuf := "Imported 5 passwords: 5 created, 0 overwritten, 0 renamed, 0 skipped\n"
+	if outBuf.String() != expectedBuf {
+		t.Fatalf("Main() output is %q, want %q", outBuf.String(), expectedBuf)
+	}
+	results, err := readPasswords(ctx.Database, searchOptions{verbose: true})
+	if err != nil {
+		t.Fatalf("readPasswords() err = %q, want nil", err)
+	}
+	expected := []string{
+		"id:        1, machine: gmail.com, service: email, user: alice, password type: plain, password: alicepassword, archived: false, notes: PIN: 1234\n\nsecond note, strength: 0/4",
+		"id:        2, machine: gmail.com, service: email, user: alice, password type: TOTP shared secret, password: otpauth://totp/Gmail:alice?secret=JBSWY3DPEHPK3PXP, archived: false, notes: PIN: 1234\n\nsecond note",
+		// Only a TOTP shared secret, as written by pass-otp.
+		"id:        3, machine: example.net, service: http, user: carol, password type: TOTP shared secret, password: otpauth://totp/Example:carol?secret=JBSWY3DPEHPK3PXP, archived: false, notes: recovery in the safe",
+		"id:        4, machine: example.org, service: http, user: bob, password type: TOTP shared secret, password: otpauth://totp/Example:bob?secret=JBSWY3DPEHPK3PXP, archived: false",
+		"id:        5, machine: github.com, service: http, user: , password type: plain, password: toplevelpassword, archived: false, strength: 0/4",
+	}
+	if !slices.Equal(results, expected) {
+		t.Fatalf("results = %q, want %q", results, expected)
+	}
+}
+
+// Without a directory, the password store from the environment is imported.
+func TestImportPassDefaultDir(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	UseCommandForTesting(t)
+	t.Setenv(passwordStoreDir, createPasswordStoreForTesting(t))
+	os.Args = []string{"", "import", "-f", "pass", "-n"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	expectedBuf := "Would import 5 passwords: 5 created, 0 overwritten, 0 renamed, 0 skipped\n"
+	if outBuf.String() != expectedBuf {
+		t.Fatalf("Main() output is %q, want %q", outBuf.String(), expectedBuf)
+	}
+	results, err := readPasswords(ctx.Database, searchOptions{})
+	if err != nil {
+		t.Fatalf("readPasswords() err = %q, want nil", err)
+	}
+	if len(results) != 0 {
+		t.Fatalf("results = %q, want none", results)
+	}
+}
+
+// Import fails because the password store doesn't exist.
+func TestImportPassNoDir(t *testing.T) {
+	CreateContextForTesting(t)
+	UseCommandForTesting(t)
+	os.Args = []string{"", "import", "-f", "pass", filepath.Join(t.TempDir(), "missing")}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 1
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q", actualRet, expectedRet)
+	}
+}
+
+// TestGetPasswordStorePath checks the default location of the password store.
+func TestGetPasswordStorePath(t *testing.T) {
+	t.Setenv(passwordStoreDir, "")
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Fatalf("os.UserHomeDir() err = %q, want nil", err)
+	}
+
+	actual, err := getPasswordStorePath()
+	if err != nil {
+		t.Fatalf("getPasswordStorePath() err = %q, want nil", err)
+	}
+
+	expected := filepath.Join(home, ".password-store")
+	if actual != expected {
+		t.Fatalf("getPasswordStorePath() = %q, want %q", actual, expected)
+	}
+}
@@ -6,8 +6,12 @@ package commands
 
 import (
 	"bytes"
+	"compress/gzip"
 	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -52,3 +56,258 @@ func TestImport(t *testing.T) {
 		t.Fatalf("actualContains = %v, want %v", actualContains, expectedContains)
 	}
 }
+
+// writeXMLDatabaseForTesting writes a compressed old XML database, CommandForTesting decrypts it by
+// just reading it.
+func writeXMLDatabaseForTesting(t *testing.T, content string) string {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	_, err := writer.Write([]byte(content))
+	if err != nil {
+		t.Fatalf("writer.Write() failed: %s", err)
+	}
+	err = writer.Close()
+	if err != nil {
+		t.Fatalf("writer.Close() failed: %s", err)
+	}
+	path := filepath.Join(t.TempDir(), "cpmdb")
+	err = os.WriteFile(path, buf.Bytes(), 0600)
+	if err != nil {
+		t.Fatalf("os.WriteFile() failed: %s", err)
+	}
+	return path
+}
+
+// Accented labels are decoded from ISO-8859-1, importing again only imports the new passwords.
+func TestImportXMLPath(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	UseCommandForTesting(t)
+	path := writeXMLDatabaseForTesting(t, "<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?>\n"+
+		"<root><node label=\"caf\xe9\"><node label=\"http\"><node label=\"myuser\"><node label=\"mypassword\"/></node></node></node></root>\n")
+	expectedBufs := []string{
+		"Imported 1 passwords: 1 created, 0 overwritten, 0 renamed, 0 skipped\n",
+		"Imported 0 passwords: 0 created, 0 overwritten, 0 renamed, 1 skipped\n",
+	}
+	for _, expectedBuf := range expectedBufs {
+		os.Args = []string{"", "import", path}
+		inBuf := new(bytes.Buffer)
+		outBuf := new(bytes.Buffer)
+
+		actualRet := Main(inBuf, outBuf)
+
+		expectedRet := 0
+		if actualRet != expectedRet {
+			t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+		}
+		if outBuf.String() != expectedBuf {
+			t.Fatalf("Main() output is %q, want %q", outBuf.String(), expectedBuf)
+		}
+	}
+	results, err := readPasswords(ctx.Database, searchOptions{verbose: true})
+	if err != nil {
+		t.Fatalf("readPasswords() err = %q, want nil", err)
+	}
+	expected := []string{
+		"id:        1, machine: café, service: http, user: myuser, password type: plain, password: mypassword, archived: false, created: 2020-05-10 00:00, modified: 2020-05-10 00:00, strength: 0/4",
+	}
+	if !slices.Equal(results, expected) {
+		t.Fatalf("results = %q, want %q", results, expected)
+	}
+}
+
+// Dry run reports the passwords of the old XML database, without importing anything.
+func TestImportXMLDryRun(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	UseCommandForTesting(t)
+	os.Args = []string{"", "import", "--dry-run"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	expectedBuf := "Would import 2 passwords: 2 created, 0 overwritten, 0 renamed, 0 skipped\n"
+	if outBuf.String() != expectedBuf {
+		t.Fatalf("Main() output is %q, want %q", outBuf.String(), expectedBuf)
+	}
+	results, err := readPasswords(ctx.Database, searchOptions{})
+	if err != nil {
+		t.Fatalf("readPasswords() err = %q, want nil", err)
+	}
+	if len(results) != 0 {
+		t.Fatalf("results = %q, want none", results)
+	}
+}
+
+// Import fails because the database is not compressed, has an unknown encoding or is missing.
+func TestImportXMLInvalid(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	UseCommandForTesting(t)
+	uncompressed := filepath.Join(t.TempDir(), "cpmdb")
+	err := os.WriteFile(uncompressed, []byte("<root/>\n"), 0600)
+	if err != nil {
+		t.Fatalf("os.WriteFile() failed: %s", err)
+	}
+	paths := []string{
+		uncompressed,
+		writeXMLDatabaseForTesting(t, "<?xml version=\"1.0\" encoding=\"KOI8-R\"?>\n<root/>\n"),
+		filepath.Join(t.TempDir(), "missing"),
+	}
+	for _, path := range paths {
+		os.Args = []string{"", "import", path}
+		inBuf := new(bytes.Buffer)
+		outBuf := new(bytes.Buffer)
+
+		actualRet := Main(inBuf, outBuf)
+
+		expectedRet := 1
+		if actualRet != expectedRet {
+			t.Fatalf("Main() = %q, want %q, path is %q", actualRet, expectedRet, path)
+		}
+	}
+	results, err := readPasswords(ctx.Database, searchOptions{})
+	if err != nil {
+		t.Fatalf("readPasswords() err = %q, want nil", err)
+	}
+	if len(results) != 0 {
+		t.Fatalf("results = %q, want none", results)
+	}
+}
+
+// importConflictsForTesting imports passwords which conflict with existing ones using `strategy`,
+// and returns the output and the resulting passwords.
+func importConflictsForTesting(t *testing.T, strategy string, extraArgs ...string) (int, string, []string) {
+	ctx := CreateContextForTesting(t)
+	_, err := ctx.Database.Exec(`insert into passwords (id, machine, service, user, password, type) values(1, 'machine1', 'http', 'myuser', 'password1', 'plain');
+	                             insert into passwords (id, machine, service, user, password, type) values(2, 'machine2', 'http', 'myuser', 'password2', 'plain');`)
+	if err != nil {
+		t.Fatalf("db.Exec() = %q, want nil", err)
+	}
+	// Same ID and same machine/service/user/type; only the same machine/service/user/type; only
+	// the same ID; no conflict; no ID and the same machine/service/user/type as the previous one;
+	// only the same ID as an imported password.
+	input := `[{"ID":1,"Machine":"machine1","Service":"http","User":"myuser","Password":"new1","PasswordType":"plain"},
+	           {"ID":5,"Machine":"machine2","Service":"http","User":"myuser","Password":"new2","PasswordType":"plain"},
+	           {"ID":2,"Machine":"machine3","Service":"http","User":"myuser","Password":"new3","PasswordType":"plain"},
+	           {"Machine":"machine4","Service":"http","User":"myuser","Password":"new4","PasswordType":"plain"},
+	           {"Machine":"machine4","Service":"http","User":"myuser","Password":"new5","PasswordType":"plain"},
+	           {"ID":1,"Machine":"machine5","Service":"http","User":"myuser","Password":"new6","PasswordType":"plain"}]`
+	os.Args = append([]string{"", "import", "-f", "json", "--on-conflict", strategy, "-"}, extraArgs...)
+	inBuf := bytes.NewBufferString(input)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	results, err := readPasswords(ctx.Database, searchOptions{})
+	if err != nil {
+		t.Fatalf("readPasswords() err = %q, want nil", err)
+	}
+	return actualRet, outBuf.String(), results
+}
+
+func TestImportConflictFail(t *testing.T) {
+	actualRet, _, results := importConflictsForTesting(t, "fail")
+
+	if actualRet != 1 {
+		t.Fatalf("Main() = %q, want 1", actualRet)
+	}
+	if len(results) != 2 {
+		t.Fatalf("results = %q, want the original 2 passwords", results)
+	}
+}
+
+func TestImportConflictSkip(t *testing.T) {
+	actualRet, output, results := importConflictsForTesting(t, "skip")
+
+	if actualRet != 0 {
+		t.Fatalf("Main() = %q, want 0, output is %q", actualRet, output)
+	}
+	expectedOutput := "Imported 1 passwords: 1 created, 0 overwritten, 0 renamed, 5 skipped\n"
+	if output != expectedOutput {
+		t.Fatalf("output = %q, want %q", output, expectedOutput)
+	}
+	expected := []string{
+		"id:        1, machine: machine1, service: http, user: myuser, password type: plain, password: password1",
+		"id:        2, machine: machine2, service: http, user: myuser, password type: plain, password: password2",
+		"id:        3, machine: machine4, service: http, user: myuser, password type: plain, password: new4",
+	}
+	if strings.Join(results, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("results = %q, want %q", results, expected)
+	}
+}
+
+func TestImportConflictOverwrite(t *testing.T) {
+	actualRet, output, results := importConflictsForTesting(t, "overwrite")
+
+	if actualRet != 0 {
+		t.Fatalf("Main() = %q, want 0, output is %q", actualRet, output)
+	}
+	// Overwriting password 2 with ID 5 frees up ID 2. ID 1 belongs to a different password, so
+	// machine5 gets a new ID instead of replacing it.
+	expectedOutput := "Imported 6 passwords: 3 created, 3 overwritten, 0 renamed, 0 skipped\n"
+	if output != expectedOutput {
+		t.Fatalf("output = %q, want %q", output, expectedOutput)
+	}
+	expected := []string{
+		"id:        1, machine: machine1, service: http, user: myuser, password type: plain, password: new1",
+		"id:        2, machine: machine3, service: http, user: myuser, password type: plain, password: new3",
+		"id:        5, machine: machine2, service: http, user: myuser, password type: plain, password: new2",
+		"id:        7, machine: machine4, service: http, user: myuser, password type: plain, password: new5",
+		"id:        8, machine: machine5, service: http, user: myuser, password type: plain, password: new6",
+	}
+	if strings.Join(results, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("results = %q, want %q", results, expected)
+	}
+}
+
+func TestImportConflictRename(t *testing.T) {
+	actualRet, output, results := importConflictsForTesting(t, "rename")
+
+	if actualRet != 0 {
+		t.Fatalf("Main() = %q, want 0, output is %q", actualRet, output)
+	}
+	expectedOutput := "Imported 6 passwords: 1 created, 0 overwritten, 5 renamed, 0 skipped\n"
+	if output != expectedOutput {
+		t.Fatalf("output = %q, want %q", output, expectedOutput)
+	}
+	expected := []string{
+		"id:        1, machine: machine1, service: http, user: myuser, password type: plain, password: password1",
+		"id:        2, machine: machine2, service: http, user: myuser, password type: plain, password: password2",
+		"id:        3, machine: machine1, service: http, user: myuser (2), password type: plain, password: new1",
+		"id:        5, machine: machine2, service: http, user: myuser (2), password type: plain, password: new2",
+		"id:        6, machine: machine3, service: http, user: myuser, password type: plain, password: new3",
+		"id:        7, machine: machine4, service: http, user: myuser, password type: plain, password: new4",
+		"id:        8, machine: machine4, service: http, user: myuser (2), password type: plain, password: new5",
+		"id:        9, machine: machine5, service: http, user: myuser, password type: plain, password: new6",
+	}
+	if strings.Join(results, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("results = %q, want %q", results, expected)
+	}
+}
+
+func TestImportConflictDryRun(t *testing.T) {
+	actualRet, output, results := importConflictsForTesting(t, "skip", "-n")
+
+	if actualRet != 0 {
+		t.Fatalf("Main() = %q, want 0, output is %q", actualRet, output)
+	}
+	expectedOutput := "Would import 1 passwords: 1 created, 0 overwritten, 0 renamed, 5 skipped\n"
+	if output != expectedOutput {
+		t.Fatalf("output = %q, want %q", output, expectedOutput)
+	}
+	if len(results) != 2 {
+		t.Fatalf("results = %q, want the original 2 passwords", results)
+	}
+}
+
+// Import fails because the strategy is invalid.
+func TestImportConflictInvalid(t *testing.T) {
+	actualRet, _, _ := importConflictsForTesting(t, "foo")
+
+	if actualRet != 1 {
+		t.Fatalf("Main() = %q, want 1", actualRet)
+	}
+}
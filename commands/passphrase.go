@@ -0,0 +1,76 @@
+// Copyright 2026 Miklos Vajna
+//
+// SPDX-License-Identifier: MIT
+
+package commands
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math"
+	"math/big"
+	"os"
+	"strings"
+)
+
+// parseWordlist parses a diceware word list: each line is either "11111<tab>word" as in the EFF
+// lists, or just "word". Duplicates are ignored, so they don't inflate the entropy.
+func parseWordlist(content string) []string {
+	var words []string
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		word := fields[len(fields)-1]
+		if seen[word] {
+			continue
+		}
+		seen[word] = true
+		words = append(words, word)
+	}
+	return words
+}
+
+// readWordlist returns the words of the word list at `path`, or of the embedded EFF large word list
+// if `path` is empty.
+func readWordlist(path string) ([]string, error) {
+	content := EffLargeWordlist
+	if len(path) > 0 {
+		buf, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("os.ReadFile() failed: %s", err)
+		}
+		content = string(buf)
+	}
+
+	words := parseWordlist(content)
+	if len(words) < 2 {
+		return nil, fmt.Errorf("word list has %d unique words, need at least 2", len(words))
+	}
+
+	return words, nil
+}
+
+// generatePassphrase picks `count` random words from `words` and returns them joined by
+// `separator`, together with the entropy of the passphrase in bits.
+func generatePassphrase(words []string, count int, separator string) (string, float64, error) {
+	if count < 1 {
+		return "", 0, fmt.Errorf("number of words must be positive, got %d", count)
+	}
+
+	var picked []string
+	size := big.NewInt(int64(len(words)))
+	for i := 0; i < count; i++ {
+		index, err := RandInt(rand.Reader, size)
+		if err != nil {
+			return "", 0, fmt.Errorf("RandInt() failed: %s", err)
+		}
+		picked = append(picked, words[index.Int64()])
+	}
+
+	entropy := float64(count) * math.Log2(float64(len(words)))
+	return strings.Join(picked, separator), entropy, nil
+}
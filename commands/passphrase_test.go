@@ -0,0 +1,206 @@
+// Copyright 2026 Miklos Vajna
+//
+// SPDX-License-Identifier: MIT
+
+package commands
+
+import (
+	"bytes"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// UseRandIntForTesting makes RandInt() return 0, 1, 2, ... instead of random values.
+func UseRandIntForTesting(t *testing.T) {
+	next := int64(0)
+	oldRandInt := RandInt
+	RandInt = func(rand io.Reader, max *big.Int) (*big.Int, error) {
+		ret := big.NewInt(next % max.Int64())
+		next++
+		return ret, nil
+	}
+	t.Cleanup(func() { RandInt = oldRandInt })
+}
+
+func TestReadWordlistEmbedded(t *testing.T) {
+	words, err := readWordlist("")
+
+	if err != nil {
+		t.Fatalf("readWordlist() err = %q, want nil", err)
+	}
+	if len(words) != 7776 {
+		t.Fatalf("len(words) = %v, want 7776", len(words))
+	}
+	if words[0] != "abacus" || words[7775] != "zoom" {
+		t.Fatalf("words[0] = %q, words[7775] = %q", words[0], words[7775])
+	}
+}
+
+func TestReadWordlistFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wordlist.txt")
+	// Both the diceware and the plain format is accepted, duplicates are ignored.
+	err := os.WriteFile(path, []byte("11111\tfoo\n\nbar\nfoo\nbaz\n"), 0600)
+	if err != nil {
+		t.Fatalf("os.WriteFile() failed: %s", err)
+	}
+
+	words, err := readWordlist(path)
+
+	if err != nil {
+		t.Fatalf("readWordlist() err = %q, want nil", err)
+	}
+	if len(words) != 3 || words[0] != "foo" || words[1] != "bar" || words[2] != "baz" {
+		t.Fatalf("words = %q, want [foo bar baz]", words)
+	}
+}
+
+func TestGeneratePassphrase(t *testing.T) {
+	UseRandIntForTesting(t)
+	words := []string{"a", "b", "c", "d"}
+
+	passphrase, entropy, err := generatePassphrase(words, 3, "_")
+
+	if err != nil {
+		t.Fatalf("generatePassphrase() err = %q, want nil", err)
+	}
+	if passphrase != "a_b_c" {
+		t.Fatalf("passphrase = %q, want %q", passphrase, "a_b_c")
+	}
+	// 2 bits per word.
+	if entropy != 6 {
+		t.Fatalf("entropy = %v, want 6", entropy)
+	}
+}
+
+func TestInsertPassphrase(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	UseRandIntForTesting(t)
+	os.Args = []string{"", "create", "-m", "mymachine", "-u", "myuser", "--passphrase"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	// 6 words from a list of 7776 words: 6 * log2(7776) bits.
+	expectedBuf := "Created 1 password\nGenerated password: abacus-abdomen-abdominal-abide-abiding-ability\nEntropy: 77.5 bits\n"
+	if outBuf.String() != expectedBuf {
+		t.Fatalf("Main() output is %q, want %q", outBuf.String(), expectedBuf)
+	}
+	results, err := readPasswords(ctx.Database, searchOptions{noid: true})
+	if err != nil {
+		t.Fatalf("readPasswords() err = %q, want nil", err)
+	}
+	expected := "machine: mymachine, service: http, user: myuser, password type: plain, password: abacus-abdomen-abdominal-abide-abiding-ability"
+	if !ContainsString(results, expected) {
+		t.Fatalf("results = %q, want to contain %q", results, expected)
+	}
+}
+
+func TestInsertPassphraseWordlist(t *testing.T) {
+	CreateContextForTesting(t)
+	UseRandIntForTesting(t)
+	path := filepath.Join(t.TempDir(), "wordlist.txt")
+	err := os.WriteFile(path, []byte("foo\nbar\n"), 0600)
+	if err != nil {
+		t.Fatalf("os.WriteFile() failed: %s", err)
+	}
+	os.Args = []string{"", "create", "-m", "mymachine", "-u", "myuser", "--passphrase", "--words", "3", "--separator", " ", "--wordlist", path}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	expectedBuf := "Created 1 password\nGenerated password: foo bar foo\nEntropy: 3.0 bits\n"
+	if outBuf.String() != expectedBuf {
+		t.Fatalf("Main() output is %q, want %q", outBuf.String(), expectedBuf)
+	}
+}
+
+// Insert fails because the word list has a single word.
+func TestInsertPassphraseWordlistTooSmall(t *testing.T) {
+	CreateContextForTesting(t)
+	path := filepath.Join(t.TempDir(), "wordlist.txt")
+	err := os.WriteFile(path, []byte("foo\nfoo\n"), 0600)
+	if err != nil {
+		t.Fatalf("os.WriteFile() failed: %s", err)
+	}
+	os.Args = []string{"", "create", "-m", "mymachine", "-u", "myuser", "--passphrase", "--wordlist", path}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 1
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q", actualRet, expectedRet)
+	}
+}
+
+// Insert fails because a passphrase needs at least one word.
+func TestInsertPassphraseNoWords(t *testing.T) {
+	CreateContextForTesting(t)
+	os.Args = []string{"", "create", "-m", "mymachine", "-u", "myuser", "--passphrase", "--words", "0"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 1
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q", actualRet, expectedRet)
+	}
+}
+
+func TestUpdatePassphrase(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	UseRandIntForTesting(t)
+	_, err := createPassword(&ctx, "mymachine", "myservice", "myuser", "oldpassword", PasswordTypePlain, false, "", 0, "")
+	if err != nil {
+		t.Fatalf("createPassword() = %q, want nil", err)
+	}
+	// No -p: --passphrase implies generating a new password.
+	os.Args = []string{"", "update", "-i", "1", "--passphrase", "--words", "2"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	expectedBuf := "Updated 1 password\nGenerated password: abacus-abdomen\nEntropy: 25.8 bits\n"
+	if outBuf.String() != expectedBuf {
+		t.Fatalf("Main() output is %q, want %q", outBuf.String(), expectedBuf)
+	}
+}
+
+// Update fails because generating a passphrase failed.
+func TestUpdatePassphraseNoWords(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	_, err := createPassword(&ctx, "mymachine", "myservice", "myuser", "oldpassword", PasswordTypePlain, false, "", 0, "")
+	if err != nil {
+		t.Fatalf("createPassword() = %q, want nil", err)
+	}
+	os.Args = []string{"", "update", "-i", "1", "--passphrase", "--words", "0"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 1
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q", actualRet, expectedRet)
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright 2026 Miklos Vajna
+//
+// SPDX-License-Identifier: MIT
+
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/sethvargo/go-password/password"
+	"github.com/spf13/cobra"
+)
+
+// passwordPolicy describes the rules of a site for generated passwords.
+type passwordPolicy struct {
+	Length      int
+	Digits      int
+	Symbols     int
+	SymbolSet   string
+	NoUpper     bool
+	AllowRepeat bool
+}
+
+// getDefaultPolicy returns the built-in password generation rules.
+func getDefaultPolicy(secure bool) passwordPolicy {
+	// Length of 15 and no symbols matches current Firefox and `pwgen --secure 15 1`.
+	policy := passwordPolicy{
+		Length: 15,
+		Digits: 3,
+	}
+	if secure {
+		policy.Symbols = 3
+	}
+	return policy
+}
+
+// UnmarshalJSON parses a policy from the config, keys not specified keep their default value.
+func (p *passwordPolicy) UnmarshalJSON(data []byte) error {
+	type plainPolicy passwordPolicy
+	policy := plainPolicy(getDefaultPolicy(false))
+	err := json.Unmarshal(data, &policy)
+	if err != nil {
+		return fmt.Errorf("json.Unmarshal() failed: %s", err)
+	}
+
+	*p = passwordPolicy(policy)
+	return nil
+}
+
+// generatePolicyPassword generates a new password, following the rules of `policy`.
+func generatePolicyPassword(policy passwordPolicy) (string, error) {
+	var output string
+	var err error
+	if len(policy.SymbolSet) > 0 {
+		var generator *password.Generator
+		generator, err = password.NewGenerator(&password.GeneratorInput{Symbols: policy.SymbolSet})
+		if err != nil {
+			return "", fmt.Errorf("password.NewGenerator() failed: %s", err)
+		}
+
+		output, err = generator.Generate(policy.Length, policy.Digits, policy.Symbols, policy.NoUpper, policy.AllowRepeat)
+	} else {
+		output, err = GeneratePassword(policy.Length, policy.Digits, policy.Symbols, policy.NoUpper, policy.AllowRepeat)
+	}
+	if err != nil {
+		return "", fmt.Errorf("password.Generate() failed: %s", err)
+	}
+
+	return strings.TrimSpace(output), nil
+}
+
+// policyFlags are the password generation switches of create and update.
+type policyFlags struct {
+	policy      string
+	length      int
+	digits      int
+	symbols     int
+	symbolSet   string
+	noUpper     bool
+	allowRepeat bool
+	passphrase  bool
+	words       int
+	separator   string
+	wordlist    string
+}
+
+func addPolicyFlags(cmd *cobra.Command, flags *policyFlags) {
+	defaults := getDefaultPolicy(false)
+	cmd.Flags().StringVarP(&flags.policy, "policy", "", "", `name of a password generation policy from the config (default: the policy of the machine)`)
+	cmd.Flags().IntVarP(&flags.length, "length", "", defaults.Length, `length of the generated password`)
+	cmd.Flags().IntVarP(&flags.digits, "digits", "", defaults.Digits, `number of digits in the generated password`)
+	cmd.Flags().IntVarP(&flags.symbols, "symbols", "", defaults.Symbols, `number of symbols in the generated password`)
+	cmd.Flags().StringVarP(&flags.symbolSet, "symbol-set", "", "", `allowed symbols in the generated password (default: all)`)
+	cmd.Flags().BoolVarP(&flags.noUpper, "no-upper", "", defaults.NoUpper, `exclude uppercase letters from the generated password (default: false)`)
+	cmd.Flags().BoolVarP(&flags.allowRepeat, "allow-repeat", "", defaults.AllowRepeat, `allow repeating characters in the generated password (default: false)`)
+	cmd.Flags().BoolVarP(&flags.passphrase, "passphrase", "", false, `generate a diceware passphrase of random words instead of a password (default: false)`)
+	cmd.Flags().IntVarP(&flags.words, "words", "", 6, `number of words in the generated passphrase`)
+	cmd.Flags().StringVarP(&flags.separator, "separator", "", "-", `separator between the words of the generated passphrase`)
+	cmd.Flags().StringVarP(&flags.wordlist, "wordlist", "", "", `word list file for the generated passphrase (default: the EFF large word list)`)
+}
+
+// getPasswordPolicy decides the password generation rules: explicit switches override the named
+// policy, which defaults to the policy attached to the machine, which defaults to the built-in rules.
+func getPasswordPolicy(cmd *cobra.Command, flags policyFlags, machine string, secure bool) (passwordPolicy, error) {
+	policy := getDefaultPolicy(secure)
+	c, err := loadConfig()
+	if err != nil {
+		return policy, fmt.Errorf("loadConfig() failed: %s", err)
+	}
+
+	name := flags.policy
+	if len(name) == 0 {
+		name = c.MachinePolicies[machine]
+	}
+	if len(name) > 0 {
+		var ok bool
+		policy, ok = c.Policies[name]
+		if !ok {
+			return policy, fmt.Errorf("no policy named '%s' in the config", name)
+		}
+		if secure {
+			policy.Symbols = 3
+		}
+	}
+
+	if cmd.Flags().Changed("length") {
+		policy.Length = flags.length
+	}
+	if cmd.Flags().Changed("digits") {
+		policy.Digits = flags.digits
+	}
+	if cmd.Flags().Changed("symbols") {
+		policy.Symbols = flags.symbols
+	}
+	if cmd.Flags().Changed("symbol-set") {
+		policy.SymbolSet = flags.symbolSet
+	}
+	if cmd.Flags().Changed("no-upper") {
+		policy.NoUpper = flags.noUpper
+	}
+	if cmd.Flags().Changed("allow-repeat") {
+		policy.AllowRepeat = flags.allowRepeat
+	}
+
+	return policy, nil
+}
+
+// generateFlagsPassword generates a new password following the switches: either a passphrase or a
+// password according to the policy. The second return value is the entropy of a passphrase in bits,
+// 0 for a password.
+func generateFlagsPassword(cmd *cobra.Command, flags policyFlags, machine string, secure bool) (string, float64, error) {
+	if flags.passphrase {
+		words, err := readWordlist(flags.wordlist)
+		if err != nil {
+			return "", 0, fmt.Errorf("readWordlist() failed: %s", err)
+		}
+
+		passphrase, entropy, err := generatePassphrase(words, flags.words, flags.separator)
+		if err != nil {
+			return "", 0, fmt.Errorf("generatePassphrase() failed: %s", err)
+		}
+
+		return passphrase, entropy, nil
+	}
+
+	policy, err := getPasswordPolicy(cmd, flags, machine, secure)
+	if err != nil {
+		return "", 0, fmt.Errorf("getPasswordPolicy() failed: %s", err)
+	}
+
+	password, err := generatePolicyPassword(policy)
+	if err != nil {
+		return "", 0, fmt.Errorf("generatePolicyPassword() failed: %s", err)
+	}
+
+	return password, 0, nil
+}
@@ -0,0 +1,154 @@
+// Copyright 2026 Miklos Vajna
+//
+// SPDX-License-Identifier: MIT
+
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+// UseGeneratePasswordRecorderForTesting records the arguments of GeneratePassword().
+func UseGeneratePasswordRecorderForTesting(t *testing.T) *string {
+	var actualArgs string
+	oldGeneratePassword := GeneratePassword
+	GeneratePassword = func(length, numDigits, numSymbols int, noUpper, allowRepeat bool) (string, error) {
+		actualArgs = fmt.Sprintf("length: %d, digits: %d, symbols: %d, no upper: %v, allow repeat: %v", length, numDigits, numSymbols, noUpper, allowRepeat)
+		return "output-from-pwgen", nil
+	}
+	t.Cleanup(func() { GeneratePassword = oldGeneratePassword })
+	return &actualArgs
+}
+
+func TestLoadConfigPolicyDefaults(t *testing.T) {
+	UseConfigForTesting(t, `{"Policies": {"mypolicy": {"Length": 24, "NoUpper": true}}}`)
+
+	c, err := loadConfig()
+
+	if err != nil {
+		t.Fatalf("loadConfig() err = %q, want nil", err)
+	}
+	actual := c.Policies["mypolicy"]
+	// Digits is not specified, so it has its default value.
+	expected := passwordPolicy{Length: 24, Digits: 3, NoUpper: true}
+	if actual != expected {
+		t.Fatalf("actual = %v, want %v", actual, expected)
+	}
+}
+
+func TestInsertPolicyFlags(t *testing.T) {
+	CreateContextForTesting(t)
+	actualArgs := UseGeneratePasswordRecorderForTesting(t)
+	os.Args = []string{"", "create", "-m", "mymachine", "-u", "myuser", "--length", "20", "--digits", "0", "--symbols", "2", "--no-upper", "--allow-repeat"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	expectedArgs := "length: 20, digits: 0, symbols: 2, no upper: true, allow repeat: true"
+	if *actualArgs != expectedArgs {
+		t.Fatalf("actualArgs = %q, want %q", *actualArgs, expectedArgs)
+	}
+	expectedBuf := "Created 1 password\nGenerated password: output-from-pwgen\n"
+	if outBuf.String() != expectedBuf {
+		t.Fatalf("Main() output is %q, want %q", outBuf.String(), expectedBuf)
+	}
+}
+
+func TestInsertPolicySymbolSet(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	os.Args = []string{"", "create", "-m", "mymachine", "-u", "myuser", "--length", "20", "--symbols", "3", "--symbol-set", "-_."}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	results, err := readPasswords(ctx.Database, searchOptions{quiet: true})
+	if err != nil {
+		t.Fatalf("readPasswords() err = %q, want nil", err)
+	}
+	password := results[0]
+	if len(password) != 20 {
+		t.Fatalf("len(password) = %v, want 20", len(password))
+	}
+	symbols := 0
+	for _, r := range password {
+		if strings.ContainsRune("-_.", r) {
+			symbols++
+		} else if !strings.ContainsRune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789", r) {
+			t.Fatalf("password = %q, unexpected character %q", password, r)
+		}
+	}
+	if symbols != 3 {
+		t.Fatalf("symbols = %v, want 3", symbols)
+	}
+}
+
+func TestInsertPolicyConfig(t *testing.T) {
+	CreateContextForTesting(t)
+	UseConfigForTesting(t, `{
+		"Policies": {
+			"nosymbols": {"Length": 24, "Symbols": 0},
+			"short": {"Length": 8}
+		},
+		"MachinePolicies": {"mymachine": "nosymbols"}
+	}`)
+	actualArgs := UseGeneratePasswordRecorderForTesting(t)
+	tests := []struct {
+		args         []string
+		expectedArgs string
+	}{
+		// Policy of the machine.
+		{[]string{"", "create", "-m", "mymachine", "-u", "myuser1"}, "length: 24, digits: 3, symbols: 0, no upper: false, allow repeat: false"},
+		// Explicit switches override the policy of the machine.
+		{[]string{"", "create", "-m", "mymachine", "-u", "myuser2", "--length", "30"}, "length: 30, digits: 3, symbols: 0, no upper: false, allow repeat: false"},
+		// Explicit policy, combined with --secure.
+		{[]string{"", "create", "-m", "othermachine", "-u", "myuser", "--policy", "short", "-y"}, "length: 8, digits: 3, symbols: 3, no upper: false, allow repeat: false"},
+		// Update uses the policy of the machine as well.
+		{[]string{"", "update", "-i", "1", "-p", "-"}, "length: 24, digits: 3, symbols: 0, no upper: false, allow repeat: false"},
+		{[]string{"", "update", "-i", "1", "-p", "-", "--digits", "5"}, "length: 24, digits: 5, symbols: 0, no upper: false, allow repeat: false"},
+	}
+	for _, test := range tests {
+		os.Args = test.args
+		inBuf := new(bytes.Buffer)
+		outBuf := new(bytes.Buffer)
+
+		actualRet := Main(inBuf, outBuf)
+
+		expectedRet := 0
+		if actualRet != expectedRet {
+			t.Fatalf("Main(%v) = %q, want %q, output is %q", test.args, actualRet, expectedRet, outBuf.String())
+		}
+		if *actualArgs != test.expectedArgs {
+			t.Fatalf("Main(%v): actualArgs = %q, want %q", test.args, *actualArgs, test.expectedArgs)
+		}
+	}
+}
+
+// Insert fails because the policy is not in the config.
+func TestInsertPolicyMissing(t *testing.T) {
+	CreateContextForTesting(t)
+	UseConfigForTesting(t, `{"Policies": {"short": {"Length": 8}}}`)
+	os.Args = []string{"", "create", "-m", "mymachine", "-u", "myuser", "--policy", "long"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 1
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q", actualRet, expectedRet)
+	}
+}
@@ -21,6 +21,8 @@ func TestPull(t *testing.T) {
 	oldGeneratePassword := GeneratePassword
 	GeneratePassword = GeneratePasswordForTesting
 	t.Cleanup(func() { GeneratePassword = oldGeneratePassword })
+	// Don't depend on the config of the user running the tests.
+	t.Setenv(xdgConfigHome, t.TempDir())
 	expectedMachine := "mymachine"
 	expectedUser := "myuser"
 	os.Args = []string{"", "create", "-m", expectedMachine, "-u", expectedUser}
@@ -0,0 +1,222 @@
+// Copyright 2026 Miklos Vajna
+//
+// SPDX-License-Identifier: MIT
+
+package commands
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"image"
+	// register image decoders
+	_ "image/jpeg"
+	_ "image/png"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/makiuchi-d/gozxing"
+	"github.com/makiuchi-d/gozxing/qrcode"
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+	"rsc.io/qr"
+)
+
+// QrcodeFormat is an enum of possible QR code image file formats.
+type QrcodeFormat string
+
+const (
+	// QrcodeFormatPng is a raster PNG image.
+	QrcodeFormatPng QrcodeFormat = "png"
+	// QrcodeFormatSvg is a vector SVG image, suitable for printing.
+	QrcodeFormatSvg QrcodeFormat = "svg"
+)
+
+func (f *QrcodeFormat) String() string {
+	return string(*f)
+}
+
+// Set sets the value of `f` from `v`.
+func (f *QrcodeFormat) Set(v string) error {
+	switch v {
+	case "png", "svg":
+		*f = QrcodeFormat(v)
+		return nil
+	default:
+		return errors.New(`must be one of "png", or "svg"`)
+	}
+}
+
+// Type returns the type of `f` as a string.
+func (f *QrcodeFormat) Type() string {
+	return "QrcodeFormat"
+}
+
+// decodeQrImage decodes the text of a QR code from a PNG or JPEG image file, locally.
+func decodeQrImage(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("os.Open() failed: %s", err)
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return "", fmt.Errorf("image.Decode() failed: %s", err)
+	}
+
+	bitmap, err := gozxing.NewBinaryBitmapFromImage(img)
+	if err != nil {
+		return "", fmt.Errorf("gozxing.NewBinaryBitmapFromImage() failed: %s", err)
+	}
+
+	result, err := qrcode.NewQRCodeReader().Decode(bitmap, nil)
+	if err != nil {
+		return "", fmt.Errorf("QRCodeReader.Decode() failed: %s", err)
+	}
+
+	return result.GetText(), nil
+}
+
+// parseTotpURL validates an otpauth:// URL that contains a TOTP shared secret.
+func parseTotpURL(s string) (*otp.Key, error) {
+	if !strings.HasPrefix(s, "otpauth://") {
+		return nil, fmt.Errorf("'%s' is not an otpauth:// URL", s)
+	}
+
+	key, err := otp.NewKeyFromURL(s)
+	if err != nil {
+		return nil, fmt.Errorf("otp.NewKeyFromURL() failed: %s", err)
+	}
+
+	if key.Type() != "totp" {
+		return nil, fmt.Errorf("unexpected OTP type: '%s'", key.Type())
+	}
+
+	sharedSecret, err := parsePassword(s)
+	if err != nil {
+		return nil, fmt.Errorf("parsePassword() failed: %s", err)
+	}
+
+	_, err = totp.GenerateCode(sharedSecret, Now())
+	if err != nil {
+		return nil, fmt.Errorf("totp.GenerateCode() failed: %s", err)
+	}
+
+	return key, nil
+}
+
+// readQrImage decodes a QR code image and validates that it contains a TOTP shared secret.
+func readQrImage(path string) (*otp.Key, error) {
+	text, err := decodeQrImage(path)
+	if err != nil {
+		return nil, fmt.Errorf("decodeQrImage() failed: %s", err)
+	}
+
+	key, err := parseTotpURL(text)
+	if err != nil {
+		return nil, fmt.Errorf("parseTotpURL() failed: %s", err)
+	}
+
+	return key, nil
+}
+
+// getTotpURL returns an otpauth:// URL for a TOTP shared secret: either the stored URL as-is or one
+// built from the machine and the user if only a bare secret is stored.
+func getTotpURL(machine, user, password string) string {
+	if strings.HasPrefix(password, "otpauth://") {
+		return password
+	}
+
+	u := url.URL{
+		Scheme: "otpauth",
+		Host:   "totp",
+		Path:   "/" + machine + ":" + user,
+	}
+	query := url.Values{}
+	// Strip spaces, oathtool does this as well.
+	query.Set("secret", strings.ReplaceAll(password, " ", ""))
+	query.Set("issuer", machine)
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// encodeQrcodeSvg renders a QR code as an SVG image, including the usual 4 modules of quiet zone.
+func encodeQrcodeSvg(code *qr.Code) []byte {
+	size := code.Size + 8
+	var sb strings.Builder
+	sb.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" width="%d" height="%d" shape-rendering="crispEdges">`+"\n", size, size, size*code.Scale, size*code.Scale)
+	fmt.Fprintf(&sb, `<rect width="%d" height="%d" fill="#ffffff"/>`+"\n", size, size)
+	sb.WriteString(`<path fill="#000000" d="`)
+	for y := 0; y < code.Size; y++ {
+		for x := 0; x < code.Size; x++ {
+			if code.Black(x, y) {
+				fmt.Fprintf(&sb, "M%d,%dh1v1h-1z", x+4, y+4)
+			}
+		}
+	}
+	sb.WriteString(`"/>` + "\n")
+	sb.WriteString("</svg>\n")
+	return []byte(sb.String())
+}
+
+// writeQrcodeFile writes a TOTP shared secret as a QR code image file to `dir`, named after the
+// machine, the user and the ID. An existing file is not overwritten.
+func writeQrcodeFile(dir string, format QrcodeFormat, row passwordRow) error {
+	code, err := qr.Encode(getTotpURL(row.Machine, row.User, row.Password), qr.L)
+	if err != nil {
+		return fmt.Errorf("qr.Encode() failed: %s", err)
+	}
+
+	var content []byte
+	if format == QrcodeFormatSvg {
+		content = encodeQrcodeSvg(code)
+	} else {
+		content = code.PNG()
+	}
+
+	err = os.MkdirAll(dir, 0700)
+	if err != nil {
+		return fmt.Errorf("os.MkdirAll() failed: %s", err)
+	}
+
+	name := strings.ReplaceAll(fmt.Sprintf("%s-%s-%d", row.Machine, row.User, row.ID), string(os.PathSeparator), "_")
+	path := filepath.Join(dir, name+"."+string(format))
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return fmt.Errorf("os.OpenFile() failed: %s", err)
+	}
+
+	defer file.Close()
+	_, err = file.Write(content)
+	if err != nil {
+		return fmt.Errorf("file.Write() failed: %s", err)
+	}
+
+	return nil
+}
+
+// writeQrcodeFiles writes the TOTP shared secrets which match the filters of a search as QR code
+// image files to `dir`.
+func writeQrcodeFiles(db *sql.DB, opts searchOptions, dir string, format QrcodeFormat) error {
+	rows, err := selectPasswords(db, opts)
+	if err != nil {
+		return fmt.Errorf("selectPasswords() failed: %s", err)
+	}
+
+	for _, row := range rows {
+		if row.PasswordType != PasswordTypeTotp {
+			continue
+		}
+
+		err = writeQrcodeFile(dir, format, row)
+		if err != nil {
+			return fmt.Errorf("writeQrcodeFile() failed: %s", err)
+		}
+	}
+
+	return nil
+}
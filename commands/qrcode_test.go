@@ -0,0 +1,314 @@
+// Copyright 2026 Miklos Vajna
+//
+// SPDX-License-Identifier: MIT
+
+package commands
+
+import (
+	"bytes"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"rsc.io/qr"
+)
+
+// writeQrImageForTesting encodes `text` as a QR code PNG and returns its path.
+func writeQrImageForTesting(t *testing.T, text string) string {
+	code, err := qr.Encode(text, qr.L)
+	if err != nil {
+		t.Fatalf("qr.Encode() failed: %s", err)
+	}
+	path := filepath.Join(t.TempDir(), "qrcode.png")
+	err = os.WriteFile(path, code.PNG(), 0600)
+	if err != nil {
+		t.Fatalf("os.WriteFile() failed: %s", err)
+	}
+	return path
+}
+
+func TestDecodeQrImage(t *testing.T) {
+	expected := "otpauth://totp/Myserver:myuser?secret=JBSWY3DPEHPK3PXP&issuer=Myserver"
+	path := writeQrImageForTesting(t, expected)
+
+	actual, err := decodeQrImage(path)
+
+	if err != nil {
+		t.Fatalf("decodeQrImage() err = %q, want nil", err)
+	}
+	if actual != expected {
+		t.Fatalf("actual = %q, want %q", actual, expected)
+	}
+}
+
+func TestDecodeQrImageJpeg(t *testing.T) {
+	expected := "otpauth://totp/Myserver:myuser?secret=JBSWY3DPEHPK3PXP&issuer=Myserver"
+	pngPath := writeQrImageForTesting(t, expected)
+	pngBytes, err := os.ReadFile(pngPath)
+	if err != nil {
+		t.Fatalf("os.ReadFile() failed: %s", err)
+	}
+	img, err := png.Decode(bytes.NewReader(pngBytes))
+	if err != nil {
+		t.Fatalf("png.Decode() failed: %s", err)
+	}
+	jpegBuf := new(bytes.Buffer)
+	err = jpeg.Encode(jpegBuf, img, nil)
+	if err != nil {
+		t.Fatalf("jpeg.Encode() failed: %s", err)
+	}
+	jpegPath := filepath.Join(t.TempDir(), "qrcode.jpg")
+	err = os.WriteFile(jpegPath, jpegBuf.Bytes(), 0600)
+	if err != nil {
+		t.Fatalf("os.WriteFile() failed: %s", err)
+	}
+
+	actual, err := decodeQrImage(jpegPath)
+
+	if err != nil {
+		t.Fatalf("decodeQrImage() err = %q, want nil", err)
+	}
+	if actual != expected {
+		t.Fatalf("actual = %q, want %q", actual, expected)
+	}
+}
+
+func TestParseTotpURLBad(t *testing.T) {
+	for _, s := range []string{
+		"JBSWY3DPEHPK3PXP",
+		"otpauth://hotp/Myserver:myuser?secret=JBSWY3DPEHPK3PXP&counter=1",
+		"otpauth://totp/Myserver:myuser?issuer=Myserver",
+		"otpauth://totp/Myserver:myuser?secret=not-base32",
+	} {
+		_, err := parseTotpURL(s)
+		if err == nil {
+			t.Fatalf("parseTotpURL(%q) err = nil, want !nil", s)
+		}
+	}
+}
+
+func TestInsertQrImage(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	url := "otpauth://totp/Myserver:myuser?secret=JBSWY3DPEHPK3PXP&issuer=Myserver"
+	path := writeQrImageForTesting(t, url)
+	os.Args = []string{"", "create", "-t", "totp", "--qr-image", path}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	// No interactive questions: machine and user come from the issuer and the label.
+	expectedBuf := "Created 1 password\n"
+	if outBuf.String() != expectedBuf {
+		t.Fatalf("Main() output is %q, want %q", outBuf.String(), expectedBuf)
+	}
+	opts := searchOptions{}
+	opts.noid = true
+	results, err := readPasswords(ctx.Database, opts)
+	if err != nil {
+		t.Fatalf("readPasswords() err = %q, want nil", err)
+	}
+	expected := "machine: Myserver, service: http, user: myuser, password type: TOTP shared secret, password: " + url
+	if !ContainsString(results, expected) {
+		t.Fatalf("results = %q, want to contain %q", results, expected)
+	}
+}
+
+func TestInsertQrImageExplicitMachine(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	url := "otpauth://totp/Myserver:myuser?secret=JBSWY3DPEHPK3PXP&issuer=Myserver"
+	path := writeQrImageForTesting(t, url)
+	os.Args = []string{"", "create", "-t", "totp", "-m", "mymachine", "-u", "otheruser", "--qr-image", path}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	opts := searchOptions{}
+	opts.noid = true
+	results, err := readPasswords(ctx.Database, opts)
+	if err != nil {
+		t.Fatalf("readPasswords() err = %q, want nil", err)
+	}
+	expected := "machine: mymachine, service: http, user: otheruser, password type: TOTP shared secret, password: " + url
+	if !ContainsString(results, expected) {
+		t.Fatalf("results = %q, want to contain %q", results, expected)
+	}
+}
+
+// Insert fails because --qr-image is only valid for TOTP shared secrets.
+func TestInsertQrImagePlain(t *testing.T) {
+	CreateContextForTesting(t)
+	path := writeQrImageForTesting(t, "otpauth://totp/Myserver:myuser?secret=JBSWY3DPEHPK3PXP")
+	os.Args = []string{"", "create", "--qr-image", path}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 1
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q", actualRet, expectedRet)
+	}
+}
+
+// Insert fails because the QR code doesn't contain an otpauth:// URL.
+func TestInsertQrImageNotOtpauth(t *testing.T) {
+	CreateContextForTesting(t)
+	path := writeQrImageForTesting(t, "https://www.example.com/")
+	os.Args = []string{"", "create", "-t", "totp", "--qr-image", path}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 1
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q", actualRet, expectedRet)
+	}
+}
+
+func TestGetTotpURL(t *testing.T) {
+	actual := getTotpURL("mymachine", "myuser", "JBSW Y3DP EHPK 3PXP")
+
+	expected := "otpauth://totp/mymachine:myuser?issuer=mymachine&secret=JBSWY3DPEHPK3PXP"
+	if actual != expected {
+		t.Fatalf("actual = %q, want %q", actual, expected)
+	}
+	key, err := parseTotpURL(actual)
+	if err != nil {
+		t.Fatalf("parseTotpURL() err = %q, want nil", err)
+	}
+	if key.Issuer() != "mymachine" || key.AccountName() != "myuser" {
+		t.Fatalf("issuer = %q, account name = %q", key.Issuer(), key.AccountName())
+	}
+}
+
+func TestQrcodeOutSelect(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	url := "otpauth://totp/Myserver:myuser?secret=JBSWY3DPEHPK3PXP&issuer=Myserver"
+	_, err := ctx.Database.Exec(`insert into passwords (machine, service, user, password, type) values('mymachine', 'myservice', 'myuser', ?, 'totp');
+	                             insert into passwords (machine, service, user, password, type) values('mymachine', 'myservice', 'otheruser', 'JBSWY3DPEHPK3PXP', 'totp');
+	                             insert into passwords (machine, service, user, password, type) values('mymachine', 'myservice', 'myuser', 'mypassword', 'plain');`, url)
+	if err != nil {
+		t.Fatalf("db.Exec() = %q, want nil", err)
+	}
+	dir := filepath.Join(t.TempDir(), "qrcodes")
+	os.Args = []string{"", "search", "--noid", "-m", "mymachine", "--qrcode-out", dir}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("os.ReadDir() failed: %s", err)
+	}
+	// One file per TOTP shared secret, nothing for the plain password.
+	if len(entries) != 2 {
+		t.Fatalf("len(entries) = %v, want 2", len(entries))
+	}
+	actual, err := decodeQrImage(filepath.Join(dir, "mymachine-myuser-1.png"))
+	if err != nil {
+		t.Fatalf("decodeQrImage() err = %q, want nil", err)
+	}
+	if actual != url {
+		t.Fatalf("actual = %q, want %q", actual, url)
+	}
+	actual, err = decodeQrImage(filepath.Join(dir, "mymachine-otheruser-2.png"))
+	if err != nil {
+		t.Fatalf("decodeQrImage() err = %q, want nil", err)
+	}
+	expected := "otpauth://totp/mymachine:otheruser?issuer=mymachine&secret=JBSWY3DPEHPK3PXP"
+	if actual != expected {
+		t.Fatalf("actual = %q, want %q", actual, expected)
+	}
+}
+
+func TestQrcodeOutSelectSvg(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	_, err := ctx.Database.Exec(`insert into passwords (machine, service, user, password, type) values('mymachine', 'myservice', 'my/user', 'JBSWY3DPEHPK3PXP', 'totp');`)
+	if err != nil {
+		t.Fatalf("db.Exec() = %q, want nil", err)
+	}
+	dir := t.TempDir()
+	os.Args = []string{"", "search", "--totp", "-m", "mymachine", "--qrcode-out", dir, "--qrcode-format", "svg"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	// The path separator in the user name is escaped.
+	svg, err := os.ReadFile(filepath.Join(dir, "mymachine-my_user-1.svg"))
+	if err != nil {
+		t.Fatalf("os.ReadFile() failed: %s", err)
+	}
+	if !bytes.HasPrefix(svg, []byte("<?xml")) || !bytes.Contains(svg, []byte("<svg ")) || !bytes.Contains(svg, []byte("h1v1h-1z")) {
+		t.Fatalf("svg = %q, want an SVG document", svg)
+	}
+}
+
+// Search fails because the QR code image file already exists.
+func TestQrcodeOutSelectExisting(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	_, err := ctx.Database.Exec(`insert into passwords (machine, service, user, password, type) values('mymachine', 'myservice', 'myuser', 'JBSWY3DPEHPK3PXP', 'totp');`)
+	if err != nil {
+		t.Fatalf("db.Exec() = %q, want nil", err)
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "mymachine-myuser-1.png")
+	err = os.WriteFile(path, []byte("old"), 0600)
+	if err != nil {
+		t.Fatalf("os.WriteFile() failed: %s", err)
+	}
+	os.Args = []string{"", "search", "-m", "mymachine", "--qrcode-out", dir}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 1
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q", actualRet, expectedRet)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("os.ReadFile() failed: %s", err)
+	}
+	if string(content) != "old" {
+		t.Fatalf("content = %q, want %q", content, "old")
+	}
+}
+
+// Search fails because bmp is not a supported QR code image file format.
+func TestQrcodeOutSelectBadFormat(t *testing.T) {
+	CreateContextForTesting(t)
+	os.Args = []string{"", "search", "-m", "mymachine", "--qrcode-out", t.TempDir(), "--qrcode-format", "bmp"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 1
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q", actualRet, expectedRet)
+	}
+}
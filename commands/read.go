@@ -54,84 +54,163 @@ type searchOptions struct {
 	qrcode        bool
 	noid          bool
 	verbose       bool
-	args          []string
+	// Only match archived passwords.
+	archived bool
+	// Warn if fewer unused recovery codes are left.
+	minRecoveryCodes int
+	args             []string
 }
 
-func readPasswords(db *sql.DB, opts searchOptions) ([]string, error) {
-	var results []string
-	if opts.totp {
-		opts.wantedType = "totp"
+// matchesSearch decides if a password matches the filters of a search.
+func matchesSearch(opts searchOptions, row passwordRow) bool {
+	if !opts.verbose && row.Archived {
+		return false
+	}
+
+	if opts.archived && !row.Archived {
+		return false
+	}
+
+	if len(opts.wantedMachine) > 0 && row.Machine != opts.wantedMachine {
+		return false
+	}
+
+	if len(opts.wantedService) > 0 && row.Service != opts.wantedService {
+		return false
 	}
-	rows, err := db.Query("select id, machine, service, user, password, type, archived, created, modified from passwords")
+
+	if len(opts.wantedUser) > 0 && row.User != opts.wantedUser {
+		return false
+	}
+
+	if len(opts.wantedType) > 0 && row.PasswordType != opts.wantedType {
+		return false
+	}
+
+	if len(opts.args) > 0 {
+		// Allow simply matching a sub-string: e.g. search for a service type or a part
+		// of a machine without explicitly telling if the query is a service or a
+		// machine.
+		s := fmt.Sprintf("%d %s %s %s %s", row.ID, row.Machine, row.Service, row.User, row.PasswordType)
+		if !strings.Contains(s, opts.args[0]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// passwordQuerier runs queries on the database, either directly or in a transaction.
+type passwordQuerier interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+// selectPasswords returns the passwords which match the filters of a search, with all their
+// columns.
+func selectPasswords(db passwordQuerier, opts searchOptions) ([]passwordRow, error) {
+	var results []passwordRow
+	rows, err := db.Query("select id, machine, service, user, password, type, archived, created, modified, notes, expires, rotate_every from passwords order by id")
 	if err != nil {
 		return nil, fmt.Errorf("db.Query(select) failed: %s", err)
 	}
 
 	defer rows.Close()
 	for rows.Next() {
-		var id int
-		var machine string
-		var service string
-		var user string
-		var password string
-		var passwordType PasswordType
-		var archived bool
-		var created string
-		var modified string
-		err = rows.Scan(&id, &machine, &service, &user, &password, &passwordType, &archived, &created, &modified)
+		var row passwordRow
+		err = rows.Scan(&row.ID, &row.Machine, &row.Service, &row.User, &row.Password, &row.PasswordType, &row.Archived, &row.Created, &row.Modified, &row.Notes, &row.Expires, &row.RotateEvery)
 		if err != nil {
 			return nil, fmt.Errorf("rows.Scan() failed: %s", err)
 		}
 
-		if !opts.verbose && archived {
+		if !matchesSearch(opts, row) {
 			continue
 		}
 
-		if len(opts.wantedMachine) > 0 && machine != opts.wantedMachine {
-			continue
-		}
-
-		if len(opts.wantedService) > 0 && service != opts.wantedService {
-			continue
-		}
+		results = append(results, row)
+	}
 
-		if len(opts.wantedUser) > 0 && user != opts.wantedUser {
-			continue
-		}
+	return results, nil
+}
 
-		if len(opts.wantedType) > 0 && passwordType != opts.wantedType {
-			continue
-		}
+func readPasswords(db *sql.DB, opts searchOptions) ([]string, error) {
+	var results []string
+	if opts.totp {
+		opts.wantedType = "totp"
+	}
+	rows, err := selectPasswords(db, opts)
+	if err != nil {
+		return nil, fmt.Errorf("selectPasswords() failed: %s", err)
+	}
 
-		if len(opts.args) > 0 {
-			// Allow simply matching a sub-string: e.g. search for a service type or a part
-			// of a machine without explicitly telling if the query is a service or a
-			// machine.
-			s := fmt.Sprintf("%d %s %s %s %s", id, machine, service, user, passwordType)
-			if !strings.Contains(s, opts.args[0]) {
-				continue
-			}
-		}
+	for _, row := range rows {
+		id := row.ID
+		machine := row.Machine
+		service := row.Service
+		user := row.User
+		password := row.Password
+		passwordType := row.PasswordType
+		archived := row.Archived
+		created := row.Created
+		modified := row.Modified
+		expires := row.Expires
 
 		if passwordType == "totp" {
 			if opts.totp {
 				// This is a TOTP password and the current value is required: invoke
 				// the totp library to generate it.
 				passwordType = "TOTP code"
-				sharedSecret, err := parsePassword(password)
+				sharedSecret, totpOpts, err := getTotpOpts(password)
 				if err != nil {
-					return nil, fmt.Errorf("parsePassword() failed: %s", err)
+					return nil, fmt.Errorf("getTotpOpts() failed: %s", err)
 				}
 
-				password, err = totp.GenerateCode(sharedSecret, Now())
+				password, err = totp.GenerateCodeCustom(sharedSecret, Now(), totpOpts)
 				if err != nil {
-					return nil, fmt.Errorf("totp.GenerateCode() failed: %s", err)
+					return nil, fmt.Errorf("totp.GenerateCodeCustom() failed: %s", err)
 				}
 			} else {
 				passwordType = "TOTP shared secret"
 			}
 		}
 
+		var warning string
+		var usedCodes []string
+		if passwordType == PasswordTypeRecoveryCodes {
+			passwordType = "recovery codes"
+			codes, err := decodeRecoveryCodes(password)
+			if err != nil {
+				// Report the broken password, but don't fail the whole search.
+				warning = fmt.Sprintf(", warning: invalid recovery codes: %s", err)
+			} else {
+				unused := getUnusedRecoveryCodes(codes)
+				password = strings.Join(unused, " ")
+				if len(unused) < opts.minRecoveryCodes {
+					warning = fmt.Sprintf(", warning: only %d unused recovery codes left", len(unused))
+				}
+			}
+			for _, code := range codes {
+				if !code.Used {
+					continue
+				}
+				used := code.Code
+				if t, err := time.Parse(time.RFC3339, code.UsedAt); err == nil {
+					used += " at " + t.Format("2006-01-02 15:04")
+				}
+				usedCodes = append(usedCodes, used)
+			}
+		}
+
+		var expiresTime time.Time
+		if len(expires) > 0 {
+			expiresTime, err = time.Parse(time.RFC3339, expires)
+			if err != nil {
+				return nil, fmt.Errorf("time.Parse() failed: %s", err)
+			}
+			if !expiresTime.After(Now()) {
+				warning += fmt.Sprintf(", warning: expired on %s", expiresTime.Format("2006-01-02"))
+			}
+		}
+
 		var result string
 		if opts.quiet {
 			result = password
@@ -147,8 +226,12 @@ func readPasswords(db *sql.DB, opts searchOptions) ([]string, error) {
 			} else {
 				result += fmt.Sprintf(" %s", password)
 			}
+			result += warning
 			if opts.verbose {
 				result += fmt.Sprintf(", archived: %v", archived)
+				if len(usedCodes) > 0 {
+					result += fmt.Sprintf(", used: %s", strings.Join(usedCodes, ", "))
+				}
 				if len(created) > 0 {
 					t, err := time.Parse(time.RFC3339, created)
 					if err != nil {
@@ -163,6 +246,15 @@ func readPasswords(db *sql.DB, opts searchOptions) ([]string, error) {
 					}
 					result += fmt.Sprintf(", modified: %v", t.Format("2006-01-02 15:04"))
 				}
+				if len(expires) > 0 {
+					result += fmt.Sprintf(", expires: %v", expiresTime.Format("2006-01-02 15:04"))
+				}
+				if len(row.Notes) > 0 {
+					result += fmt.Sprintf(", notes: %s", row.Notes)
+				}
+				if passwordType == PasswordTypePlain {
+					result += fmt.Sprintf(", strength: %d/%d", getPasswordStrength(password, machine, user), maxStrength)
+				}
 			}
 		}
 		results = append(results, result)
@@ -180,8 +272,13 @@ func newReadCommand(ctx *Context) *cobra.Command {
 	var totpFlag bool
 	var quietFlag bool
 	var qrcodeFlag bool
+	var qrcodeOutFlag string
+	var qrcodeFormatFlag QrcodeFormat = "png"
 	var noidFlag bool
 	var verboseFlag bool
+	var minRecoveryCodesFlag int
+	var clipFlag bool
+	var clipTimeoutFlag int
 	var cmd = &cobra.Command{
 		Use:   "search",
 		Short: "searches passwords",
@@ -207,29 +304,57 @@ func newReadCommand(ctx *Context) *cobra.Command {
 			opts.qrcode = qrcodeFlag
 			opts.noid = noidFlag
 			opts.verbose = verboseFlag
+			opts.minRecoveryCodes = minRecoveryCodesFlag
 			opts.args = args
+			if clipFlag {
+				opts.quiet = true
+			}
 			results, err := readPasswords(ctx.Database, opts)
 			if err != nil {
 				return fmt.Errorf("readPasswords() failed: %s", err)
 			}
 
+			if len(qrcodeOutFlag) > 0 {
+				err = writeQrcodeFiles(ctx.Database, opts, qrcodeOutFlag, qrcodeFormatFlag)
+				if err != nil {
+					return fmt.Errorf("writeQrcodeFiles() failed: %s", err)
+				}
+			}
+
+			ctx.NoWriteBack = true
+			if clipFlag {
+				if len(results) != 1 {
+					return fmt.Errorf("the query matches %d passwords, need exactly one for --clip", len(results))
+				}
+
+				err = clipPassword(cmd, ctx, results[0], clipTimeoutFlag)
+				if err != nil {
+					return fmt.Errorf("clipPassword() failed: %s", err)
+				}
+				return nil
+			}
+
 			for _, result := range results {
 				fmt.Fprintf(cmd.OutOrStdout(), "%s\n", result)
 			}
 
-			ctx.NoWriteBack = true
 			return nil
 		},
 	}
 	cmd.Flags().StringVarP(&machineFlag, "machine", "m", "", `machine (default: "")`)
 	cmd.Flags().StringVarP(&serviceFlag, "service", "s", "", `service (default: "")`)
 	cmd.Flags().StringVarP(&userFlag, "user", "u", "", `user (default: "")`)
-	cmd.Flags().VarP(&typeFlag, "type", "t", `password type ("plain" or "totp", default: "")`)
+	cmd.Flags().VarP(&typeFlag, "type", "t", `password type ("plain", "totp" or "recovery-codes", default: "")`)
 	cmd.Flags().BoolVarP(&totpFlag, "totp", "T", false, `show the current TOTP code, not the TOTP shared secret (default: false, implies "--type totp")`)
 	cmd.Flags().BoolVarP(&quietFlag, "quiet", "q", false, "quite mode: only print the password itself (default: false)")
 	cmd.Flags().BoolVarP(&qrcodeFlag, "qrcode", "Q", false, "qrcode mode: print the TOTP shared secret as a QR code (default: false)")
+	cmd.Flags().StringVarP(&qrcodeOutFlag, "qrcode-out", "", "", `write the TOTP shared secrets as QR code image files to this directory (default: "")`)
+	cmd.Flags().VarP(&qrcodeFormatFlag, "qrcode-format", "", `QR code image file format ("png" or "svg")`)
 	cmd.Flags().BoolVarP(&noidFlag, "noid", "I", false, "noid mode: omit password ID from the output (default: false)")
 	cmd.Flags().BoolVarP(&verboseFlag, "verbose", "v", false, "verbose mode: show if the password is archived (default: false)")
+	cmd.Flags().IntVarP(&minRecoveryCodesFlag, "min-recovery-codes", "", 3, "warn if fewer unused recovery codes are left")
+	cmd.Flags().BoolVarP(&clipFlag, "clip", "c", false, "clip mode: copy the password or the TOTP code to the clipboard instead of printing it (default: false)")
+	cmd.Flags().IntVarP(&clipTimeoutFlag, "clip-timeout", "", 45, "clear the clipboard after this many seconds, 0 keeps the password there")
 
 	return cmd
 }
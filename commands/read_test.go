@@ -8,6 +8,7 @@ import (
 	"bytes"
 	"io"
 	"os"
+	"strings"
 	"testing"
 
 	"rsc.io/qr"
@@ -93,6 +94,30 @@ func TestSelectTotpCode(t *testing.T) {
 	}
 }
 
+// The TOTP code respects the digits of the otpauth:// URL.
+func TestSelectTotpCodeDigits(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	_, err := ctx.Database.Exec(`insert into passwords (machine, service, user, password, type) values('mymachine', 'myservice', 'myuser', 'otpauth://totp/Myserver:myuser?secret=JBSWY3DPEHPK3PXP&digits=8', 'totp');`)
+	if err != nil {
+		t.Fatalf("db.Exec() = %q, want nil", err)
+	}
+	os.Args = []string{"", "search", "-q", "--totp", "-m", "mymachine"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q", actualRet, expectedRet)
+	}
+	actualLength := len(strings.TrimSuffix(outBuf.String(), "\n"))
+	expectedLength := 8
+	if actualLength != expectedLength {
+		t.Fatalf("actualLength = %v, want %v", actualLength, expectedLength)
+	}
+}
+
 func GenerateQrCodeForTesting(text string, l qr.Level, w io.Writer) {
 	w.Write([]byte("qrcode-output"))
 }
@@ -152,6 +177,38 @@ func TestSelectMachineFilter(t *testing.T) {
 	}
 }
 
+// Command names are only commands in the first position, later they are search terms.
+func TestSelectCommandNames(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	_, err := ctx.Database.Exec(`insert into passwords (machine, service, user, password, type) values('backup', 'myservice1', 'myuser1', 'mypassword1', 'plain');
+	                             insert into passwords (machine, service, user, password, type) values('edit', 'myservice2', 'myuser2', 'mypassword2', 'plain');`)
+	if err != nil {
+		t.Fatalf("db.Exec() = %q, want nil", err)
+	}
+	for _, test := range []struct {
+		args           []string
+		expectedOutput string
+	}{
+		{[]string{"--noid", "-m", "backup"}, "machine: backup, service: myservice1, user: myuser1, password type: plain, password: mypassword1\n"},
+		{[]string{"--noid", "edit"}, "machine: edit, service: myservice2, user: myuser2, password type: plain, password: mypassword2\n"},
+	} {
+		os.Args = append([]string{""}, test.args...)
+		inBuf := new(bytes.Buffer)
+		outBuf := new(bytes.Buffer)
+
+		actualRet := Main(inBuf, outBuf)
+
+		expectedRet := 0
+		if actualRet != expectedRet {
+			t.Fatalf("Main() = %q, want %q, args are %q", actualRet, expectedRet, test.args)
+		}
+		actualOutput := outBuf.String()
+		if actualOutput != test.expectedOutput {
+			t.Fatalf("actualOutput = %q, want %q", actualOutput, test.expectedOutput)
+		}
+	}
+}
+
 func TestSelectServiceFilter(t *testing.T) {
 	ctx := CreateContextForTesting(t)
 	_, err := ctx.Database.Exec(`insert into passwords (machine, service, user, password, type) values('mymachine1', 'myservice1', 'myuser1', 'mypassword1', 'plain');
@@ -387,7 +444,7 @@ func TestSelectArchivedVerbose(t *testing.T) {
 	if actualRet != expectedRet {
 		t.Fatalf("Main() = %q, want %q", actualRet, expectedRet)
 	}
-	expectedOutput := "machine: mymachine, service: myservice, user: myuser, password type: plain, password: mypassword, archived: true\n"
+	expectedOutput := "machine: mymachine, service: myservice, user: myuser, password type: plain, password: mypassword, archived: true, strength: 0/4\n"
 	actualOutput := outBuf.String()
 	if actualOutput != expectedOutput {
 		t.Fatalf("actualOutput = %q, want %q", actualOutput, expectedOutput)
@@ -0,0 +1,194 @@
+// Copyright 2026 Miklos Vajna
+//
+// SPDX-License-Identifier: MIT
+
+package commands
+
+import (
+	"bufio"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// recoveryCode is one single-use 2FA backup code of a recovery-codes password.
+type recoveryCode struct {
+	Code   string
+	Used   bool
+	UsedAt string
+}
+
+// parseRecoveryCodes splits user input on whitespace and commas, to a list of unused codes.
+func parseRecoveryCodes(s string) []recoveryCode {
+	var codes []recoveryCode
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n'
+	})
+	for _, field := range fields {
+		codes = append(codes, recoveryCode{Code: field})
+	}
+	return codes
+}
+
+// encodeRecoveryCodes turns user input into the stored form of a recovery-codes password.
+func encodeRecoveryCodes(s string) (string, error) {
+	codes := parseRecoveryCodes(s)
+	if len(codes) == 0 {
+		return "", fmt.Errorf("no recovery codes specified")
+	}
+
+	j, err := json.Marshal(codes)
+	if err != nil {
+		return "", fmt.Errorf("json.Marshal() failed: %s", err)
+	}
+
+	return string(j), nil
+}
+
+// decodeRecoveryCodes parses the stored form of a recovery-codes password.
+func decodeRecoveryCodes(password string) ([]recoveryCode, error) {
+	var codes []recoveryCode
+	err := json.Unmarshal([]byte(password), &codes)
+	if err != nil {
+		return nil, fmt.Errorf("json.Unmarshal() failed: %s", err)
+	}
+
+	return codes, nil
+}
+
+// getUnusedRecoveryCodes returns the codes which are not yet consumed.
+func getUnusedRecoveryCodes(codes []recoveryCode) []string {
+	var unused []string
+	for _, code := range codes {
+		if !code.Used {
+			unused = append(unused, code.Code)
+		}
+	}
+	return unused
+}
+
+// formatUnusedRecoveryCodes returns the unused codes of a recovery-codes password, separated by
+// spaces. A password which can't be decoded is reported in its stored form, instead of failing.
+func formatUnusedRecoveryCodes(password string) string {
+	codes, err := decodeRecoveryCodes(password)
+	if err != nil {
+		return fmt.Sprintf("invalid recovery codes '%s': %s", password, err)
+	}
+
+	return strings.Join(getUnusedRecoveryCodes(codes), " ")
+}
+
+// useRecoveryCode marks the next unused recovery code of a password as consumed and returns it,
+// together with the number of remaining unused codes.
+func useRecoveryCode(transaction *sql.Tx, id string) (string, int, error) {
+	var password string
+	var passwordType PasswordType
+	row := transaction.QueryRow("select password, type from passwords where id=?", id)
+	err := row.Scan(&password, &passwordType)
+	if err == sql.ErrNoRows {
+		return "", 0, fmt.Errorf("no password with id '%s'", id)
+	}
+	if err != nil {
+		return "", 0, fmt.Errorf("row.Scan() failed: %s", err)
+	}
+
+	if passwordType != PasswordTypeRecoveryCodes {
+		return "", 0, fmt.Errorf("password with id '%s' is not a list of recovery codes", id)
+	}
+
+	codes, err := decodeRecoveryCodes(password)
+	if err != nil {
+		return "", 0, fmt.Errorf("decodeRecoveryCodes() failed: %s", err)
+	}
+
+	now := Now().Format(time.RFC3339)
+	var code string
+	for i := range codes {
+		if !codes[i].Used {
+			codes[i].Used = true
+			codes[i].UsedAt = now
+			code = codes[i].Code
+			break
+		}
+	}
+	if len(code) == 0 {
+		return "", 0, fmt.Errorf("all recovery codes are used already")
+	}
+
+	j, err := json.Marshal(codes)
+	if err != nil {
+		return "", 0, fmt.Errorf("json.Marshal() failed: %s", err)
+	}
+
+	query, err := transaction.Prepare("update passwords set password=?, modified=? where id=?")
+	if err != nil {
+		return "", 0, fmt.Errorf("db.Prepare() failed: %s", err)
+	}
+
+	_, err = query.Exec(string(j), now, id)
+	if err != nil {
+		return "", 0, fmt.Errorf("db.Exec() failed: %s", err)
+	}
+
+	return code, len(getUnusedRecoveryCodes(codes)), nil
+}
+
+func newRecoveryUseCommand(ctx *Context) *cobra.Command {
+	var dryRun bool
+	var id string
+	var cmd = &cobra.Command{
+		Use:   "use",
+		Short: "hands out the next unused recovery code and marks it as used",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			transaction, err := ctx.Database.Begin()
+			if err != nil {
+				return fmt.Errorf("db.Begin() failed: %s", err)
+			}
+
+			defer transaction.Rollback()
+
+			if len(id) == 0 {
+				fmt.Fprintf(cmd.OutOrStdout(), "Id: ")
+				reader := bufio.NewReader(cmd.InOrStdin())
+				line, err := reader.ReadString('\n')
+				if err != nil {
+					return fmt.Errorf("ReadString() failed: %s", err)
+				}
+				id = strings.TrimSuffix(line, "\n")
+			}
+
+			code, remaining, err := useRecoveryCode(transaction, id)
+			if err != nil {
+				return fmt.Errorf("useRecoveryCode() failed: %s", err)
+			}
+
+			if dryRun {
+				fmt.Fprintf(cmd.OutOrStdout(), "Would use recovery code: %s\n", code)
+				ctx.NoWriteBack = true
+			} else {
+				transaction.Commit()
+				fmt.Fprintf(cmd.OutOrStdout(), "Recovery code: %s\n", code)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Unused recovery codes left: %d\n", remaining)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVarP(&dryRun, "dry-run", "n", false, `do everything except actually perform the database action (default: false)`)
+	cmd.Flags().StringVarP(&id, "id", "i", "", `unique identifier (default: ask)`)
+
+	return cmd
+}
+
+func newRecoveryCommand(ctx *Context) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "recovery",
+		Short: "works with single-use 2FA recovery codes",
+	}
+	cmd.AddCommand(newRecoveryUseCommand(ctx))
+
+	return cmd
+}
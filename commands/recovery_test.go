@@ -0,0 +1,343 @@
+// Copyright 2026 Miklos Vajna
+//
+// SPDX-License-Identifier: MIT
+
+package commands
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// createRecoveryCodesForTesting inserts a recovery-codes password with 3 unused codes.
+func createRecoveryCodesForTesting(t *testing.T) {
+	os.Args = []string{"", "create", "-m", "mymachine", "-u", "myuser", "-t", "recovery-codes", "-p", "code1 code2,code3"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+	actualRet := Main(inBuf, outBuf)
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q", actualRet, expectedRet)
+	}
+}
+
+func TestInsertRecoveryCodes(t *testing.T) {
+	CreateContextForTesting(t)
+	createRecoveryCodesForTesting(t)
+	os.Args = []string{"", "search", "--noid", "-m", "mymachine"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q", actualRet, expectedRet)
+	}
+	expectedOutput := "machine: mymachine, service: http, user: myuser, password type: recovery codes, password: code1 code2 code3\n"
+	actualOutput := outBuf.String()
+	if actualOutput != expectedOutput {
+		t.Fatalf("actualOutput = %q, want %q", actualOutput, expectedOutput)
+	}
+}
+
+// Insert fails because recovery codes can't be generated.
+func TestInsertRecoveryCodesEmpty(t *testing.T) {
+	CreateContextForTesting(t)
+	os.Args = []string{"", "create", "-m", "mymachine", "-u", "myuser", "-t", "recovery-codes"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 1
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q", actualRet, expectedRet)
+	}
+}
+
+func TestRecoveryUse(t *testing.T) {
+	CreateContextForTesting(t)
+	createRecoveryCodesForTesting(t)
+	os.Args = []string{"", "recovery", "use", "-i", "1"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q", actualRet, expectedRet)
+	}
+	expectedOutput := "Recovery code: code1\nUnused recovery codes left: 2\n"
+	actualOutput := outBuf.String()
+	if actualOutput != expectedOutput {
+		t.Fatalf("actualOutput = %q, want %q", actualOutput, expectedOutput)
+	}
+
+	// The used code is no longer shown, and the search warns about the few remaining codes.
+	os.Args = []string{"", "search", "--noid", "-v", "-m", "mymachine"}
+	outBuf = new(bytes.Buffer)
+
+	actualRet = Main(inBuf, outBuf)
+
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q", actualRet, expectedRet)
+	}
+	expectedOutput = "machine: mymachine, service: http, user: myuser, password type: recovery codes, password: code2 code3, warning: only 2 unused recovery codes left, archived: false, used: code1 at 2020-05-10 00:00, created: 2020-05-10 00:00, modified: 2020-05-10 00:00\n"
+	actualOutput = outBuf.String()
+	if actualOutput != expectedOutput {
+		t.Fatalf("actualOutput = %q, want %q", actualOutput, expectedOutput)
+	}
+
+	// Quiet mode only prints the unused codes.
+	os.Args = []string{"", "search", "-q", "--min-recovery-codes", "0", "-m", "mymachine"}
+	outBuf = new(bytes.Buffer)
+
+	actualRet = Main(inBuf, outBuf)
+
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q", actualRet, expectedRet)
+	}
+	expectedOutput = "code2 code3\n"
+	actualOutput = outBuf.String()
+	if actualOutput != expectedOutput {
+		t.Fatalf("actualOutput = %q, want %q", actualOutput, expectedOutput)
+	}
+}
+
+func TestRecoveryUseDryRun(t *testing.T) {
+	CreateContextForTesting(t)
+	createRecoveryCodesForTesting(t)
+	os.Args = []string{"", "recovery", "use", "-n"}
+	inBuf := new(bytes.Buffer)
+	inBuf.Write([]byte("1\n"))
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q", actualRet, expectedRet)
+	}
+	expectedOutput := "Id: Would use recovery code: code1\nUnused recovery codes left: 2\n"
+	actualOutput := outBuf.String()
+	if actualOutput != expectedOutput {
+		t.Fatalf("actualOutput = %q, want %q", actualOutput, expectedOutput)
+	}
+
+	// This was a dry run, so the first code is handed out again.
+	os.Args = []string{"", "recovery", "use", "-i", "1"}
+	outBuf = new(bytes.Buffer)
+
+	actualRet = Main(inBuf, outBuf)
+
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q", actualRet, expectedRet)
+	}
+	expectedOutput = "Recovery code: code1\nUnused recovery codes left: 2\n"
+	actualOutput = outBuf.String()
+	if actualOutput != expectedOutput {
+		t.Fatalf("actualOutput = %q, want %q", actualOutput, expectedOutput)
+	}
+}
+
+// Use fails once all codes are used.
+func TestRecoveryUseExhausted(t *testing.T) {
+	CreateContextForTesting(t)
+	createRecoveryCodesForTesting(t)
+	for i := 0; i < 3; i++ {
+		os.Args = []string{"", "recovery", "use", "-i", "1"}
+		inBuf := new(bytes.Buffer)
+		outBuf := new(bytes.Buffer)
+		actualRet := Main(inBuf, outBuf)
+		expectedRet := 0
+		if actualRet != expectedRet {
+			t.Fatalf("Main() = %q, want %q", actualRet, expectedRet)
+		}
+	}
+	os.Args = []string{"", "recovery", "use", "-i", "1"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 1
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q", actualRet, expectedRet)
+	}
+}
+
+// Use fails because the password is not a list of recovery codes or doesn't exist.
+func TestRecoveryUseBadID(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	_, err := ctx.Database.Exec(`insert into passwords (machine, service, user, password, type) values('mymachine', 'myservice', 'myuser', 'mypassword', 'plain');`)
+	if err != nil {
+		t.Fatalf("db.Exec() = %q, want nil", err)
+	}
+	for _, id := range []string{"1", "2"} {
+		os.Args = []string{"", "recovery", "use", "-i", id}
+		inBuf := new(bytes.Buffer)
+		outBuf := new(bytes.Buffer)
+
+		actualRet := Main(inBuf, outBuf)
+
+		expectedRet := 1
+		if actualRet != expectedRet {
+			t.Fatalf("Main() = %q, want %q", actualRet, expectedRet)
+		}
+	}
+}
+
+func TestUpdateRecoveryCodes(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	createRecoveryCodesForTesting(t)
+	_, err := ctx.Database.Exec(`insert into passwords (machine, service, user, password, type) values('othermachine', 'myservice', 'myuser', 'mypassword', 'plain');`)
+	if err != nil {
+		t.Fatalf("db.Exec() = %q, want nil", err)
+	}
+	// Replace the codes of an existing recovery-codes password, then turn a plain password into
+	// recovery codes.
+	for _, args := range [][]string{
+		{"", "update", "-i", "1", "-p", "code4 code5 code6"},
+		{"", "update", "-i", "2", "-t", "recovery-codes", "-p", "code7 code8 code9"},
+	} {
+		os.Args = args
+		inBuf := new(bytes.Buffer)
+		outBuf := new(bytes.Buffer)
+		actualRet := Main(inBuf, outBuf)
+		expectedRet := 0
+		if actualRet != expectedRet {
+			t.Fatalf("Main() = %q, want %q", actualRet, expectedRet)
+		}
+	}
+	results, err := readPasswords(ctx.Database, searchOptions{noid: true})
+	if err != nil {
+		t.Fatalf("readPasswords() err = %q, want nil", err)
+	}
+	for _, expected := range []string{
+		"machine: mymachine, service: http, user: myuser, password type: recovery codes, password: code4 code5 code6",
+		"machine: othermachine, service: myservice, user: myuser, password type: recovery codes, password: code7 code8 code9",
+	} {
+		if !ContainsString(results, expected) {
+			t.Fatalf("results = %q, want to contain %q", results, expected)
+		}
+	}
+}
+
+// Update fails because recovery codes can't be generated.
+func TestUpdateRecoveryCodesGenerate(t *testing.T) {
+	CreateContextForTesting(t)
+	createRecoveryCodesForTesting(t)
+	os.Args = []string{"", "update", "-i", "1", "-p", "-"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 1
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q", actualRet, expectedRet)
+	}
+}
+
+// Update fails because changing the type to or from recovery codes needs a new password.
+func TestUpdateRecoveryCodesType(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	createRecoveryCodesForTesting(t)
+	_, err := ctx.Database.Exec(`insert into passwords (machine, service, user, password, type) values('othermachine', 'http', 'myuser', 'mypassword', 'plain');`)
+	if err != nil {
+		t.Fatalf("db.Exec() = %q, want nil", err)
+	}
+	for _, args := range [][]string{
+		{"", "update", "-i", "1", "-t", "plain"},
+		{"", "update", "-i", "2", "-t", "recovery-codes"},
+	} {
+		os.Args = args
+		inBuf := new(bytes.Buffer)
+		outBuf := new(bytes.Buffer)
+
+		actualRet := Main(inBuf, outBuf)
+
+		expectedRet := 1
+		if actualRet != expectedRet {
+			t.Fatalf("Main() = %q, want %q, args are %q", actualRet, expectedRet, args)
+		}
+	}
+	results, err := readPasswords(ctx.Database, searchOptions{noid: true})
+	if err != nil {
+		t.Fatalf("readPasswords() err = %q, want nil", err)
+	}
+	expected := []string{
+		"machine: mymachine, service: http, user: myuser, password type: recovery codes, password: code1 code2 code3",
+		"machine: othermachine, service: http, user: myuser, password type: plain, password: mypassword",
+	}
+	if !slices.Equal(results, expected) {
+		t.Fatalf("results = %q, want %q", results, expected)
+	}
+}
+
+// Broken recovery codes are reported for their password, the other passwords are still searched,
+// exported and backed up.
+func TestRecoveryCodesInvalid(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	_, err := ctx.Database.Exec(`insert into passwords (machine, service, user, password, type) values('mymachine', 'http', 'myuser', 'notjson', 'recovery-codes');
+	                             insert into passwords (machine, service, user, password, type) values('othermachine', 'http', 'myuser', '[{"Code":"code1","Used":true},{"Code":"code2"}]', 'recovery-codes');`)
+	if err != nil {
+		t.Fatalf("db.Exec() = %q, want nil", err)
+	}
+	path := filepath.Join(t.TempDir(), "backup.html")
+	tests := []struct {
+		args     []string
+		expected []string
+	}{
+		{
+			[]string{"search", "--noid", "-v", "-s", "http"},
+			[]string{
+				"machine: mymachine, service: http, user: myuser, password type: recovery codes, password: notjson, warning: invalid recovery codes: json.Unmarshal() failed: invalid character 'o' in literal null (expecting 'u'), archived: false",
+				// The time of using the code is unknown.
+				"machine: othermachine, service: http, user: myuser, password type: recovery codes, password: code2, warning: only 1 unused recovery codes left, archived: false, used: code1",
+			},
+		},
+		{
+			[]string{"export", "-f", "csv"},
+			[]string{
+				"mymachine,http,myuser,,,Recovery codes: invalid recovery codes 'notjson': json.Unmarshal() failed: invalid character 'o' in literal null (expecting 'u'),false",
+				"othermachine,http,myuser,,,Recovery codes: code2,false",
+			},
+		},
+		{
+			[]string{"backup", "paper", "--out", path},
+			[]string{"Wrote 2 passwords"},
+		},
+	}
+	for _, test := range tests {
+		os.Args = append([]string{""}, test.args...)
+		inBuf := new(bytes.Buffer)
+		outBuf := new(bytes.Buffer)
+
+		actualRet := Main(inBuf, outBuf)
+
+		expectedRet := 0
+		if actualRet != expectedRet {
+			t.Fatalf("Main() = %q, want %q, args are %q", actualRet, expectedRet, test.args)
+		}
+		for _, expected := range test.expected {
+			if !strings.Contains(outBuf.String(), expected) {
+				t.Fatalf("Main() output is %q, want it to contain %q", outBuf.String(), expected)
+			}
+		}
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("os.ReadFile() err = %q, want nil", err)
+	}
+	expected := "invalid recovery codes &#39;notjson&#39;"
+	if !strings.Contains(string(content), expected) {
+		t.Fatalf("html is %q, want it to contain %q", content, expected)
+	}
+}
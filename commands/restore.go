@@ -0,0 +1,192 @@
+// Copyright 2026 Miklos Vajna
+//
+// SPDX-License-Identifier: MIT
+
+package commands
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// paperChunk is the parsed text of a numbered QR code of a paper backup.
+type paperChunk struct {
+	number   int
+	total    int
+	checksum string
+	data     string
+}
+
+// parsePaperChunk parses the text of a numbered QR code, as written by encodePaperChunks().
+func parsePaperChunk(text string) (paperChunk, error) {
+	var chunk paperChunk
+	fields := strings.Split(text, ":")
+	if len(fields) != 4 || fields[0] != paperChunkPrefix {
+		return chunk, fmt.Errorf("'%s' is not a numbered QR code of a paper backup", text)
+	}
+
+	number, total, found := strings.Cut(fields[1], "/")
+	if !found {
+		return chunk, fmt.Errorf("'%s' has no total number of QR codes", fields[1])
+	}
+
+	var err error
+	chunk.number, err = strconv.Atoi(number)
+	if err != nil {
+		return chunk, fmt.Errorf("strconv.Atoi() failed: %s", err)
+	}
+
+	chunk.total, err = strconv.Atoi(total)
+	if err != nil {
+		return chunk, fmt.Errorf("strconv.Atoi() failed: %s", err)
+	}
+
+	if chunk.number < 1 || chunk.number > chunk.total {
+		return chunk, fmt.Errorf("QR code %d is out of range, the total is %d", chunk.number, chunk.total)
+	}
+
+	chunk.checksum = fields[2]
+	chunk.data = fields[3]
+	return chunk, nil
+}
+
+// decodePaperChunks reassembles the payload of a paper backup from the texts of its numbered QR
+// codes, in any order. Empty lines and repeated QR codes are ignored.
+func decodePaperChunks(texts []string) ([]byte, int, error) {
+	chunks := map[int]paperChunk{}
+	var first paperChunk
+	for _, text := range texts {
+		text = strings.TrimSpace(text)
+		if len(text) == 0 {
+			continue
+		}
+
+		chunk, err := parsePaperChunk(text)
+		if err != nil {
+			return nil, 0, fmt.Errorf("parsePaperChunk() failed: %s", err)
+		}
+
+		if len(chunks) == 0 {
+			first = chunk
+		} else if chunk.total != first.total || chunk.checksum != first.checksum {
+			return nil, 0, fmt.Errorf("QR code %d/%d is from a different backup than QR code %d/%d", chunk.number, chunk.total, first.number, first.total)
+		}
+		chunks[chunk.number] = chunk
+	}
+
+	if len(chunks) == 0 {
+		return nil, 0, fmt.Errorf("no QR codes")
+	}
+
+	var missing []string
+	var sb strings.Builder
+	for number := 1; number <= first.total; number++ {
+		chunk, ok := chunks[number]
+		if !ok {
+			missing = append(missing, strconv.Itoa(number))
+			continue
+		}
+
+		sb.WriteString(chunk.data)
+	}
+	if len(missing) > 0 {
+		return nil, 0, fmt.Errorf("missing QR codes: %s of %d", strings.Join(missing, ", "), first.total)
+	}
+
+	payload, err := base64.StdEncoding.DecodeString(sb.String())
+	if err != nil {
+		return nil, 0, fmt.Errorf("base64.DecodeString() failed: %s", err)
+	}
+
+	if getPaperChecksum(payload) != first.checksum {
+		return nil, 0, fmt.Errorf("checksum mismatch, the content of a QR code is damaged")
+	}
+
+	return payload, first.total, nil
+}
+
+// readPaperChunks reads the texts of numbered QR codes, one per line, from `paths` or from the
+// standard input.
+func readPaperChunks(cmd *cobra.Command, paths []string) ([]string, error) {
+	var readers []io.Reader
+	for _, path := range paths {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("os.Open() failed: %s", err)
+		}
+		defer file.Close()
+
+		readers = append(readers, file)
+	}
+	if len(readers) == 0 {
+		readers = append(readers, cmd.InOrStdin())
+	}
+
+	var texts []string
+	for _, reader := range readers {
+		scanner := bufio.NewScanner(reader)
+		scanner.Buffer(nil, 1024*1024)
+		for scanner.Scan() {
+			texts = append(texts, scanner.Text())
+		}
+		err := scanner.Err()
+		if err != nil {
+			return nil, fmt.Errorf("scanner.Err() failed: %s", err)
+		}
+	}
+
+	return texts, nil
+}
+
+func newRestorePaperCommand(ctx *Context) *cobra.Command {
+	var outPath string
+	var cmd = &cobra.Command{
+		Use:   "paper [FILE...]",
+		Short: "reassembles the numbered QR codes of a paper backup from their decoded text",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx.NoWriteBack = true
+			texts, err := readPaperChunks(cmd, args)
+			if err != nil {
+				return fmt.Errorf("readPaperChunks() failed: %s", err)
+			}
+
+			payload, total, err := decodePaperChunks(texts)
+			if err != nil {
+				return fmt.Errorf("decodePaperChunks() failed: %s", err)
+			}
+
+			if len(outPath) == 0 {
+				cmd.OutOrStdout().Write(payload)
+				return nil
+			}
+
+			err = os.WriteFile(outPath, payload, 0600)
+			if err != nil {
+				return fmt.Errorf("os.WriteFile() failed: %s", err)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Restored %v QR codes to '%s'\n", total, outPath)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&outPath, "out", "o", "", `write the restored file here, instead of the standard output (default: "")`)
+
+	return cmd
+}
+
+func newRestoreCommand(ctx *Context) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "restore",
+		Short: "restores backups of the password database",
+	}
+	cmd.AddCommand(newRestorePaperCommand(ctx))
+
+	return cmd
+}
@@ -0,0 +1,120 @@
+// Copyright 2026 Miklos Vajna
+//
+// SPDX-License-Identifier: MIT
+
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// The numbered QR codes are restored from the standard input, in any order, even if some of them
+// are scanned twice.
+func TestRestorePaper(t *testing.T) {
+	// Large enough to need multiple numbered QR codes.
+	payload := []byte(strings.Repeat("-----BEGIN PGP MESSAGE-----\n", 80))
+	chunks := encodePaperChunks(payload)
+	if len(chunks) != 4 {
+		t.Fatalf("len(chunks) = %v, want 4", len(chunks))
+	}
+	slices.Reverse(chunks)
+	os.Args = []string{"", "restore", "paper"}
+	inBuf := bytes.NewBufferString(strings.Join(append(chunks, "", chunks[0]), "\n"))
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q", actualRet, expectedRet)
+	}
+	if !bytes.Equal(outBuf.Bytes(), payload) {
+		t.Fatalf("Main() output is %q, want %q", outBuf.String(), payload)
+	}
+}
+
+// The numbered QR codes are restored from multiple files to a file.
+func TestRestorePaperOut(t *testing.T) {
+	// Large enough to need multiple numbered QR codes.
+	payload := []byte(strings.Repeat("-----BEGIN PGP MESSAGE-----\n", 80))
+	chunks := encodePaperChunks(payload)
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.txt")
+	err := os.WriteFile(first, []byte(strings.Join(chunks[:2], "\n")+"\n"), 0600)
+	if err != nil {
+		t.Fatalf("os.WriteFile() failed: %s", err)
+	}
+	second := filepath.Join(dir, "second.txt")
+	err = os.WriteFile(second, []byte(strings.Join(chunks[2:], "\n")+"\n"), 0600)
+	if err != nil {
+		t.Fatalf("os.WriteFile() failed: %s", err)
+	}
+	path := filepath.Join(dir, "passwords.db")
+	os.Args = []string{"", "restore", "paper", "--out", path, first, second}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q", actualRet, expectedRet)
+	}
+	expectedBuf := fmt.Sprintf("Restored 4 QR codes to '%s'\n", path)
+	if outBuf.String() != expectedBuf {
+		t.Fatalf("Main() output is %q, want %q", outBuf.String(), expectedBuf)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("os.ReadFile() err = %q, want nil", err)
+	}
+	if !bytes.Equal(content, payload) {
+		t.Fatalf("content = %q, want %q", content, payload)
+	}
+}
+
+// Restore fails because QR codes are missing, damaged, from a different backup or not from a paper
+// backup at all.
+func TestRestorePaperInvalid(t *testing.T) {
+	// Large enough to need multiple numbered QR codes.
+	payload := []byte(strings.Repeat("-----BEGIN PGP MESSAGE-----\n", 80))
+	chunks := encodePaperChunks(payload)
+	other := encodePaperChunks([]byte("other"))
+	data := strings.LastIndex(chunks[1], ":") + 1
+	damaged := chunks[1][:data] + "AAAA" + chunks[1][data+4:]
+	tests := []string{
+		"",
+		"https://www.example.com/",
+		"cpm-paper:1:abcd:LS0t",
+		"cpm-paper:x/2:abcd:LS0t",
+		"cpm-paper:1/x:abcd:LS0t",
+		"cpm-paper:3/2:abcd:LS0t",
+		"cpm-paper:1/1:abcd:!!!!",
+		strings.Join([]string{chunks[0], other[0]}, "\n"),
+		strings.Join([]string{chunks[0], chunks[1], chunks[3]}, "\n"),
+		strings.Join([]string{chunks[0], damaged, chunks[2], chunks[3]}, "\n"),
+	}
+	for _, test := range tests {
+		os.Args = []string{"", "restore", "paper"}
+		inBuf := bytes.NewBufferString(test)
+		outBuf := new(bytes.Buffer)
+
+		actualRet := Main(inBuf, outBuf)
+
+		expectedRet := 1
+		if actualRet != expectedRet {
+			t.Fatalf("Main() = %q, want %q, input is %q", actualRet, expectedRet, test)
+		}
+	}
+	os.Args = []string{"", "restore", "paper", filepath.Join(t.TempDir(), "missing.txt")}
+	actualRet := Main(new(bytes.Buffer), new(bytes.Buffer))
+	if actualRet != 1 {
+		t.Fatalf("Main() = %q, want %q", actualRet, 1)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"os"
 	"os/user"
 	"path/filepath"
+	"slices"
 
 	// register sqlite driver
 	_ "github.com/mattn/go-sqlite3"
@@ -29,8 +30,10 @@ func NewRootCommand(ctx *Context) *cobra.Command {
 	var cmd = &cobra.Command{
 		Use:   "cpm",
 		Short: "turtle-cpm is a console password manager",
+		// Errors are not about the usage, e.g. audit fails when it finds problems.
+		SilenceUsage: true,
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-			if len(os.Args) >= 2 && os.Args[1] == "version" {
+			if !needsDatabase() {
 				return nil
 			}
 
@@ -42,6 +45,10 @@ func NewRootCommand(ctx *Context) *cobra.Command {
 			return nil
 		},
 		PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
+			if !needsDatabase() {
+				return nil
+			}
+
 			err := CloseDatabase(ctx)
 			if err != nil {
 				return fmt.Errorf("CloseDatabase() failed: %s", err)
@@ -59,6 +66,16 @@ func NewRootCommand(ctx *Context) *cobra.Command {
 	cmd.AddCommand(newVersionCommand(ctx))
 	cmd.AddCommand(newGcCommand(ctx))
 	cmd.AddCommand(newExportCommand(ctx))
+	cmd.AddCommand(newTotpCommand(ctx))
+	cmd.AddCommand(newRecoveryCommand(ctx))
+	cmd.AddCommand(newGenerateCommand(ctx))
+	cmd.AddCommand(newAuditCommand(ctx))
+	cmd.AddCommand(newDueCommand(ctx))
+	cmd.AddCommand(newRotateCommand(ctx))
+	cmd.AddCommand(newUICommand(ctx))
+	cmd.AddCommand(newEditCommand(ctx))
+	cmd.AddCommand(newBackupCommand(ctx))
+	cmd.AddCommand(newRestoreCommand(ctx))
 
 	return cmd
 }
@@ -79,9 +96,25 @@ func getCommands() []string {
 		"version",
 		"gc",
 		"export",
+		"totp",
+		"recovery",
+		"generate",
+		"audit",
+		"due",
+		"rotate",
+		"ui",
+		"edit",
+		"backup",
+		"restore",
 	}
 }
 
+// needsDatabase decides if the subcommand works with the database, or the database can be left
+// alone.
+func needsDatabase() bool {
+	return len(os.Args) < 2 || (os.Args[1] != "version" && os.Args[1] != "generate" && os.Args[1] != "restore")
+}
+
 // Context is state that is preserved during PreRun / Run / PostRun.
 type Context struct {
 	TempFile         *os.File
@@ -221,8 +254,62 @@ func initDatabase(ctx *Context) error {
 		ctx.DatabaseMigrated = true
 	}
 
+	if version < 4 {
+		query, err := ctx.Database.Prepare(`alter table passwords add column
+				expires text not null default ''`)
+		if err != nil {
+			return fmt.Errorf("db.Prepare() failed: %s", err)
+		}
+		_, err = query.Exec()
+		if err != nil {
+			return fmt.Errorf("db.Exec() failed: %s", err)
+		}
+		query, err = ctx.Database.Prepare(`alter table passwords add column
+				rotate_every integer not null default 0`)
+		if err != nil {
+			return fmt.Errorf("db.Prepare() failed: %s", err)
+		}
+		_, err = query.Exec()
+		if err != nil {
+			return fmt.Errorf("db.Exec() failed: %s", err)
+		}
+		ctx.DatabaseMigrated = true
+	}
+
+	if version < 5 {
+		// Old values of rotated passwords.
+		query, err := ctx.Database.Prepare(`create table history (
+				id integer primary key autoincrement,
+				password_id integer not null,
+				password text not null,
+				type text not null,
+				replaced text not null
+		);`)
+		if err != nil {
+			return fmt.Errorf("db.Prepare() failed: %s", err)
+		}
+		_, err = query.Exec()
+		if err != nil {
+			return fmt.Errorf("db.Exec() failed: %s", err)
+		}
+		ctx.DatabaseMigrated = true
+	}
+
+	if version < 6 {
+		query, err := ctx.Database.Prepare(`alter table passwords add column
+				notes text not null default ''`)
+		if err != nil {
+			return fmt.Errorf("db.Prepare() failed: %s", err)
+		}
+		_, err = query.Exec()
+		if err != nil {
+			return fmt.Errorf("db.Exec() failed: %s", err)
+		}
+		ctx.DatabaseMigrated = true
+	}
+
 	if ctx.DatabaseMigrated {
-		query, err := ctx.Database.Prepare("pragma user_version = 3")
+		query, err := ctx.Database.Prepare("pragma user_version = 6")
 		if err != nil {
 			return fmt.Errorf("db.Prepare() failed: %s", err)
 		}
@@ -248,6 +335,11 @@ func closeDatabase(ctx *Context) error {
 		}
 	}
 
+	if !pathExists(ctx.TempFile.Name()) {
+		// Already written back and cleaned, e.g. while waiting to clear the clipboard.
+		return nil
+	}
+
 	Remove(ctx.PermanentPath)
 	command := Command("gpg", "--encrypt", "--sign", "-a", "--default-recipient-self", "-o", ctx.PermanentPath, ctx.TempFile.Name())
 	err := command.Run()
@@ -287,6 +379,8 @@ const (
 	PasswordTypePlain PasswordType = "plain"
 	// PasswordTypeTotp is a TOTP shared secret.
 	PasswordTypeTotp PasswordType = "totp"
+	// PasswordTypeRecoveryCodes is a list of single-use 2FA backup codes.
+	PasswordTypeRecoveryCodes PasswordType = "recovery-codes"
 )
 
 func (t *PasswordType) String() string {
@@ -296,11 +390,11 @@ func (t *PasswordType) String() string {
 // Set sets the value of `t` from `v`.
 func (t *PasswordType) Set(v string) error {
 	switch v {
-	case "plain", "totp":
+	case "plain", "totp", "recovery-codes":
 		*t = PasswordType(v)
 		return nil
 	default:
-		return errors.New(`must be one of "plain", or "totp"`)
+		return errors.New(`must be one of "plain", "totp", or "recovery-codes"`)
 	}
 }
 
@@ -314,16 +408,10 @@ func Main(input io.Reader, output io.Writer) int {
 	var ctx Context
 	defer cleanDatabase(&ctx)
 
-	var commandFound bool
-	commands := getCommands()
-	for _, a := range commands {
-		for _, b := range os.Args[1:] {
-			if a == b {
-				commandFound = true
-				break
-			}
-		}
-	}
+	// The root command has no flags of its own, so a command can only be the first argument. Later
+	// arguments may be values of search flags which happen to be command names: "cpm -t totp foo"
+	// or "cpm -m backup" are still searches.
+	commandFound := len(os.Args) > 1 && slices.Contains(getCommands(), os.Args[1])
 	var cmd = NewRootCommand(&ctx)
 	var args []string
 	if commandFound {
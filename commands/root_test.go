@@ -5,6 +5,7 @@
 package commands
 
 import (
+	"bytes"
 	"database/sql"
 	"fmt"
 	"io"
@@ -36,10 +37,17 @@ func CreateContextForTesting(t *testing.T) Context {
 	GeneratePassword = GeneratePasswordForTesting
 	t.Cleanup(func() { GeneratePassword = oldGeneratePassword })
 
+	oldGenerateTotpKey := GenerateTotpKey
+	GenerateTotpKey = GenerateTotpKeyForTesting
+	t.Cleanup(func() { GenerateTotpKey = oldGenerateTotpKey })
+
 	oldNow := Now
 	Now = NowForTesting
 	t.Cleanup(func() { Now = oldNow })
 
+	// Don't depend on the config of the user running the tests.
+	t.Setenv(xdgConfigHome, t.TempDir())
+
 	ctx := Context{Database: db}
 	err = initDatabase(&ctx)
 	if err != nil {
@@ -68,9 +76,7 @@ func CommandForTesting(t *testing.T) func(name string, arg ...string) *exec.Cmd
 			decryptedPath := arg[3]
 			encryptedPath := arg[4]
 			var encryptedQaPath string
-			if strings.HasSuffix(encryptedPath, ".cpmdb") {
-				encryptedQaPath = "fixtures/cpmdb.xml"
-			} else if strings.HasSuffix(encryptedPath, "passwords.db") {
+			if strings.HasSuffix(encryptedPath, "passwords.db") {
 				encryptedQaPath = "fixtures/passwords.db"
 			} else {
 				t.Fatalf("unexpected encryted path: %s", encryptedPath)
@@ -94,14 +100,16 @@ func CommandForTesting(t *testing.T) func(name string, arg ...string) *exec.Cmd
 				t.Fatalf("CopyPath() failed: %s", err)
 			}
 			return exec.Command("true")
-		} else if len(arg) == 2 && name == "gunzip" && arg[0] == "--force" {
-			compressedPath := arg[1]
-			uncompressedPath := strings.ReplaceAll(compressedPath, ".gz", "")
-			err := CopyPath(compressedPath, uncompressedPath)
-			if err != nil {
-				t.Fatalf("CopyPath() failed: %s", err)
+		} else if len(arg) == 3 && name == "gpg" && arg[0] == "--decrypt" && arg[1] == "--quiet" {
+			// The files of the tests are not encrypted.
+			encryptedPath := arg[2]
+			if strings.HasSuffix(encryptedPath, "/.cpmdb") {
+				encryptedPath = "fixtures/cpmdb.xml.gz"
 			}
-			return exec.Command("true")
+			return exec.Command("cat", encryptedPath)
+		} else if len(arg) >= 5 && name == "gpg" && arg[0] == "--encrypt" && arg[1] == "--quiet" && arg[3] == "--output" {
+			// Write the standard input as-is, so the tests can read it back.
+			return exec.Command("tee", arg[4])
 		} else if name == "scp" && len(arg) == 2 && strings.HasPrefix(arg[0], "cpm:") && strings.HasSuffix(arg[0], "passwords.db") && strings.HasSuffix(arg[1], "passwords.db") {
 			err := CopyPath("fixtures/remote.db", "fixtures/passwords.db")
 			if err != nil {
@@ -182,3 +190,33 @@ func TestGetDatabasePath(t *testing.T) {
 		t.Fatalf("getDatabasePath() = %q, want %q", actual, expected)
 	}
 }
+
+// The help of commands shows the types of their enum flags.
+func TestHelpFlagTypes(t *testing.T) {
+	CreateContextForTesting(t)
+	for _, test := range []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"search", "-h"}, "--type PasswordType"},
+		{[]string{"search", "-h"}, "--qrcode-format QrcodeFormat"},
+		{[]string{"export", "-h"}, "--format ExportFormat"},
+		{[]string{"import", "-h"}, "--format ImportFormat"},
+		{[]string{"import", "-h"}, "--on-conflict ConflictStrategy"},
+		{[]string{"backup", "paper", "-h"}, "--chunks PaperChunks"},
+	} {
+		os.Args = append([]string{""}, test.args...)
+		inBuf := new(bytes.Buffer)
+		outBuf := new(bytes.Buffer)
+
+		actualRet := Main(inBuf, outBuf)
+
+		expectedRet := 0
+		if actualRet != expectedRet {
+			t.Fatalf("Main() = %q, want %q, args are %q", actualRet, expectedRet, test.args)
+		}
+		if !strings.Contains(outBuf.String(), test.expected) {
+			t.Fatalf("Main() output is %q, want %q in it", outBuf.String(), test.expected)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2026 Miklos Vajna
+//
+// SPDX-License-Identifier: MIT
+
+package commands
+
+import (
+	"database/sql"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// rotatedPassword is a password selected for rotation, with its new value.
+type rotatedPassword struct {
+	row         passwordRow
+	newPassword string
+}
+
+// selectRotatedPasswords finds the non-archived plain passwords matching the search filters.
+func selectRotatedPasswords(transaction *sql.Tx, opts searchOptions) ([]rotatedPassword, error) {
+	rows, err := selectPasswords(transaction, opts)
+	if err != nil {
+		return nil, fmt.Errorf("selectPasswords() failed: %s", err)
+	}
+
+	var results []rotatedPassword
+	for _, row := range rows {
+		results = append(results, rotatedPassword{row: row})
+	}
+
+	return results, nil
+}
+
+// rotatePassword replaces the value of a password, saving the old value to the history.
+func rotatePassword(transaction *sql.Tx, rotated rotatedPassword) error {
+	now := Now()
+	query, err := transaction.Prepare("insert into history (password_id, password, type, replaced) values(?, ?, ?, ?)")
+	if err != nil {
+		return fmt.Errorf("db.Prepare() failed: %s", err)
+	}
+
+	_, err = query.Exec(rotated.row.ID, rotated.row.Password, rotated.row.PasswordType, now.Format(time.RFC3339))
+	if err != nil {
+		return fmt.Errorf("db.Exec() failed: %s", err)
+	}
+
+	query, err = transaction.Prepare("update passwords set password=?, modified=? where id=?")
+	if err != nil {
+		return fmt.Errorf("db.Prepare() failed: %s", err)
+	}
+
+	_, err = query.Exec(rotated.newPassword, now.Format(time.RFC3339), rotated.row.ID)
+	if err != nil {
+		return fmt.Errorf("db.Exec() failed: %s", err)
+	}
+
+	if rotated.row.RotateEvery > 0 {
+		// Restart the rotation interval.
+		query, err = transaction.Prepare("update passwords set expires=? where id=?")
+		if err != nil {
+			return fmt.Errorf("db.Prepare() failed: %s", err)
+		}
+
+		_, err = query.Exec(now.AddDate(0, 0, rotated.row.RotateEvery).Format(time.RFC3339), rotated.row.ID)
+		if err != nil {
+			return fmt.Errorf("db.Exec() failed: %s", err)
+		}
+	}
+
+	return nil
+}
+
+func newRotateCommand(ctx *Context) *cobra.Command {
+	var machine string
+	var service string
+	var user string
+	var dryRun bool
+	var secure bool
+	var policy policyFlags
+	var cmd = &cobra.Command{
+		Use:   "rotate [QUERY]",
+		Short: "generates new passwords for all matching plain passwords",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(machine) == 0 && len(service) == 0 && len(user) == 0 && len(args) == 0 {
+				return fmt.Errorf("no query or filter specified, refusing to rotate all passwords")
+			}
+
+			transaction, err := ctx.Database.Begin()
+			if err != nil {
+				return fmt.Errorf("db.Begin() failed: %s", err)
+			}
+
+			defer transaction.Rollback()
+
+			opts := searchOptions{}
+			opts.wantedMachine = machine
+			opts.wantedService = service
+			opts.wantedUser = user
+			// TOTP shared secrets and recovery codes come from the site, can't be generated.
+			opts.wantedType = PasswordTypePlain
+			opts.args = args
+			passwords, err := selectRotatedPasswords(transaction, opts)
+			if err != nil {
+				return fmt.Errorf("selectRotatedPasswords() failed: %s", err)
+			}
+
+			for i := range passwords {
+				// Each password follows the policy of its own machine.
+				passwords[i].newPassword, _, err = generateFlagsPassword(cmd, policy, passwords[i].row.Machine, secure)
+				if err != nil {
+					return fmt.Errorf("generateFlagsPassword() failed: %s", err)
+				}
+
+				err = rotatePassword(transaction, passwords[i])
+				if err != nil {
+					return fmt.Errorf("rotatePassword() failed: %s", err)
+				}
+			}
+
+			if dryRun {
+				fmt.Fprintf(cmd.OutOrStdout(), "Would rotate %v passwords\n", len(passwords))
+				ctx.NoWriteBack = true
+			} else {
+				transaction.Commit()
+				fmt.Fprintf(cmd.OutOrStdout(), "Rotated %v passwords\n", len(passwords))
+			}
+
+			if len(passwords) > 0 {
+				writer := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+				fmt.Fprintf(writer, "ID\tMACHINE\tSERVICE\tUSER\tNEW PASSWORD\n")
+				for _, rotated := range passwords {
+					fmt.Fprintf(writer, "%d\t%s\t%s\t%s\t%s\n", rotated.row.ID, rotated.row.Machine, rotated.row.Service, rotated.row.User, rotated.newPassword)
+				}
+				writer.Flush()
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&machine, "machine", "m", "", `machine (default: "")`)
+	cmd.Flags().StringVarP(&service, "service", "s", "", `service (default: "")`)
+	cmd.Flags().StringVarP(&user, "user", "u", "", `user (default: "")`)
+	cmd.Flags().BoolVarP(&dryRun, "dry-run", "n", false, `do everything except actually perform the database action (default: false)`)
+	cmd.Flags().BoolVarP(&secure, "secure", "y", false, `increase number of symbols from 0 to 3 (default: false)`)
+	addPolicyFlags(cmd, &policy)
+
+	return cmd
+}
@@ -0,0 +1,201 @@
+// Copyright 2026 Miklos Vajna
+//
+// SPDX-License-Identifier: MIT
+
+package commands
+
+import (
+	"bytes"
+	"database/sql"
+	"os"
+	"testing"
+)
+
+// getHistoryForTesting returns the old values of the password with `id`.
+func getHistoryForTesting(t *testing.T, db *sql.DB, id int) []string {
+	var history []string
+	rows, err := db.Query("select password from history where password_id=? order by id", id)
+	if err != nil {
+		t.Fatalf("db.Query() failed: %s", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var password string
+		err = rows.Scan(&password)
+		if err != nil {
+			t.Fatalf("rows.Scan() failed: %s", err)
+		}
+		history = append(history, password)
+	}
+	return history
+}
+
+func TestRotate(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	_, err := ctx.Database.Exec(`insert into passwords (machine, service, user, password, type) values('mymachine', 'http', 'myuser', 'oldpassword1', 'plain');
+	                             insert into passwords (machine, service, user, password, type, rotate_every) values('mymachine', 'ssh', 'root', 'oldpassword2', 'plain', 30);
+	                             insert into passwords (machine, service, user, password, type) values('mymachine', 'http', 'myuser', 'JBSWY3DPEHPK3PXP', 'totp');
+	                             insert into passwords (machine, service, user, password, type, archived) values('mymachine', 'http', 'olduser', 'oldpassword3', 'plain', 1);
+	                             insert into passwords (machine, service, user, password, type) values('othermachine', 'http', 'myuser', 'oldpassword4', 'plain');`)
+	if err != nil {
+		t.Fatalf("db.Exec() = %q, want nil", err)
+	}
+	os.Args = []string{"", "rotate", "-m", "mymachine"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	// TOTP shared secrets, archived passwords and other machines are not rotated.
+	expectedBuf := "Rotated 2 passwords\n" +
+		"ID  MACHINE    SERVICE  USER    NEW PASSWORD\n" +
+		"1   mymachine  http     myuser  output-from-pwgen\n" +
+		"2   mymachine  ssh      root    output-from-pwgen\n"
+	if outBuf.String() != expectedBuf {
+		t.Fatalf("Main() output is %q, want %q", outBuf.String(), expectedBuf)
+	}
+	history := getHistoryForTesting(t, ctx.Database, 1)
+	if len(history) != 1 || history[0] != "oldpassword1" {
+		t.Fatalf("history = %q, want [oldpassword1]", history)
+	}
+	expires, _ := getExpiresForTesting(t, ctx.Database, 2)
+	if expires != "2020-06-09T00:00:00+02:00" {
+		t.Fatalf("expires = %q, want 2020-06-09T00:00:00+02:00", expires)
+	}
+	results, err := readPasswords(ctx.Database, searchOptions{noid: true, verbose: true})
+	if err != nil {
+		t.Fatalf("readPasswords() err = %q, want nil", err)
+	}
+	for _, expected := range []string{
+		"machine: mymachine, service: http, user: myuser, password type: TOTP shared secret, password: JBSWY3DPEHPK3PXP, archived: false",
+		"machine: mymachine, service: http, user: olduser, password type: plain, password: oldpassword3, archived: true, strength: 0/4",
+		"machine: othermachine, service: http, user: myuser, password type: plain, password: oldpassword4, archived: false, strength: 0/4",
+	} {
+		if !ContainsString(results, expected) {
+			t.Fatalf("results = %q, want to contain %q", results, expected)
+		}
+	}
+}
+
+func TestRotatePolicy(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	UseConfigForTesting(t, `{"Policies": {"short": {"Length": 8}}, "MachinePolicies": {"othermachine": "short"}}`)
+	actualArgs := UseGeneratePasswordRecorderForTesting(t)
+	_, err := ctx.Database.Exec(`insert into passwords (machine, service, user, password, type) values('othermachine', 'http', 'myuser', 'oldpassword', 'plain');`)
+	if err != nil {
+		t.Fatalf("db.Exec() = %q, want nil", err)
+	}
+	os.Args = []string{"", "rotate", "other"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	expectedArgs := "length: 8, digits: 3, symbols: 0, no upper: false, allow repeat: false"
+	if *actualArgs != expectedArgs {
+		t.Fatalf("actualArgs = %q, want %q", *actualArgs, expectedArgs)
+	}
+}
+
+func TestRotateDryRun(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	_, err := ctx.Database.Exec(`insert into passwords (machine, service, user, password, type) values('mymachine', 'http', 'myuser', 'oldpassword', 'plain');`)
+	if err != nil {
+		t.Fatalf("db.Exec() = %q, want nil", err)
+	}
+	os.Args = []string{"", "rotate", "-n", "-u", "myuser"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	expectedBuf := "Would rotate 1 passwords\n" +
+		"ID  MACHINE    SERVICE  USER    NEW PASSWORD\n" +
+		"1   mymachine  http     myuser  output-from-pwgen\n"
+	if outBuf.String() != expectedBuf {
+		t.Fatalf("Main() output is %q, want %q", outBuf.String(), expectedBuf)
+	}
+	results, err := readPasswords(ctx.Database, searchOptions{quiet: true})
+	if err != nil {
+		t.Fatalf("readPasswords() err = %q, want nil", err)
+	}
+	if !ContainsString(results, "oldpassword") {
+		t.Fatalf("results = %q, want to contain %q", results, "oldpassword")
+	}
+	if len(getHistoryForTesting(t, ctx.Database, 1)) != 0 {
+		t.Fatalf("history is not empty")
+	}
+}
+
+func TestRotateNoMatch(t *testing.T) {
+	CreateContextForTesting(t)
+	os.Args = []string{"", "rotate", "-s", "ssh"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	expectedBuf := "Rotated 0 passwords\n"
+	if outBuf.String() != expectedBuf {
+		t.Fatalf("Main() output is %q, want %q", outBuf.String(), expectedBuf)
+	}
+}
+
+// Rotate fails because there is no filter, which would rotate all passwords.
+func TestRotateNoFilter(t *testing.T) {
+	CreateContextForTesting(t)
+	os.Args = []string{"", "rotate"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 1
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q", actualRet, expectedRet)
+	}
+}
+
+// Rotate fails because the policy of the machine doesn't exist, nothing is rotated.
+func TestRotateBadPolicy(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	UseConfigForTesting(t, `{"MachinePolicies": {"othermachine": "nosuchpolicy"}}`)
+	_, err := ctx.Database.Exec(`insert into passwords (machine, service, user, password, type) values('mymachine', 'http', 'myuser', 'oldpassword1', 'plain');
+	                             insert into passwords (machine, service, user, password, type) values('othermachine', 'http', 'myuser', 'oldpassword2', 'plain');`)
+	if err != nil {
+		t.Fatalf("db.Exec() = %q, want nil", err)
+	}
+	os.Args = []string{"", "rotate", "-u", "myuser"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 1
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q", actualRet, expectedRet)
+	}
+	results, err := readPasswords(ctx.Database, searchOptions{quiet: true})
+	if err != nil {
+		t.Fatalf("readPasswords() err = %q, want nil", err)
+	}
+	if !ContainsString(results, "oldpassword1") {
+		t.Fatalf("results = %q, want to contain %q", results, "oldpassword1")
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright 2026 Miklos Vajna
+//
+// SPDX-License-Identifier: MIT
+
+package commands
+
+import (
+	"fmt"
+
+	"github.com/nbutton23/zxcvbn-go"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// maxStrength is the score of the strongest passwords.
+	maxStrength = 4
+	// defaultMinStrength is the minimal score of user-supplied passwords, without a warning.
+	defaultMinStrength = 3
+)
+
+// getPasswordStrength estimates how hard is to guess a password, from 0 (very weak) to 4 (very
+// strong). Dictionary words, keyboard patterns, dates and the context of the password (e.g. the
+// user name) are all considered.
+func getPasswordStrength(password string, context ...string) int {
+	return zxcvbn.PasswordStrength(password, context).Score
+}
+
+// strengthFlags are the password strength checking switches of create and update.
+type strengthFlags struct {
+	minStrength     int
+	enforceStrength bool
+}
+
+func addStrengthFlags(cmd *cobra.Command, flags *strengthFlags) {
+	cmd.Flags().IntVarP(&flags.minStrength, "min-strength", "", 0, `warn if the strength of the specified password is below this, from 0 to 4 (default: from the config, or 3)`)
+	cmd.Flags().BoolVarP(&flags.enforceStrength, "enforce-strength", "", false, `refuse passwords below the minimal strength, instead of a warning (default: false)`)
+}
+
+// checkPasswordStrength warns about a weak user-supplied password, or fails in case the strength is
+// enforced.
+func checkPasswordStrength(cmd *cobra.Command, flags strengthFlags, password, machine, user string) error {
+	minStrength := flags.minStrength
+	if !cmd.Flags().Changed("min-strength") {
+		c, err := loadConfig()
+		if err != nil {
+			return fmt.Errorf("loadConfig() failed: %s", err)
+		}
+		minStrength = c.MinStrength
+	}
+
+	strength := getPasswordStrength(password, machine, user)
+	if strength >= minStrength {
+		return nil
+	}
+
+	if flags.enforceStrength {
+		return fmt.Errorf("password is too weak: strength is %d/%d, need at least %d", strength, maxStrength, minStrength)
+	}
+
+	// Warn on stderr, so the output of create and update is not changed.
+	fmt.Fprintf(cmd.ErrOrStderr(), "Warning: password is weak: strength is %d/%d, recommended at least %d\n", strength, maxStrength, minStrength)
+	return nil
+}
@@ -0,0 +1,156 @@
+// Copyright 2026 Miklos Vajna
+//
+// SPDX-License-Identifier: MIT
+
+package commands
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// UseStderrForTesting redirects stderr to a file and returns its path.
+func UseStderrForTesting(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "stderr")
+	stderr, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("os.Create() failed: %s", err)
+	}
+	oldStderr := os.Stderr
+	os.Stderr = stderr
+	t.Cleanup(func() {
+		os.Stderr = oldStderr
+		stderr.Close()
+	})
+	return path
+}
+
+func TestGetPasswordStrength(t *testing.T) {
+	if actual := getPasswordStrength("123456"); actual != 0 {
+		t.Fatalf("getPasswordStrength(123456) = %v, want 0", actual)
+	}
+	if actual := getPasswordStrength("7U1FvIzubR95Itg"); actual != 4 {
+		t.Fatalf("getPasswordStrength(7U1FvIzubR95Itg) = %v, want 4", actual)
+	}
+	// The context makes a password weaker.
+	without := getPasswordStrength("mymachinemyuser")
+	with := getPasswordStrength("mymachinemyuser", "mymachine", "myuser")
+	if with >= without {
+		t.Fatalf("with = %v, without = %v, want with < without", with, without)
+	}
+}
+
+func TestInsertWeakPassword(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	stderr := UseStderrForTesting(t)
+	os.Args = []string{"", "create", "-m", "mymachine", "-u", "myuser", "-p", "123456"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	// The warning doesn't change the output.
+	expectedBuf := "Created 1 password\n"
+	if outBuf.String() != expectedBuf {
+		t.Fatalf("Main() output is %q, want %q", outBuf.String(), expectedBuf)
+	}
+	actualStderr, err := os.ReadFile(stderr)
+	if err != nil {
+		t.Fatalf("os.ReadFile() failed: %s", err)
+	}
+	expectedStderr := "Warning: password is weak: strength is 0/4, recommended at least 3\n"
+	if string(actualStderr) != expectedStderr {
+		t.Fatalf("stderr is %q, want %q", actualStderr, expectedStderr)
+	}
+	results, err := readPasswords(ctx.Database, searchOptions{quiet: true})
+	if err != nil {
+		t.Fatalf("readPasswords() err = %q, want nil", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("len(results) = %v, want 1", len(results))
+	}
+}
+
+// Insert fails because the password is too weak.
+func TestInsertEnforceStrength(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	os.Args = []string{"", "create", "-m", "mymachine", "-u", "myuser", "-p", "123456", "--enforce-strength"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 1
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q", actualRet, expectedRet)
+	}
+	results, err := readPasswords(ctx.Database, searchOptions{quiet: true})
+	if err != nil {
+		t.Fatalf("readPasswords() err = %q, want nil", err)
+	}
+	if len(results) != 0 {
+		t.Fatalf("len(results) = %v, want 0", len(results))
+	}
+}
+
+func TestInsertEnforceStrengthStrong(t *testing.T) {
+	CreateContextForTesting(t)
+	os.Args = []string{"", "create", "-m", "mymachine", "-u", "myuser", "-p", "7U1FvIzubR95Itg", "--enforce-strength"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+}
+
+func TestInsertMinStrengthConfig(t *testing.T) {
+	CreateContextForTesting(t)
+	UseConfigForTesting(t, `{"MinStrength": 0}`)
+	os.Args = []string{"", "create", "-m", "mymachine", "-u", "myuser", "-p", "123456", "--enforce-strength"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+}
+
+// Update fails because the password is too weak, the switch overrides the config.
+func TestUpdateEnforceStrength(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	UseConfigForTesting(t, `{"MinStrength": 0}`)
+	_, err := createPassword(&ctx, "mymachine", "myservice", "myuser", "oldpassword", PasswordTypePlain, false, "", 0, "")
+	if err != nil {
+		t.Fatalf("createPassword() = %q, want nil", err)
+	}
+	os.Args = []string{"", "update", "-i", "1", "-p", "myuser", "--min-strength", "2", "--enforce-strength"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 1
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q", actualRet, expectedRet)
+	}
+	results, err := readPasswords(ctx.Database, searchOptions{quiet: true})
+	if err != nil {
+		t.Fatalf("readPasswords() err = %q, want nil", err)
+	}
+	if !ContainsString(results, "oldpassword") {
+		t.Fatalf("results = %q, want to contain %q", results, "oldpassword")
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright 2026 Miklos Vajna
+//
+// SPDX-License-Identifier: MIT
+
+package commands
+
+import (
+	"bufio"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+	"github.com/spf13/cobra"
+)
+
+// getTotpOpts returns the TOTP shared secret and the code generation parameters from a stored
+// password: the parameters of an otpauth:// URL, or the defaults for a bare secret.
+func getTotpOpts(password string) (string, totp.ValidateOpts, error) {
+	opts := totp.ValidateOpts{
+		Period:    30,
+		Digits:    otp.DigitsSix,
+		Algorithm: otp.AlgorithmSHA1,
+	}
+	sharedSecret, err := parsePassword(password)
+	if err != nil {
+		return "", opts, fmt.Errorf("parsePassword() failed: %s", err)
+	}
+
+	if strings.HasPrefix(password, "otpauth://") {
+		key, err := otp.NewKeyFromURL(password)
+		if err != nil {
+			return "", opts, fmt.Errorf("otp.NewKeyFromURL() failed: %s", err)
+		}
+
+		opts.Period = uint(key.Period())
+		opts.Digits = key.Digits()
+		opts.Algorithm = key.Algorithm()
+	}
+
+	return sharedSecret, opts, nil
+}
+
+// verifyTotpCode checks `code` against a TOTP shared secret, trying the current time step first and
+// then the neighbouring ones, up to `skew` steps. It returns the matching step.
+func verifyTotpCode(sharedSecret string, opts totp.ValidateOpts, code string, skew int) (int, bool, error) {
+	now := Now()
+	for i := 0; i <= 2*skew; i++ {
+		// 0, -1, 1, -2, 2, ...
+		step := (i + 1) / 2
+		if i%2 == 1 {
+			step = -step
+		}
+		t := now.Add(time.Duration(step*int(opts.Period)) * time.Second)
+		expected, err := totp.GenerateCodeCustom(sharedSecret, t, opts)
+		if err != nil {
+			return 0, false, fmt.Errorf("totp.GenerateCodeCustom() failed: %s", err)
+		}
+
+		if expected == code {
+			return step, true, nil
+		}
+	}
+
+	return 0, false, nil
+}
+
+func newTotpVerifyCommand(ctx *Context) *cobra.Command {
+	var id string
+	var skew int
+	var cmd = &cobra.Command{
+		Use:   "verify CODE",
+		Short: "verifies a TOTP code against a stored TOTP shared secret",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx.NoWriteBack = true
+			if len(id) == 0 {
+				fmt.Fprintf(cmd.OutOrStdout(), "Id: ")
+				reader := bufio.NewReader(cmd.InOrStdin())
+				line, err := reader.ReadString('\n')
+				if err != nil {
+					return fmt.Errorf("ReadString() failed: %s", err)
+				}
+				id = strings.TrimSuffix(line, "\n")
+			}
+
+			var password string
+			var passwordType PasswordType
+			row := ctx.Database.QueryRow("select password, type from passwords where id=?", id)
+			err := row.Scan(&password, &passwordType)
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("no password with id '%s'", id)
+			}
+			if err != nil {
+				return fmt.Errorf("row.Scan() failed: %s", err)
+			}
+
+			if passwordType != PasswordTypeTotp {
+				return fmt.Errorf("password with id '%s' is not a TOTP shared secret", id)
+			}
+
+			sharedSecret, opts, err := getTotpOpts(password)
+			if err != nil {
+				return fmt.Errorf("getTotpOpts() failed: %s", err)
+			}
+
+			step, found, err := verifyTotpCode(sharedSecret, opts, args[0], skew)
+			if err != nil {
+				return fmt.Errorf("verifyTotpCode() failed: %s", err)
+			}
+
+			if !found {
+				return fmt.Errorf("code is not valid within %d time steps of the current time", skew)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Code is valid at time step %+d (%+ds from the current time)\n", step, step*int(opts.Period))
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&id, "id", "i", "", `unique identifier (default: ask)`)
+	cmd.Flags().IntVarP(&skew, "skew", "", 1, `number of time steps to accept before and after the current time`)
+
+	return cmd
+}
+
+func newTotpCommand(ctx *Context) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "totp",
+		Short: "works with TOTP shared secrets",
+	}
+	cmd.AddCommand(newTotpVerifyCommand(ctx))
+
+	return cmd
+}
@@ -0,0 +1,154 @@
+// Copyright 2026 Miklos Vajna
+//
+// SPDX-License-Identifier: MIT
+
+package commands
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+)
+
+func TestGetTotpOpts(t *testing.T) {
+	sharedSecret, opts, err := getTotpOpts("otpauth://totp/Myserver:myuser?secret=JBSWY3DPEHPK3PXP&digits=8&algorithm=SHA256&period=60")
+
+	if err != nil {
+		t.Fatalf("getTotpOpts() err = %q, want nil", err)
+	}
+	if sharedSecret != "JBSWY3DPEHPK3PXP" {
+		t.Fatalf("sharedSecret = %q, want %q", sharedSecret, "JBSWY3DPEHPK3PXP")
+	}
+	if opts.Period != 60 || opts.Digits != otp.DigitsEight || opts.Algorithm != otp.AlgorithmSHA256 {
+		t.Fatalf("opts = %v, want period 60, 8 digits, SHA256", opts)
+	}
+}
+
+// createTotpCodeForTesting generates the TOTP code for `sharedSecret`, `step` time steps away from
+// the current time.
+func createTotpCodeForTesting(t *testing.T, sharedSecret string, step int) string {
+	opts := totp.ValidateOpts{Period: 30, Digits: otp.DigitsSix, Algorithm: otp.AlgorithmSHA1}
+	code, err := totp.GenerateCodeCustom(sharedSecret, Now().Add(time.Duration(step*30)*time.Second), opts)
+	if err != nil {
+		t.Fatalf("totp.GenerateCodeCustom() failed: %s", err)
+	}
+	return code
+}
+
+func TestTotpVerify(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	_, err := ctx.Database.Exec(`insert into passwords (machine, service, user, password, type) values('mymachine', 'myservice', 'myuser', 'JBSWY3DPEHPK3PXP', 'totp');`)
+	if err != nil {
+		t.Fatalf("db.Exec() = %q, want nil", err)
+	}
+	code := createTotpCodeForTesting(t, "JBSWY3DPEHPK3PXP", 0)
+	os.Args = []string{"", "totp", "verify", "-i", "1", code}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	expectedBuf := "Code is valid at time step +0 (+0s from the current time)\n"
+	if outBuf.String() != expectedBuf {
+		t.Fatalf("Main() output is %q, want %q", outBuf.String(), expectedBuf)
+	}
+}
+
+func TestTotpVerifySkew(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	_, err := ctx.Database.Exec(`insert into passwords (machine, service, user, password, type) values('mymachine', 'myservice', 'myuser', 'otpauth://totp/Myserver:myuser?secret=JBSWY3DPEHPK3PXP', 'totp');`)
+	if err != nil {
+		t.Fatalf("db.Exec() = %q, want nil", err)
+	}
+	// The client's clock is 2 time steps behind.
+	code := createTotpCodeForTesting(t, "JBSWY3DPEHPK3PXP", -2)
+	os.Args = []string{"", "totp", "verify", "--skew", "2", code}
+	inBuf := new(bytes.Buffer)
+	inBuf.Write([]byte("1\n"))
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	expectedBuf := "Id: Code is valid at time step -2 (-60s from the current time)\n"
+	if outBuf.String() != expectedBuf {
+		t.Fatalf("Main() output is %q, want %q", outBuf.String(), expectedBuf)
+	}
+}
+
+// Verify fails because the code is outside the accepted time steps.
+func TestTotpVerifyNoMatch(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	_, err := ctx.Database.Exec(`insert into passwords (machine, service, user, password, type) values('mymachine', 'myservice', 'myuser', 'JBSWY3DPEHPK3PXP', 'totp');`)
+	if err != nil {
+		t.Fatalf("db.Exec() = %q, want nil", err)
+	}
+	code := createTotpCodeForTesting(t, "JBSWY3DPEHPK3PXP", 2)
+	os.Args = []string{"", "totp", "verify", "-i", "1", code}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 1
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q", actualRet, expectedRet)
+	}
+}
+
+// Verify fails because the password is not a TOTP shared secret or doesn't exist.
+func TestTotpVerifyBadID(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	_, err := ctx.Database.Exec(`insert into passwords (machine, service, user, password, type) values('mymachine', 'myservice', 'myuser', 'mypassword', 'plain');`)
+	if err != nil {
+		t.Fatalf("db.Exec() = %q, want nil", err)
+	}
+	for _, id := range []string{"1", "2"} {
+		os.Args = []string{"", "totp", "verify", "-i", id, "123456"}
+		inBuf := new(bytes.Buffer)
+		outBuf := new(bytes.Buffer)
+
+		actualRet := Main(inBuf, outBuf)
+
+		expectedRet := 1
+		if actualRet != expectedRet {
+			t.Fatalf("Main() = %q, want %q", actualRet, expectedRet)
+		}
+	}
+}
+
+// "totp" is a value of --type here, not the totp command.
+func TestSelectTypeTotpImplicit(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	_, err := ctx.Database.Exec(`insert into passwords (machine, service, user, password, type) values('mymachine', 'myservice', 'myuser', 'mypassword', 'plain');
+	                             insert into passwords (machine, service, user, password, type) values('mymachine', 'myservice', 'myuser', 'mypassword', 'totp');`)
+	if err != nil {
+		t.Fatalf("db.Exec() = %q, want nil", err)
+	}
+	os.Args = []string{"", "-t", "totp", "--noid", "mymachine"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q", actualRet, expectedRet)
+	}
+	expectedOutput := "machine: mymachine, service: myservice, user: myuser, password type: TOTP shared secret, password: mypassword\n"
+	actualOutput := outBuf.String()
+	if actualOutput != expectedOutput {
+		t.Fatalf("actualOutput = %q, want %q", actualOutput, expectedOutput)
+	}
+}
@@ -0,0 +1,542 @@
+// Copyright 2026 Miklos Vajna
+//
+// SPDX-License-Identifier: MIT
+
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/pquerna/otp/totp"
+	"github.com/rivo/tview"
+	"github.com/spf13/cobra"
+)
+
+// uiHelp lists the keybindings of the password list.
+const uiHelp = "/: filter, r: reveal/hide, c: copy, e: edit, a: archive/unarchive, d: delete, q: quit"
+
+// getCurrentTotpCode returns the current TOTP code of a TOTP password, and the number of seconds
+// till it changes.
+func getCurrentTotpCode(password string) (string, int64, error) {
+	sharedSecret, opts, err := getTotpOpts(password)
+	if err != nil {
+		return "", 0, fmt.Errorf("getTotpOpts() failed: %s", err)
+	}
+
+	code, err := totp.GenerateCodeCustom(sharedSecret, Now(), opts)
+	if err != nil {
+		return "", 0, fmt.Errorf("totp.GenerateCodeCustom() failed: %s", err)
+	}
+
+	left := int64(opts.Period) - Now().Unix()%int64(opts.Period)
+	return code, left, nil
+}
+
+// passwordsUI is the state of the terminal UI.
+type passwordsUI struct {
+	ctx      *Context
+	cmd      *cobra.Command
+	app      *tview.Application
+	pages    *tview.Pages
+	filter   *tview.InputField
+	list     *tview.List
+	details  *tview.TextView
+	status   *tview.TextView
+	rows     []passwordRow
+	revealed bool
+	// Used when editing a password.
+	policy   policyFlags
+	secure   bool
+	strength strengthFlags
+	// The copied password and the number of seconds left till the clipboard is cleared.
+	clipTool    string
+	clipTimeout int
+	clipped     string
+	clipLeft    int
+	// Decides if the database has to be written back.
+	changed bool
+}
+
+// newPasswordsUI creates the widgets of the terminal UI.
+func newPasswordsUI(cmd *cobra.Command, ctx *Context) *passwordsUI {
+	u := &passwordsUI{ctx: ctx, cmd: cmd}
+	u.app = tview.NewApplication()
+	u.filter = tview.NewInputField().SetLabel("Filter: ")
+	u.filter.SetChangedFunc(func(text string) {
+		u.setError(u.refresh())
+	})
+	u.filter.SetDoneFunc(func(key tcell.Key) {
+		u.app.SetFocus(u.list)
+	})
+	u.list = tview.NewList().ShowSecondaryText(false).SetUseStyleTags(false, false)
+	u.list.SetChangedFunc(func(index int, mainText string, secondaryText string, shortcut rune) {
+		// The current item is not yet updated at this point.
+		u.showDetails(index)
+	})
+	u.list.SetInputCapture(u.handleListKey)
+	u.list.SetBorder(true).SetTitle("Passwords")
+	u.details = tview.NewTextView()
+	u.details.SetBorder(true).SetTitle("Details")
+	u.status = tview.NewTextView().SetText(uiHelp)
+	panes := tview.NewFlex().
+		AddItem(u.list, 0, 1, true).
+		AddItem(u.details, 0, 1, false)
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(u.filter, 1, 0, true).
+		AddItem(panes, 0, 1, false).
+		AddItem(u.status, 1, 0, false)
+	u.pages = tview.NewPages().AddPage("main", layout, true, true)
+	u.app.SetRoot(u.pages, true)
+	return u
+}
+
+// getRow returns the password at `index` in the list, if there is one.
+func (u *passwordsUI) getRow(index int) *passwordRow {
+	if index < 0 || index >= len(u.rows) {
+		return nil
+	}
+
+	return &u.rows[index]
+}
+
+// current returns the selected password, if there is one.
+func (u *passwordsUI) current() *passwordRow {
+	return u.getRow(u.list.GetCurrentItem())
+}
+
+// refresh updates the list of passwords to match the filter, keeping the selection if possible.
+func (u *passwordsUI) refresh() error {
+	selected := -1
+	if row := u.current(); row != nil {
+		selected = row.ID
+	}
+
+	// Show archived passwords as well, so they can be unarchived.
+	opts := searchOptions{verbose: true}
+	if len(u.filter.GetText()) > 0 {
+		opts.args = []string{u.filter.GetText()}
+	}
+	rows, err := selectPasswords(u.ctx.Database, opts)
+	if err != nil {
+		return fmt.Errorf("selectPasswords() failed: %s", err)
+	}
+
+	u.rows = rows
+	u.list.Clear()
+	current := 0
+	for i, row := range u.rows {
+		text := fmt.Sprintf("%s, %s, %s, %s", row.Machine, row.Service, row.User, row.PasswordType)
+		if row.Archived {
+			text += " (archived)"
+		}
+		u.list.AddItem(text, "", 0, nil)
+		if row.ID == selected {
+			current = i
+		}
+	}
+	u.list.SetCurrentItem(current)
+	u.showDetails(u.list.GetCurrentItem())
+	return nil
+}
+
+// showDetails shows the password at `index` in the detail pane.
+func (u *passwordsUI) showDetails(index int) {
+	row := u.getRow(index)
+	if row == nil {
+		u.details.SetText("No matching passwords")
+		return
+	}
+
+	var lines []string
+	lines = append(lines, fmt.Sprintf("ID: %d", row.ID))
+	lines = append(lines, fmt.Sprintf("Machine: %s", row.Machine))
+	lines = append(lines, fmt.Sprintf("Service: %s", row.Service))
+	lines = append(lines, fmt.Sprintf("User: %s", row.User))
+	lines = append(lines, fmt.Sprintf("Password type: %s", row.PasswordType))
+	password := "********"
+	if u.revealed {
+		password = row.Password
+	}
+	switch row.PasswordType {
+	case PasswordTypeTotp:
+		lines = append(lines, fmt.Sprintf("TOTP shared secret: %s", password))
+		code, left, err := getCurrentTotpCode(row.Password)
+		if err != nil {
+			code = err.Error()
+		} else {
+			code += fmt.Sprintf(" (%d seconds left)", left)
+		}
+		lines = append(lines, fmt.Sprintf("TOTP code: %s", code))
+	case PasswordTypeRecoveryCodes:
+		if u.revealed {
+			codes, err := decodeRecoveryCodes(row.Password)
+			if err != nil {
+				password = err.Error()
+			} else {
+				password = strings.Join(getUnusedRecoveryCodes(codes), " ")
+			}
+		}
+		lines = append(lines, fmt.Sprintf("Unused recovery codes: %s", password))
+	default:
+		lines = append(lines, fmt.Sprintf("Password: %s", password))
+		lines = append(lines, fmt.Sprintf("Strength: %d/%d", getPasswordStrength(row.Password, row.Machine, row.User), maxStrength))
+	}
+	lines = append(lines, fmt.Sprintf("Archived: %v", row.Archived))
+	for _, field := range []struct{ name, value string }{{"Created", row.Created}, {"Modified", row.Modified}, {"Expires", row.Expires}} {
+		t, err := time.Parse(time.RFC3339, field.value)
+		if err != nil {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s", field.name, t.Format("2006-01-02 15:04")))
+	}
+	if len(row.Notes) > 0 {
+		lines = append(lines, fmt.Sprintf("Notes: %s", row.Notes))
+	}
+	u.details.SetText(strings.Join(lines, "\n"))
+}
+
+// setStatus replaces the content of the status line.
+func (u *passwordsUI) setStatus(format string, a ...any) {
+	u.status.SetText(fmt.Sprintf(format, a...))
+}
+
+// setError shows `err` in the status line, if it's not nil.
+func (u *passwordsUI) setError(err error) {
+	if err != nil {
+		u.setStatus("Error: %s", err)
+	}
+}
+
+// handleListKey handles the keybindings of the password list.
+func (u *passwordsUI) handleListKey(event *tcell.EventKey) *tcell.EventKey {
+	if event.Key() != tcell.KeyRune {
+		return event
+	}
+
+	var err error
+	switch event.Rune() {
+	case '/':
+		u.app.SetFocus(u.filter)
+	case 'r':
+		u.revealed = !u.revealed
+		u.showDetails(u.list.GetCurrentItem())
+	case 'c':
+		err = u.copy()
+	case 'e':
+		u.edit()
+	case 'a':
+		err = u.archive()
+	case 'd':
+		u.confirmDelete()
+	case 'q':
+		u.app.Stop()
+	default:
+		return event
+	}
+	u.setError(err)
+	return nil
+}
+
+// copy copies the selected password or the current TOTP code to the clipboard.
+func (u *passwordsUI) copy() error {
+	row := u.current()
+	if row == nil {
+		return nil
+	}
+
+	password := row.Password
+	switch row.PasswordType {
+	case PasswordTypeTotp:
+		var err error
+		password, _, err = getCurrentTotpCode(row.Password)
+		if err != nil {
+			return fmt.Errorf("getCurrentTotpCode() failed: %s", err)
+		}
+	case PasswordTypeRecoveryCodes:
+		codes, err := decodeRecoveryCodes(row.Password)
+		if err != nil {
+			return fmt.Errorf("decodeRecoveryCodes() failed: %s", err)
+		}
+
+		password = strings.Join(getUnusedRecoveryCodes(codes), " ")
+	}
+
+	err := copyToClipboard(u.cmd.OutOrStdout(), u.clipTool, password)
+	if err != nil {
+		return fmt.Errorf("copyToClipboard() failed: %s", err)
+	}
+
+	if u.clipTimeout <= 0 {
+		u.setStatus("Copied the password to the clipboard")
+		return nil
+	}
+
+	u.clipped = password
+	u.clipLeft = u.clipTimeout
+	u.setStatus("Copied the password to the clipboard, clearing it in %d seconds", u.clipTimeout)
+	return nil
+}
+
+// clearClipboard clears the clipboard, unless it was changed since the last copy.
+func (u *passwordsUI) clearClipboard() error {
+	if len(u.clipped) == 0 {
+		return nil
+	}
+
+	cleared, err := clearClipboard(u.cmd.OutOrStdout(), u.clipTool, u.clipped)
+	if err != nil {
+		return fmt.Errorf("clearClipboard() failed: %s", err)
+	}
+
+	u.clipped = ""
+	u.clipLeft = 0
+	if cleared {
+		u.setStatus("Cleared the clipboard")
+	} else {
+		u.setStatus("Not clearing the clipboard, its content changed in the meantime")
+	}
+	return nil
+}
+
+// tick is called every second: refreshes the TOTP code and clears the clipboard when it's time.
+func (u *passwordsUI) tick() {
+	u.showDetails(u.list.GetCurrentItem())
+	if u.clipLeft > 0 {
+		u.clipLeft--
+		if u.clipLeft == 0 {
+			u.setError(u.clearClipboard())
+		}
+	}
+}
+
+// update updates the password with `id` in a transaction, like 'cpm update' does.
+func (u *passwordsUI) update(id int, update passwordUpdate) error {
+	transaction, err := u.ctx.Database.Begin()
+	if err != nil {
+		return fmt.Errorf("db.Begin() failed: %s", err)
+	}
+
+	defer transaction.Rollback()
+
+	// Warnings about weak passwords go to the status line.
+	u.status.Clear()
+	update.policy = u.policy
+	update.secure = u.secure
+	update.strength = u.strength
+	result, err := updatePassword(u.cmd, transaction, strconv.Itoa(id), update)
+	if err != nil {
+		return fmt.Errorf("updatePassword() failed: %s", err)
+	}
+
+	err = transaction.Commit()
+	if err != nil {
+		return fmt.Errorf("transaction.Commit() failed: %s", err)
+	}
+
+	u.changed = true
+	if len(u.status.GetText(false)) == 0 {
+		u.setStatus("Updated %v password", result.affected)
+	}
+	err = u.refresh()
+	if err != nil {
+		return fmt.Errorf("refresh() failed: %s", err)
+	}
+
+	return nil
+}
+
+// archive archives the selected password, or unarchives it if it's already archived.
+func (u *passwordsUI) archive() error {
+	row := u.current()
+	if row == nil {
+		return nil
+	}
+
+	err := u.update(row.ID, passwordUpdate{archived: strconv.FormatBool(!row.Archived)})
+	if err != nil {
+		return fmt.Errorf("update() failed: %s", err)
+	}
+
+	return nil
+}
+
+// edit shows a form to edit the selected password.
+func (u *passwordsUI) edit() {
+	row := u.current()
+	if row == nil {
+		return
+	}
+
+	form := tview.NewForm().
+		AddInputField("Machine", row.Machine, 40, nil, nil).
+		AddInputField("Service", row.Service, 40, nil, nil).
+		AddInputField("User", row.User, 40, nil, nil).
+		AddPasswordField("Password", "", 40, '*', nil)
+	form.AddButton("Save", func() {
+		u.setError(u.saveEdit(form, *row))
+	})
+	form.AddButton("Cancel", u.closeDialog)
+	form.SetCancelFunc(u.closeDialog)
+	form.SetBorder(true).SetTitle(`Edit password (empty password: keep unchanged, "-": generate)`)
+	u.pages.AddPage("dialog", form, true, true)
+}
+
+// saveEdit updates the password of `row` using the changed fields of `form`.
+func (u *passwordsUI) saveEdit(form *tview.Form, row passwordRow) error {
+	u.closeDialog()
+	var update passwordUpdate
+	if machine := form.GetFormItemByLabel("Machine").(*tview.InputField).GetText(); machine != row.Machine {
+		update.machine = machine
+	}
+	if service := form.GetFormItemByLabel("Service").(*tview.InputField).GetText(); service != row.Service {
+		update.service = service
+	}
+	if user := form.GetFormItemByLabel("User").(*tview.InputField).GetText(); user != row.User {
+		update.user = user
+	}
+	update.password = form.GetFormItemByLabel("Password").(*tview.InputField).GetText()
+	err := u.update(row.ID, update)
+	if err != nil {
+		return fmt.Errorf("update() failed: %s", err)
+	}
+
+	return nil
+}
+
+// confirmDelete asks if the selected password should be really deleted.
+func (u *passwordsUI) confirmDelete() {
+	row := u.current()
+	if row == nil {
+		return
+	}
+
+	id := row.ID
+	modal := tview.NewModal().
+		SetText(fmt.Sprintf("Delete the password of %s on %s?", row.User, row.Machine)).
+		AddButtons([]string{"Delete", "Cancel"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			u.closeDialog()
+			if buttonLabel == "Delete" {
+				u.setError(u.delete(id))
+			}
+		})
+	u.pages.AddPage("dialog", modal, true, true)
+}
+
+// delete deletes the password with `id` in a transaction, like 'cpm delete' does.
+func (u *passwordsUI) delete(id int) error {
+	transaction, err := u.ctx.Database.Begin()
+	if err != nil {
+		return fmt.Errorf("db.Begin() failed: %s", err)
+	}
+
+	defer transaction.Rollback()
+
+	affected, err := deletePassword(transaction, strconv.Itoa(id))
+	if err != nil {
+		return fmt.Errorf("deletePassword() failed: %s", err)
+	}
+
+	err = transaction.Commit()
+	if err != nil {
+		return fmt.Errorf("transaction.Commit() failed: %s", err)
+	}
+
+	u.changed = true
+	u.setStatus("Deleted %v password", affected)
+	err = u.refresh()
+	if err != nil {
+		return fmt.Errorf("refresh() failed: %s", err)
+	}
+
+	return nil
+}
+
+// closeDialog closes the edit form or the delete confirmation.
+func (u *passwordsUI) closeDialog() {
+	u.pages.RemovePage("dialog")
+	u.app.SetFocus(u.list)
+}
+
+// run runs the terminal UI till the user quits.
+func (u *passwordsUI) run() error {
+	err := u.refresh()
+	if err != nil {
+		return fmt.Errorf("refresh() failed: %s", err)
+	}
+
+	screen, err := NewScreen()
+	if err != nil {
+		return fmt.Errorf("NewScreen() failed: %s", err)
+	}
+
+	u.app.SetScreen(screen)
+	done := make(chan struct{})
+	// The ticker may still wake up once after returning, don't look up Sleep at that point.
+	sleep := Sleep
+	go func() {
+		for {
+			sleep(time.Second)
+			select {
+			case <-done:
+				return
+			default:
+				u.app.QueueUpdateDraw(u.tick)
+			}
+		}
+	}()
+	// Weak password warnings would mess up the screen, show them in the status line instead.
+	stderr := u.cmd.ErrOrStderr()
+	u.cmd.SetErr(u.status)
+	err = u.app.Run()
+	close(done)
+	u.cmd.SetErr(stderr)
+	if err != nil {
+		return fmt.Errorf("app.Run() failed: %s", err)
+	}
+
+	// Don't leave a password on the clipboard.
+	err = u.clearClipboard()
+	if err != nil {
+		return fmt.Errorf("clearClipboard() failed: %s", err)
+	}
+
+	return nil
+}
+
+func newUICommand(ctx *Context) *cobra.Command {
+	var clipTimeoutFlag int
+	var policy policyFlags
+	var secure bool
+	var strength strengthFlags
+	var cmd = &cobra.Command{
+		Use:   "ui",
+		Short: "browses and edits passwords in a terminal UI",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			u := newPasswordsUI(cmd, ctx)
+			u.policy = policy
+			u.secure = secure
+			u.strength = strength
+			u.clipTool = getClipboardTool()
+			u.clipTimeout = clipTimeoutFlag
+			err := u.run()
+			// Only write the database back if something was changed.
+			ctx.NoWriteBack = !u.changed
+			if err != nil {
+				return fmt.Errorf("run() failed: %s", err)
+			}
+
+			return nil
+		},
+	}
+	cmd.Flags().IntVarP(&clipTimeoutFlag, "clip-timeout", "", 45, "clear the clipboard after this many seconds, 0 keeps the password there")
+	cmd.Flags().BoolVarP(&secure, "secure", "y", false, `increase number of symbols from 0 to 3, when generating a password (default: false)`)
+	addPolicyFlags(cmd, &policy)
+	addStrengthFlags(cmd, &strength)
+
+	return cmd
+}
@@ -0,0 +1,500 @@
+// Copyright 2026 Miklos Vajna
+//
+// SPDX-License-Identifier: MIT
+
+package commands
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/spf13/cobra"
+)
+
+// scriptedScreen is a simulation screen which types `keys` once it's initialized and `ticked` is
+// closed.
+type scriptedScreen struct {
+	tcell.SimulationScreen
+	keys   []*tcell.EventKey
+	ticked chan struct{}
+}
+
+func (s *scriptedScreen) Init() error {
+	err := s.SimulationScreen.Init()
+	go func() {
+		<-s.ticked
+		for _, key := range s.keys {
+			s.PostEventWait(key)
+		}
+	}()
+	return err
+}
+
+// typeForTesting returns the key events to type `text`.
+func typeForTesting(text string) []*tcell.EventKey {
+	var keys []*tcell.EventKey
+	for _, r := range text {
+		keys = append(keys, tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+	}
+	return keys
+}
+
+// UseScreenForTesting makes the terminal UI use a simulation screen, which types `keys` after the
+// first background tick. The background ticks are also sped up.
+func UseScreenForTesting(t *testing.T, keys []*tcell.EventKey) {
+	ticked := make(chan struct{})
+	oldNewScreen := NewScreen
+	NewScreen = func() (tcell.Screen, error) {
+		return &scriptedScreen{SimulationScreen: tcell.NewSimulationScreen(""), keys: keys, ticked: ticked}, nil
+	}
+	t.Cleanup(func() { NewScreen = oldNewScreen })
+	sleeps := 0
+	oldSleep := Sleep
+	Sleep = func(d time.Duration) {
+		// The second sleep means that the first tick is already queued.
+		sleeps++
+		if sleeps == 2 {
+			close(ticked)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Cleanup(func() { Sleep = oldSleep })
+}
+
+// newPasswordsUIForTesting creates a terminal UI without running it.
+func newPasswordsUIForTesting(t *testing.T, ctx *Context) (*passwordsUI, *bytes.Buffer) {
+	outBuf := new(bytes.Buffer)
+	cmd := &cobra.Command{}
+	cmd.SetOut(outBuf)
+	u := newPasswordsUI(cmd, ctx)
+	cmd.SetErr(u.status)
+	err := u.refresh()
+	if err != nil {
+		t.Fatalf("refresh() = %q, want nil", err)
+	}
+	return u, outBuf
+}
+
+// pressForTesting sends a key to the password list of `u`.
+func pressForTesting(u *passwordsUI, r rune) *tcell.EventKey {
+	return u.handleListKey(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+}
+
+func TestUI(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	_, err := ctx.Database.Exec(`insert into passwords (machine, service, user, password, type) values('mymachine', 'http', 'myuser', 'mypassword', 'plain');
+	                             insert into passwords (machine, service, user, password, type) values('othermachine', 'http', 'myuser', 'otherpassword', 'plain');`)
+	if err != nil {
+		t.Fatalf("db.Exec() = %q, want nil", err)
+	}
+	// Filter for the second password, archive it and quit.
+	keys := typeForTesting("other")
+	keys = append(keys, tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone))
+	keys = append(keys, typeForTesting("aq")...)
+	UseScreenForTesting(t, keys)
+	os.Args = []string{"", "ui"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	results, err := readPasswords(ctx.Database, searchOptions{})
+	if err != nil {
+		t.Fatalf("readPasswords() err = %q, want nil", err)
+	}
+	expected := []string{"id:        1, machine: mymachine, service: http, user: myuser, password type: plain, password: mypassword"}
+	if len(results) != 1 || results[0] != expected[0] {
+		t.Fatalf("results = %q, want %q", results, expected)
+	}
+}
+
+func TestUIDelete(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	_, err := ctx.Database.Exec(`insert into passwords (machine, service, user, password, type) values('mymachine', 'http', 'myuser', 'mypassword', 'plain');`)
+	if err != nil {
+		t.Fatalf("db.Exec() = %q, want nil", err)
+	}
+	// Go to the list, delete the password, confirm and quit.
+	keys := []*tcell.EventKey{tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone)}
+	keys = append(keys, typeForTesting("d")...)
+	keys = append(keys, tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone))
+	keys = append(keys, typeForTesting("q")...)
+	UseScreenForTesting(t, keys)
+	os.Args = []string{"", "ui"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	results, err := readPasswords(ctx.Database, searchOptions{verbose: true})
+	if err != nil {
+		t.Fatalf("readPasswords() err = %q, want nil", err)
+	}
+	if len(results) != 0 {
+		t.Fatalf("results = %q, want none", results)
+	}
+}
+
+// Just quitting doesn't write the database back.
+func TestUINoChange(t *testing.T) {
+	CreateContextForTesting(t)
+	var noWriteBack bool
+	oldCloseDatabase := CloseDatabase
+	CloseDatabase = func(ctx *Context) error {
+		noWriteBack = ctx.NoWriteBack
+		return nil
+	}
+	t.Cleanup(func() { CloseDatabase = oldCloseDatabase })
+	keys := []*tcell.EventKey{tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone)}
+	keys = append(keys, typeForTesting("q")...)
+	UseScreenForTesting(t, keys)
+	os.Args = []string{"", "ui"}
+	inBuf := new(bytes.Buffer)
+	outBuf := new(bytes.Buffer)
+
+	actualRet := Main(inBuf, outBuf)
+
+	expectedRet := 0
+	if actualRet != expectedRet {
+		t.Fatalf("Main() = %q, want %q, output is %q", actualRet, expectedRet, outBuf.String())
+	}
+	if !noWriteBack {
+		t.Fatalf("ctx.NoWriteBack = false, want true")
+	}
+}
+
+func TestUIEmpty(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	u, _ := newPasswordsUIForTesting(t, &ctx)
+
+	// None of these do anything without a selected password.
+	for _, r := range "ecad" {
+		pressForTesting(u, r)
+	}
+
+	if u.details.GetText(true) != "No matching passwords" {
+		t.Fatalf("details = %q, want %q", u.details.GetText(true), "No matching passwords")
+	}
+	if u.pages.HasPage("dialog") {
+		t.Fatalf("pages has a dialog, want none")
+	}
+	if u.changed {
+		t.Fatalf("u.changed = true, want false")
+	}
+}
+
+func TestUIKeys(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	u, _ := newPasswordsUIForTesting(t, &ctx)
+
+	// Keys without a binding are passed to the list.
+	if pressForTesting(u, 'x') == nil {
+		t.Fatalf("handleListKey(x) = nil, want the event")
+	}
+	if u.handleListKey(tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)) == nil {
+		t.Fatalf("handleListKey(down) = nil, want the event")
+	}
+	if pressForTesting(u, '/') != nil {
+		t.Fatalf("handleListKey(/) != nil, want nil")
+	}
+	if u.app.GetFocus() != u.filter {
+		t.Fatalf("focus is not on the filter")
+	}
+}
+
+func TestUIDetails(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	_, err := ctx.Database.Exec(`insert into passwords (machine, service, user, password, type, created, modified, expires) values('mymachine', 'http', 'myuser', 'mypassword', 'plain', '2020-05-01T00:00:00+02:00', '2020-05-02T00:00:00+02:00', '2020-06-01T00:00:00+02:00');
+	                             insert into passwords (machine, service, user, password, type, notes) values('mymachine', 'http', 'myuser', 'JBSWY3DPEHPK3PXP', 'totp', 'mynotes');
+	                             insert into passwords (machine, service, user, password, type) values('mymachine', 'http', 'myuser', '[{"Code":"code1","Used":true},{"Code":"code2"}]', 'recovery-codes');
+	                             insert into passwords (machine, service, user, password, type) values('badmachine', 'http', 'myuser', 'otpauth://%', 'totp');
+	                             insert into passwords (machine, service, user, password, type) values('badmachine', 'http', 'myuser', 'garbage', 'recovery-codes');`)
+	if err != nil {
+		t.Fatalf("db.Exec() = %q, want nil", err)
+	}
+	u, _ := newPasswordsUIForTesting(t, &ctx)
+	pressForTesting(u, 'r')
+	expected := []string{
+		"ID: 1\nMachine: mymachine\nService: http\nUser: myuser\nPassword type: plain\nPassword: mypassword\nStrength: 0/4\nArchived: false\nCreated: 2020-05-01 00:00\nModified: 2020-05-02 00:00\nExpires: 2020-06-01 00:00",
+		"ID: 2\nMachine: mymachine\nService: http\nUser: myuser\nPassword type: totp\nTOTP shared secret: JBSWY3DPEHPK3PXP\nTOTP code: 626953 (30 seconds left)\nArchived: false\nNotes: mynotes",
+		"ID: 3\nMachine: mymachine\nService: http\nUser: myuser\nPassword type: recovery-codes\nUnused recovery codes: code2\nArchived: false",
+		"ID: 4\nMachine: badmachine\nService: http\nUser: myuser\nPassword type: totp\nTOTP shared secret: otpauth://%\nTOTP code: getTotpOpts() failed: parsePassword() failed: url.Parse() failed: parse \"otpauth://%\": invalid URL escape \"%\"\nArchived: false",
+		"ID: 5\nMachine: badmachine\nService: http\nUser: myuser\nPassword type: recovery-codes\nUnused recovery codes: json.Unmarshal() failed: invalid character 'g' looking for beginning of value\nArchived: false",
+	}
+
+	for i := range expected {
+		u.list.SetCurrentItem(i)
+		if u.details.GetText(true) != expected[i] {
+			t.Fatalf("details = %q, want %q", u.details.GetText(true), expected[i])
+		}
+	}
+
+	// Hiding works with all password types.
+	pressForTesting(u, 'r')
+	for i, want := range []string{"Password: ********", "TOTP shared secret: ********", "Unused recovery codes: ********"} {
+		u.list.SetCurrentItem(i)
+		if !strings.Contains(u.details.GetText(true), want) {
+			t.Fatalf("details = %q, want to contain %q", u.details.GetText(true), want)
+		}
+	}
+}
+
+func TestUIFilter(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	_, err := ctx.Database.Exec(`insert into passwords (machine, service, user, password, type) values('mymachine', 'http', 'myuser', 'mypassword', 'plain');
+	                             insert into passwords (machine, service, user, password, type, archived) values('othermachine', 'http', 'myuser', 'otherpassword', 'plain', 1);`)
+	if err != nil {
+		t.Fatalf("db.Exec() = %q, want nil", err)
+	}
+	u, _ := newPasswordsUIForTesting(t, &ctx)
+	u.list.SetCurrentItem(1)
+
+	u.filter.SetText("machine")
+
+	// Archived passwords are shown, and the selection is kept.
+	if u.list.GetItemCount() != 2 {
+		t.Fatalf("item count = %v, want 2", u.list.GetItemCount())
+	}
+	mainText, _ := u.list.GetItemText(1)
+	if mainText != "othermachine, http, myuser, plain (archived)" {
+		t.Fatalf("item text = %q, want %q", mainText, "othermachine, http, myuser, plain (archived)")
+	}
+	if u.list.GetCurrentItem() != 1 {
+		t.Fatalf("current item = %v, want 1", u.list.GetCurrentItem())
+	}
+
+	u.filter.SetText("other")
+
+	if u.list.GetItemCount() != 1 {
+		t.Fatalf("item count = %v, want 1", u.list.GetItemCount())
+	}
+	if u.list.GetCurrentItem() != 0 {
+		t.Fatalf("current item = %v, want 0", u.list.GetCurrentItem())
+	}
+}
+
+func TestUICopy(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	t.Setenv("WAYLAND_DISPLAY", "wayland-0")
+	path := UseClipboardForTesting(t, func(path string, d time.Duration) {})
+	_, err := ctx.Database.Exec(`insert into passwords (machine, service, user, password, type) values('mymachine', 'http', 'myuser', 'mypassword', 'plain');
+	                             insert into passwords (machine, service, user, password, type, notes) values('mymachine', 'http', 'myuser', 'JBSWY3DPEHPK3PXP', 'totp', 'mynotes');
+	                             insert into passwords (machine, service, user, password, type) values('mymachine', 'http', 'myuser', '[{"Code":"code1","Used":true},{"Code":"code2"},{"Code":"code3"}]', 'recovery-codes');`)
+	if err != nil {
+		t.Fatalf("db.Exec() = %q, want nil", err)
+	}
+	u, _ := newPasswordsUIForTesting(t, &ctx)
+	u.clipTool = getClipboardTool()
+	u.clipTimeout = 2
+
+	// The TOTP code is copied, not the shared secret; the unused recovery codes are copied.
+	for i, want := range []string{"mypassword", "626953", "code2 code3"} {
+		u.list.SetCurrentItem(i)
+		pressForTesting(u, 'c')
+		if readClipboardForTesting(t, path) != want {
+			t.Fatalf("clipboard = %q, want %q", readClipboardForTesting(t, path), want)
+		}
+	}
+	expectedStatus := "Copied the password to the clipboard, clearing it in 2 seconds"
+	if u.status.GetText(true) != expectedStatus {
+		t.Fatalf("status = %q, want %q", u.status.GetText(true), expectedStatus)
+	}
+
+	u.tick()
+	if readClipboardForTesting(t, path) != "code2 code3" {
+		t.Fatalf("clipboard = %q, want %q", readClipboardForTesting(t, path), "code2 code3")
+	}
+	u.tick()
+	if readClipboardForTesting(t, path) != "" {
+		t.Fatalf("clipboard = %q, want empty", readClipboardForTesting(t, path))
+	}
+	if u.status.GetText(true) != "Cleared the clipboard" {
+		t.Fatalf("status = %q, want %q", u.status.GetText(true), "Cleared the clipboard")
+	}
+
+	// Something else was copied in the meantime: leave that alone.
+	u.list.SetCurrentItem(0)
+	pressForTesting(u, 'c')
+	err = os.WriteFile(path, []byte("other"), 0600)
+	if err != nil {
+		t.Fatalf("os.WriteFile() failed: %s", err)
+	}
+	u.tick()
+	u.tick()
+	if readClipboardForTesting(t, path) != "other" {
+		t.Fatalf("clipboard = %q, want %q", readClipboardForTesting(t, path), "other")
+	}
+	expectedStatus = "Not clearing the clipboard, its content changed in the meantime"
+	if u.status.GetText(true) != expectedStatus {
+		t.Fatalf("status = %q, want %q", u.status.GetText(true), expectedStatus)
+	}
+
+	// No timeout: keep the password on the clipboard.
+	u.clipTimeout = 0
+	pressForTesting(u, 'c')
+	if u.status.GetText(true) != "Copied the password to the clipboard" {
+		t.Fatalf("status = %q, want %q", u.status.GetText(true), "Copied the password to the clipboard")
+	}
+}
+
+// Copy fails because the TOTP shared secret is invalid.
+func TestUICopyBadTotp(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	_, err := ctx.Database.Exec(`insert into passwords (machine, service, user, password, type) values('mymachine', 'http', 'myuser', 'otpauth://%', 'totp');`)
+	if err != nil {
+		t.Fatalf("db.Exec() = %q, want nil", err)
+	}
+	u, outBuf := newPasswordsUIForTesting(t, &ctx)
+
+	pressForTesting(u, 'c')
+
+	if !strings.HasPrefix(u.status.GetText(true), "Error: getCurrentTotpCode() failed") {
+		t.Fatalf("status = %q, want an error", u.status.GetText(true))
+	}
+	if outBuf.Len() != 0 {
+		t.Fatalf("output = %q, want empty", outBuf.String())
+	}
+}
+
+func TestUIArchive(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	_, err := ctx.Database.Exec(`insert into passwords (machine, service, user, password, type) values('mymachine', 'http', 'myuser', 'mypassword', 'plain');`)
+	if err != nil {
+		t.Fatalf("db.Exec() = %q, want nil", err)
+	}
+	u, _ := newPasswordsUIForTesting(t, &ctx)
+
+	pressForTesting(u, 'a')
+
+	if !u.rows[0].Archived || u.rows[0].Modified != "2020-05-10T00:00:00+02:00" {
+		t.Fatalf("row = %v, want archived", u.rows[0])
+	}
+	if u.status.GetText(true) != "Updated 1 password" {
+		t.Fatalf("status = %q, want %q", u.status.GetText(true), "Updated 1 password")
+	}
+
+	pressForTesting(u, 'a')
+
+	if u.rows[0].Archived {
+		t.Fatalf("row = %v, want not archived", u.rows[0])
+	}
+	if !u.changed {
+		t.Fatalf("u.changed = false, want true")
+	}
+}
+
+// getFormFieldForTesting returns the input field of `form` with `label`.
+func getFormFieldForTesting(form *tview.Form, label string) *tview.InputField {
+	return form.GetFormItemByLabel(label).(*tview.InputField)
+}
+
+// openEditForTesting opens the edit form of the selected password.
+func openEditForTesting(t *testing.T, u *passwordsUI) *tview.Form {
+	pressForTesting(u, 'e')
+	name, page := u.pages.GetFrontPage()
+	if name != "dialog" {
+		t.Fatalf("front page = %q, want %q", name, "dialog")
+	}
+	return page.(*tview.Form)
+}
+
+func TestUIEdit(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	_, err := ctx.Database.Exec(`insert into passwords (machine, service, user, password, type, rotate_every) values('mymachine', 'http', 'myuser', 'mypassword', 'plain', 10);`)
+	if err != nil {
+		t.Fatalf("db.Exec() = %q, want nil", err)
+	}
+	u, _ := newPasswordsUIForTesting(t, &ctx)
+	form := openEditForTesting(t, u)
+	if getFormFieldForTesting(form, "Machine").GetText() != "mymachine" {
+		t.Fatalf("machine = %q, want %q", getFormFieldForTesting(form, "Machine").GetText(), "mymachine")
+	}
+	getFormFieldForTesting(form, "Machine").SetText("newmachine")
+	getFormFieldForTesting(form, "Service").SetText("ssh")
+	getFormFieldForTesting(form, "User").SetText("newuser")
+	getFormFieldForTesting(form, "Password").SetText("-")
+
+	// Press Save.
+	form.GetButton(0).InputHandler()(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), nil)
+
+	if u.pages.HasPage("dialog") {
+		t.Fatalf("pages has a dialog, want none")
+	}
+	row := u.rows[0]
+	if row.Machine != "newmachine" || row.Service != "ssh" || row.User != "newuser" || row.Password != "output-from-pwgen" {
+		t.Fatalf("row = %v, want updated", row)
+	}
+	// The new password restarts the rotation.
+	if row.Expires != "2020-05-20T00:00:00+02:00" {
+		t.Fatalf("row.Expires = %q, want %q", row.Expires, "2020-05-20T00:00:00+02:00")
+	}
+
+	// Cancel doesn't change anything.
+	form = openEditForTesting(t, u)
+	getFormFieldForTesting(form, "Machine").SetText("othermachine")
+	form.GetButton(1).InputHandler()(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), nil)
+	if u.pages.HasPage("dialog") || u.rows[0].Machine != "newmachine" {
+		t.Fatalf("row = %v, pages has dialog = %v, want unchanged without a dialog", u.rows[0], u.pages.HasPage("dialog"))
+	}
+}
+
+// A weak password is updated, but there is a warning.
+func TestUIEditWeak(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	_, err := ctx.Database.Exec(`insert into passwords (machine, service, user, password, type) values('mymachine', 'http', 'myuser', 'mypassword', 'plain');`)
+	if err != nil {
+		t.Fatalf("db.Exec() = %q, want nil", err)
+	}
+	u, _ := newPasswordsUIForTesting(t, &ctx)
+	u.strength.minStrength = defaultMinStrength
+	form := openEditForTesting(t, u)
+	getFormFieldForTesting(form, "Password").SetText("123456")
+
+	err = u.saveEdit(form, u.rows[0])
+
+	if err != nil {
+		t.Fatalf("saveEdit() = %q, want nil", err)
+	}
+	if u.rows[0].Password != "123456" {
+		t.Fatalf("row.Password = %q, want %q", u.rows[0].Password, "123456")
+	}
+	expectedStatus := "Warning: password is weak: strength is 0/4, recommended at least 3"
+	if !strings.HasPrefix(u.status.GetText(true), expectedStatus) {
+		t.Fatalf("status = %q, want %q", u.status.GetText(true), expectedStatus)
+	}
+}
+
+// Edit fails because the password is too weak.
+func TestUIEditEnforceStrength(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	_, err := ctx.Database.Exec(`insert into passwords (machine, service, user, password, type) values('mymachine', 'http', 'myuser', 'mypassword', 'plain');`)
+	if err != nil {
+		t.Fatalf("db.Exec() = %q, want nil", err)
+	}
+	u, _ := newPasswordsUIForTesting(t, &ctx)
+	u.strength = strengthFlags{minStrength: defaultMinStrength, enforceStrength: true}
+	form := openEditForTesting(t, u)
+	getFormFieldForTesting(form, "Password").SetText("123456")
+
+	err = u.saveEdit(form, u.rows[0])
+
+	if err == nil {
+		t.Fatalf("saveEdit() = nil, want an error")
+	}
+	if u.rows[0].Password != "mypassword" || u.changed {
+		t.Fatalf("row.Password = %q, u.changed = %v, want unchanged", u.rows[0].Password, u.changed)
+	}
+}
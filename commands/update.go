@@ -6,6 +6,7 @@ package commands
 
 import (
 	"bufio"
+	"database/sql"
 	"fmt"
 	"strconv"
 	"strings"
@@ -14,16 +15,223 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// passwordUpdate describes the new values of a password, empty values keep the old ones.
+type passwordUpdate struct {
+	machine      string
+	service      string
+	user         string
+	passwordType PasswordType
+	// "-" generates a new one.
+	password string
+	archived string
+	// Only used if setExpires is true, an empty value clears the expiry date.
+	expires    string
+	setExpires bool
+	// Only used if setRotateEvery is true.
+	rotateEvery    int
+	setRotateEvery bool
+	// Only used if setNotes is true, an empty value clears the notes.
+	notes    string
+	setNotes bool
+	policy   policyFlags
+	secure   bool
+	strength strengthFlags
+}
+
+// updateResult describes the outcome of updatePassword.
+type updateResult struct {
+	affected int64
+	// The new password, in case it was generated.
+	generatedPassword string
+	entropy           float64
+}
+
+// updateColumn sets a single column of a password, also updating its modification time.
+func updateColumn(transaction *sql.Tx, id, column string, value any, now string) (int64, error) {
+	query, err := transaction.Prepare(fmt.Sprintf("update passwords set %s=?, modified=? where id=?", column))
+	if err != nil {
+		return 0, fmt.Errorf("db.Prepare() failed: %s", err)
+	}
+
+	result, err := query.Exec(value, now, id)
+	if err != nil {
+		return 0, fmt.Errorf("db.Exec() failed: %s", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("result.RowsAffected() failed: %s", err)
+	}
+
+	return affected, nil
+}
+
+// typeChangeNeedsPassword decides if changing the type of a password from `oldType` to `newType`
+// needs the password to be set again: the stored form of recovery codes is different.
+func typeChangeNeedsPassword(oldType, newType PasswordType) bool {
+	return oldType != newType && (oldType == PasswordTypeRecoveryCodes || newType == PasswordTypeRecoveryCodes)
+}
+
+// updatePassword updates the password with `id` in `transaction`, as described by `update`.
+func updatePassword(cmd *cobra.Command, transaction *sql.Tx, id string, update passwordUpdate) (updateResult, error) {
+	var ret updateResult
+	var err error
+	now := Now().Format(time.RFC3339)
+	if len(update.machine) > 0 {
+		ret.affected, err = updateColumn(transaction, id, "machine", update.machine, now)
+		if err != nil {
+			return ret, fmt.Errorf("updateColumn() failed: %s", err)
+		}
+	}
+	if len(update.service) > 0 {
+		ret.affected, err = updateColumn(transaction, id, "service", update.service, now)
+		if err != nil {
+			return ret, fmt.Errorf("updateColumn() failed: %s", err)
+		}
+	}
+	if len(update.user) > 0 {
+		ret.affected, err = updateColumn(transaction, id, "user", update.user, now)
+		if err != nil {
+			return ret, fmt.Errorf("updateColumn() failed: %s", err)
+		}
+	}
+	if len(update.passwordType) > 0 {
+		if len(update.password) == 0 && !update.policy.passphrase {
+			var oldType PasswordType
+			row := transaction.QueryRow("select type from passwords where id=?", id)
+			err = row.Scan(&oldType)
+			if err != nil && err != sql.ErrNoRows {
+				return ret, fmt.Errorf("row.Scan() failed: %s", err)
+			}
+			if typeChangeNeedsPassword(oldType, update.passwordType) {
+				return ret, fmt.Errorf("changing the type from '%s' to '%s' needs a new password", oldType, update.passwordType)
+			}
+		}
+		ret.affected, err = updateColumn(transaction, id, "type", update.passwordType, now)
+		if err != nil {
+			return ret, fmt.Errorf("updateColumn() failed: %s", err)
+		}
+	}
+	password := update.password
+	if update.policy.passphrase {
+		password = "-"
+	}
+	if len(password) > 0 {
+		// The machine and type are already updated at this point, they decide how to
+		// handle the new password.
+		var newMachine string
+		var newUser string
+		var newType PasswordType
+		row := transaction.QueryRow("select machine, user, type from passwords where id=?", id)
+		err = row.Scan(&newMachine, &newUser, &newType)
+		if err != nil && err != sql.ErrNoRows {
+			return ret, fmt.Errorf("row.Scan() failed: %s", err)
+		}
+		if newType == PasswordTypeRecoveryCodes {
+			if password == "-" {
+				return ret, fmt.Errorf("recovery codes can't be generated")
+			}
+			password, err = encodeRecoveryCodes(password)
+			if err != nil {
+				return ret, fmt.Errorf("encodeRecoveryCodes() failed: %s", err)
+			}
+		} else if password == "-" {
+			password, ret.entropy, err = generateFlagsPassword(cmd, update.policy, newMachine, update.secure)
+			if err != nil {
+				return ret, fmt.Errorf("generateFlagsPassword() failed: %s", err)
+			}
+			ret.generatedPassword = password
+		} else if newType == PasswordTypePlain {
+			err = checkPasswordStrength(cmd, update.strength, password, newMachine, newUser)
+			if err != nil {
+				return ret, fmt.Errorf("checkPasswordStrength() failed: %s", err)
+			}
+		}
+		ret.affected, err = updateColumn(transaction, id, "password", password, now)
+		if err != nil {
+			return ret, fmt.Errorf("updateColumn() failed: %s", err)
+		}
+	}
+	if len(update.archived) > 0 {
+		parsed, err := strconv.ParseBool(update.archived)
+		if err != nil {
+			return ret, fmt.Errorf("ParseBool() failed: %s", err)
+		}
+		ret.affected, err = updateColumn(transaction, id, "archived", parsed, now)
+		if err != nil {
+			return ret, fmt.Errorf("updateColumn() failed: %s", err)
+		}
+	}
+	if update.setNotes {
+		ret.affected, err = updateColumn(transaction, id, "notes", update.notes, now)
+		if err != nil {
+			return ret, fmt.Errorf("updateColumn() failed: %s", err)
+		}
+	}
+	if update.setRotateEvery {
+		if update.rotateEvery < 0 {
+			return ret, fmt.Errorf("rotate-every can't be negative")
+		}
+		ret.affected, err = updateColumn(transaction, id, "rotate_every", update.rotateEvery, now)
+		if err != nil {
+			return ret, fmt.Errorf("updateColumn() failed: %s", err)
+		}
+	}
+	if update.setExpires {
+		parsed, err := parseExpiryDate(update.expires)
+		if err != nil {
+			return ret, fmt.Errorf("parseExpiryDate() failed: %s", err)
+		}
+
+		ret.affected, err = updateColumn(transaction, id, "expires", parsed, now)
+		if err != nil {
+			return ret, fmt.Errorf("updateColumn() failed: %s", err)
+		}
+	} else if len(password) > 0 || update.setRotateEvery {
+		// A new password or a new rotation interval restarts the rotation, disabling the rotation
+		// clears the expiry date.
+		var interval int
+		row := transaction.QueryRow("select rotate_every from passwords where id=?", id)
+		err = row.Scan(&interval)
+		if err != nil && err != sql.ErrNoRows {
+			return ret, fmt.Errorf("row.Scan() failed: %s", err)
+		}
+		if interval > 0 || update.setRotateEvery {
+			var expires string
+			if interval > 0 {
+				expires = Now().AddDate(0, 0, interval).Format(time.RFC3339)
+			}
+			query, err := transaction.Prepare("update passwords set expires=? where id=?")
+			if err != nil {
+				return ret, fmt.Errorf("db.Prepare() failed: %s", err)
+			}
+
+			_, err = query.Exec(expires, id)
+			if err != nil {
+				return ret, fmt.Errorf("db.Exec() failed: %s", err)
+			}
+		}
+	}
+
+	return ret, nil
+}
+
+// printGeneratedPassword prints the new password of `result`, in case it was generated.
+func printGeneratedPassword(cmd *cobra.Command, result updateResult) {
+	if len(result.generatedPassword) == 0 {
+		return
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Generated password: %s\n", result.generatedPassword)
+	if result.entropy > 0 {
+		fmt.Fprintf(cmd.OutOrStdout(), "Entropy: %.1f bits\n", result.entropy)
+	}
+}
+
 func newUpdateCommand(ctx *Context) *cobra.Command {
-	var machine string
-	var service string
-	var user string
-	var passwordType PasswordType
-	var password string
+	var update passwordUpdate
 	var dryRun bool
-	var secure bool
 	var id string
-	var archived string
 	var cmd = &cobra.Command{
 		Use:   "update",
 		Short: "updates an existing password",
@@ -35,7 +243,6 @@ func newUpdateCommand(ctx *Context) *cobra.Command {
 
 			defer transaction.Rollback()
 
-			var affected int64
 			if len(id) == 0 {
 				fmt.Fprintf(cmd.OutOrStdout(), "Id: ")
 				reader := bufio.NewReader(cmd.InOrStdin())
@@ -45,137 +252,40 @@ func newUpdateCommand(ctx *Context) *cobra.Command {
 				}
 				id = strings.TrimSuffix(line, "\n")
 			}
-			now := Now().Format(time.RFC3339)
-			if len(machine) > 0 {
-				query, err := transaction.Prepare("update passwords set machine=?, modified=? where id=?")
-				if err != nil {
-					return fmt.Errorf("db.Prepare() failed: %s", err)
-				}
-
-				result, err := query.Exec(machine, now, id)
-				if err != nil {
-					return fmt.Errorf("db.Exec() failed: %s", err)
-				}
-
-				affected, err = result.RowsAffected()
-				if err != nil {
-					return fmt.Errorf("result.RowsAffected() failed: %s", err)
-				}
-			}
-			if len(service) > 0 {
-				query, err := transaction.Prepare("update passwords set service=?, modified=? where id=?")
-				if err != nil {
-					return fmt.Errorf("db.Prepare() failed: %s", err)
-				}
-
-				result, err := query.Exec(service, now, id)
-				if err != nil {
-					return fmt.Errorf("db.Exec() failed: %s", err)
-				}
-
-				affected, err = result.RowsAffected()
-				if err != nil {
-					return fmt.Errorf("result.RowsAffected() failed: %s", err)
-				}
-			}
-			if len(user) > 0 {
-				query, err := transaction.Prepare("update passwords set user=?, modified=? where id=?")
-				if err != nil {
-					return fmt.Errorf("db.Prepare() failed: %s", err)
-				}
-
-				result, err := query.Exec(user, now, id)
-				if err != nil {
-					return fmt.Errorf("db.Exec() failed: %s", err)
-				}
-
-				affected, err = result.RowsAffected()
-				if err != nil {
-					return fmt.Errorf("result.RowsAffected() failed: %s", err)
-				}
-			}
-			if len(passwordType) > 0 {
-				query, err := transaction.Prepare("update passwords set type=?, modified=? where id=?")
-				if err != nil {
-					return fmt.Errorf("db.Prepare() failed: %s", err)
-				}
-
-				result, err := query.Exec(passwordType, now, id)
-				if err != nil {
-					return fmt.Errorf("db.Exec() failed: %s", err)
-				}
-
-				affected, err = result.RowsAffected()
-				if err != nil {
-					return fmt.Errorf("result.RowsAffected() failed: %s", err)
-				}
-			}
-			generatedPassword := false
-			if len(password) > 0 {
-				if password == "-" {
-					password, err = generatePassword(secure)
-					if err != nil {
-						return fmt.Errorf("generatePassword() failed: %s", err)
-					}
-					generatedPassword = true
-				}
-				query, err := transaction.Prepare("update passwords set password=?, modified=? where id=?")
-				if err != nil {
-					return fmt.Errorf("db.Prepare() failed: %s", err)
-				}
-
-				result, err := query.Exec(password, now, id)
-				if err != nil {
-					return fmt.Errorf("db.Exec() failed: %s", err)
-				}
-
-				affected, err = result.RowsAffected()
-				if err != nil {
-					return fmt.Errorf("result.RowsAffected() failed: %s", err)
-				}
+			update.setExpires = cmd.Flags().Changed("expires")
+			update.setRotateEvery = cmd.Flags().Changed("rotate-every")
+			update.setNotes = cmd.Flags().Changed("notes")
+			result, err := updatePassword(cmd, transaction, id, update)
+			if err != nil {
+				return fmt.Errorf("updatePassword() failed: %s", err)
 			}
-			if len(archived) > 0 {
-				query, err := transaction.Prepare("update passwords set archived=?, modified=? where id=?")
-				if err != nil {
-					return fmt.Errorf("db.Prepare() failed: %s", err)
-				}
 
-				parsed, err := strconv.ParseBool(archived)
-				if err != nil {
-					return fmt.Errorf("ParseBool() failed: %s", err)
-				}
-				result, err := query.Exec(parsed, now, id)
-				if err != nil {
-					return fmt.Errorf("db.Exec() failed: %s", err)
-				}
-
-				affected, err = result.RowsAffected()
-				if err != nil {
-					return fmt.Errorf("result.RowsAffected() failed: %s", err)
-				}
-			}
 			if dryRun {
-				fmt.Fprintf(cmd.OutOrStdout(), "Would update %v password\n", affected)
+				fmt.Fprintf(cmd.OutOrStdout(), "Would update %v password\n", result.affected)
 				ctx.NoWriteBack = true
 			} else {
 				transaction.Commit()
-				fmt.Fprintf(cmd.OutOrStdout(), "Updated %v password\n", affected)
-			}
-			if generatedPassword {
-				fmt.Fprintf(cmd.OutOrStdout(), "Generated password: %s\n", password)
+				fmt.Fprintf(cmd.OutOrStdout(), "Updated %v password\n", result.affected)
 			}
+			printGeneratedPassword(cmd, result)
 			return nil
 		},
 	}
 	cmd.Flags().BoolVarP(&dryRun, "dry-run", "n", false, `do everything except actually perform the database action (default: false)`)
-	cmd.Flags().BoolVarP(&secure, "secure", "y", false, `increase number of symbols from 0 to 3 (default: false)`)
+	cmd.Flags().BoolVarP(&update.secure, "secure", "y", false, `increase number of symbols from 0 to 3 (default: false)`)
 	cmd.Flags().StringVarP(&id, "id", "i", "", `unique identifier (default: ask)`)
-	cmd.Flags().StringVarP(&machine, "machine", "m", "", "new machine (default: keep unchanged)")
-	cmd.Flags().StringVarP(&service, "service", "s", "", "new service (default: keep unchanged)")
-	cmd.Flags().StringVarP(&user, "user", "u", "", "new user (default: keep unchanged)")
-	cmd.Flags().VarP(&passwordType, "type", "t", `new password type ("plain" or "totp"; default: keep unchanged)`)
-	cmd.Flags().StringVarP(&password, "password", "p", "", `new password ("-" generates a new one; default: keep unchanged)`)
-	cmd.Flags().StringVarP(&archived, "archived", "a", "", `new archived value ("true" or "false"; default: keep unchanged)`)
+	cmd.Flags().StringVarP(&update.machine, "machine", "m", "", "new machine (default: keep unchanged)")
+	cmd.Flags().StringVarP(&update.service, "service", "s", "", "new service (default: keep unchanged)")
+	cmd.Flags().StringVarP(&update.user, "user", "u", "", "new user (default: keep unchanged)")
+	cmd.Flags().VarP(&update.passwordType, "type", "t", `new password type ("plain", "totp" or "recovery-codes"; default: keep unchanged)`)
+	cmd.Flags().StringVarP(&update.password, "password", "p", "", `new password ("-" generates a new one; default: keep unchanged)`)
+	cmd.Flags().StringVarP(&update.archived, "archived", "a", "", `new archived value ("true" or "false"; default: keep unchanged)`)
+	addPolicyFlags(cmd, &update.policy)
+	addStrengthFlags(cmd, &update.strength)
+	cmd.Flags().StringVarP(&update.expires, "expires", "", "", `new expiry date, as YYYY-MM-DD ("" clears it; default: keep unchanged)`)
+	cmd.Flags().IntVarP(&update.rotateEvery, "rotate-every", "", 0, `new rotation interval in days, the password expires this many days after each change (0 disables it and clears the expiry date; default: keep unchanged)`)
+	cmd.Flags().StringVarP(&update.notes, "notes", "", "", `new notes ("" clears them; default: keep unchanged)`)
+	cmd.MarkFlagsMutuallyExclusive("password", "passphrase")
 
 	return cmd
 }
@@ -26,7 +26,7 @@ func TestUpdate(t *testing.T) {
 	expectedPassword := "newpassword"
 	var expectedType PasswordType = "newtype"
 	secure := false
-	_, err := createPassword(&ctx, expectedMachine, expectedService, expectedUser, "oldpassword", expectedType, secure)
+	_, err := createPassword(&ctx, expectedMachine, expectedService, expectedUser, "oldpassword", expectedType, secure, "", 0, "")
 	if err != nil {
 		t.Fatalf("createPassword() = %q, want nil", err)
 	}
@@ -71,7 +71,7 @@ func TestPwgenUpdate(t *testing.T) {
 	expectedPassword := "output-from-pwgen"
 	var expectedType PasswordType = "plain"
 	secure := false
-	_, err := createPassword(&ctx, expectedMachine, expectedService, expectedUser, "oldpassword", expectedType, secure)
+	_, err := createPassword(&ctx, expectedMachine, expectedService, expectedUser, "oldpassword", expectedType, secure, "", 0, "")
 	if err != nil {
 		t.Fatalf("createPassword() = %q, want nil", err)
 	}
@@ -115,7 +115,7 @@ func TestInteractiveUpdate(t *testing.T) {
 	expectedPassword := "newpassword"
 	var expectedType PasswordType = "plain"
 	secure := false
-	_, err := createPassword(&ctx, expectedMachine, expectedService, expectedUser, "oldpassword", expectedType, secure)
+	_, err := createPassword(&ctx, expectedMachine, expectedService, expectedUser, "oldpassword", expectedType, secure, "", 0, "")
 	if err != nil {
 		t.Fatalf("createPassword() = %q, want nil", err)
 	}
@@ -160,7 +160,7 @@ func TestDryRunUpdate(t *testing.T) {
 	expectedUser := "myuser"
 	var expectedType PasswordType = "plain"
 	secure := false
-	_, err := createPassword(&ctx, expectedMachine, expectedService, expectedUser, "oldpassword", expectedType, secure)
+	_, err := createPassword(&ctx, expectedMachine, expectedService, expectedUser, "oldpassword", expectedType, secure, "", 0, "")
 	if err != nil {
 		t.Fatalf("createPassword() = %q, want nil", err)
 	}
@@ -204,7 +204,7 @@ func TestUpdateMachine(t *testing.T) {
 	expectedUser := "myuser"
 	var expectedType PasswordType = "plain"
 	secure := false
-	_, err := createPassword(&ctx, expectedMachine, expectedService, expectedUser, "oldpassword", expectedType, secure)
+	_, err := createPassword(&ctx, expectedMachine, expectedService, expectedUser, "oldpassword", expectedType, secure, "", 0, "")
 	if err != nil {
 		t.Fatalf("createPassword() = %q, want nil", err)
 	}
@@ -248,7 +248,7 @@ func TestUpdateService(t *testing.T) {
 	expectedUser := "myuser"
 	var expectedType PasswordType = "plain"
 	secure := false
-	_, err := createPassword(&ctx, expectedMachine, expectedService, expectedUser, "oldpassword", expectedType, secure)
+	_, err := createPassword(&ctx, expectedMachine, expectedService, expectedUser, "oldpassword", expectedType, secure, "", 0, "")
 	if err != nil {
 		t.Fatalf("createPassword() = %q, want nil", err)
 	}
@@ -292,7 +292,7 @@ func TestUpdateUser(t *testing.T) {
 	expectedUser := "myuser"
 	var expectedType PasswordType = "plain"
 	secure := false
-	_, err := createPassword(&ctx, expectedMachine, expectedService, expectedUser, "oldpassword", expectedType, secure)
+	_, err := createPassword(&ctx, expectedMachine, expectedService, expectedUser, "oldpassword", expectedType, secure, "", 0, "")
 	if err != nil {
 		t.Fatalf("createPassword() = %q, want nil", err)
 	}
@@ -337,7 +337,7 @@ func TestUpdateType(t *testing.T) {
 	expectedType := "plain"
 	expectedPassword := "mypassword"
 	secure := false
-	_, err := createPassword(&ctx, expectedMachine, expectedService, expectedUser, expectedPassword, "oldtype", secure)
+	_, err := createPassword(&ctx, expectedMachine, expectedService, expectedUser, expectedPassword, "oldtype", secure, "", 0, "")
 	if err != nil {
 		t.Fatalf("createPassword() = %q, want nil", err)
 	}
@@ -381,7 +381,7 @@ func TestUpdateArchived(t *testing.T) {
 	expectedArchived := "true"
 	expectedPassword := "mypassword"
 	secure := false
-	_, err := createPassword(&ctx, expectedMachine, expectedService, expectedUser, expectedPassword, "plain", secure)
+	_, err := createPassword(&ctx, expectedMachine, expectedService, expectedUser, expectedPassword, "plain", secure, "", 0, "")
 	if err != nil {
 		t.Fatalf("createPassword() = %q, want nil", err)
 	}
@@ -411,9 +411,45 @@ func TestUpdateArchived(t *testing.T) {
 	if actualLength != expectedLength {
 		t.Fatalf("actualLength = %q, want %q", actualLength, expectedLength)
 	}
-	actualContains := ContainsString(results, fmt.Sprintf("machine: %s, service: %s, user: %s, password type: plain, password: %s, archived: true, created: 2020-05-10 00:00, modified: 2020-05-10 00:00", expectedMachine, expectedService, expectedUser, expectedPassword))
+	actualContains := ContainsString(results, fmt.Sprintf("machine: %s, service: %s, user: %s, password type: plain, password: %s, archived: true, created: 2020-05-10 00:00, modified: 2020-05-10 00:00, strength: 0/4", expectedMachine, expectedService, expectedUser, expectedPassword))
 	expectedContains := true
 	if actualContains != expectedContains {
 		t.Fatalf("actualContains = %v, want %v", actualContains, expectedContains)
 	}
 }
+
+func TestUpdateNotes(t *testing.T) {
+	ctx := CreateContextForTesting(t)
+	_, err := createPassword(&ctx, "mymachine", "myservice", "myuser", "mypassword", "plain", false, "", 0, "oldnotes")
+	if err != nil {
+		t.Fatalf("createPassword() = %q, want nil", err)
+	}
+	// The notes are kept unless --notes is given, an empty value clears them.
+	for _, test := range []struct {
+		args          []string
+		expectedNotes string
+	}{
+		{[]string{"-u", "newuser"}, "oldnotes"},
+		{[]string{"--notes", "newnotes"}, "newnotes"},
+		{[]string{"--notes", ""}, ""},
+	} {
+		os.Args = append([]string{"", "update", "--id", "1"}, test.args...)
+		inBuf := new(bytes.Buffer)
+		outBuf := new(bytes.Buffer)
+
+		actualRet := Main(inBuf, outBuf)
+
+		expectedRet := 0
+		if actualRet != expectedRet {
+			t.Fatalf("Main() = %q, want %q, args are %q", actualRet, expectedRet, test.args)
+		}
+		var notes string
+		err = ctx.Database.QueryRow("select notes from passwords where id=1").Scan(&notes)
+		if err != nil {
+			t.Fatalf("row.Scan() failed: %s", err)
+		}
+		if notes != test.expectedNotes {
+			t.Fatalf("notes = %q, want %q, args are %q", notes, test.expectedNotes, test.args)
+		}
+	}
+}